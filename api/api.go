@@ -5,6 +5,8 @@ import (
 	"app/api/handler"
 	"app/config"
 	"app/pkg/logger"
+	"app/pkg/outbox"
+	"app/pkg/security"
 	"app/storage"
 
 	"github.com/gin-gonic/gin"
@@ -12,51 +14,133 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func NewApi(r *gin.Engine, cfg *config.Config, store storage.StorageI, logger logger.LoggerI) {
-
-	handler := handler.NewHandler(cfg, store, logger)
-
-	r.POST("/customer", handler.CreateCustomer)
-	r.GET("/customer/:id", handler.GetByIdCustomer)
-	r.GET("/customer", handler.GetListCustomer)	
-	r.PUT("/customer/:id", handler.UpdateCustomer)
-	r.PATCH("/customer/:id", handler.UpdatePatchCustomer)
-	r.DELETE("/customer/:id", handler.DeleteCustomer)
-
-	r.POST("/user", handler.CreateUser)
-	r.GET("/user/:id", handler.GetByIdUser)
-	r.GET("/user", handler.GetListUser)	
-	r.PUT("/user/:id", handler.UpdateUser)
-	r.PATCH("/user/:id", handler.UpdatePatchUser)
-	r.DELETE("/user/:id", handler.DeleteUser)
-
-	r.POST("/courier", handler.CreateCourier)
-	r.GET("/courier/:id", handler.GetByIdCourier)
-	r.GET("/courier", handler.GetListCourier)	
-	r.PUT("/courier/:id", handler.UpdateCourier)
-	r.PATCH("/courier/:id", handler.UpdatePatchCourier)
-	r.DELETE("/courier/:id", handler.DeleteCourier)
-
-	r.POST("/category", handler.CreateCategory)
-	r.GET("/category/:id", handler.GetByIdCategory)
-	r.GET("/category", handler.GetListCategory)	
-	r.PUT("/category/:id", handler.UpdateCategory)
-	r.PATCH("/category/:id", handler.UpdatePatchCategory)
-	r.DELETE("/category/:id", handler.DeleteCategory)
-
-	r.POST("/product", handler.CreateProduct)
-	r.GET("/product/:id", handler.GetByIdProduct)
-	r.GET("/product", handler.GetListProduct)	
-	r.PUT("/product/:id", handler.UpdateProduct)
-	r.PATCH("/product/:id", handler.UpdatePatchProduct)
-	r.DELETE("/product/:id", handler.DeleteProduct)
-
-	r.POST("/order", handler.CreateOrder)
-	r.GET("/order/:id", handler.GetByIdOrder)
-	r.GET("/order", handler.GetListOrder)	
-	r.PUT("/order/:id", handler.UpdateOrder)
-	r.PATCH("/order/:id", handler.UpdatePatchOrder)
-	r.DELETE("/order/:id", handler.DeleteOrder)
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description Access token from /auth/login, as "Bearer <token>"
+func NewApi(r *gin.Engine, cfg *config.Config, store storage.StorageI, logger logger.LoggerI, relay *outbox.Relay) {
+
+	handler := handler.NewHandler(cfg, store, logger, relay)
+
+	r.POST("/auth/login", handler.Login)
+	r.POST("/auth/refresh", handler.RefreshToken)
+	r.POST("/auth/customer/otp/request", handler.RequestCustomerOTP)
+	r.POST("/auth/customer/otp/verify", handler.VerifyCustomerOTP)
+
+	// Authenticates itself: WebSocket clients may pass the token as a query.
+	r.GET("/ws/courier", handler.CourierSocket)
+
+	secured := r.Group("/", handler.AuthMiddleware())
+
+	secured.POST("/customer", handler.Require(security.PermCustomerWrite), handler.CreateCustomer)
+	secured.GET("/customer/:id", handler.Require(security.PermCustomerRead), handler.GetByIdCustomer)
+	secured.GET("/customer", handler.Require(security.PermCustomerRead), handler.GetListCustomer)	
+	secured.PUT("/customer/:id", handler.Require(security.PermCustomerWrite), handler.UpdateCustomer)
+	secured.PATCH("/customer/:id", handler.Require(security.PermCustomerWrite), handler.UpdatePatchCustomer)
+	secured.DELETE("/customer/:id", handler.Require(security.PermCustomerDelete), handler.DeleteCustomer)
+	secured.POST("/customer/:id/addresses", handler.Require(security.PermCustomerWrite), handler.CreateCustomerAddress)
+	secured.GET("/customer/:id/addresses", handler.Require(security.PermCustomerRead), handler.GetListCustomerAddress)
+	secured.GET("/customer/:id/addresses/:address_id", handler.Require(security.PermCustomerRead), handler.GetByIdCustomerAddress)
+	secured.PUT("/customer/:id/addresses/:address_id", handler.Require(security.PermCustomerWrite), handler.UpdateCustomerAddress)
+	secured.DELETE("/customer/:id/addresses/:address_id", handler.Require(security.PermCustomerWrite), handler.DeleteCustomerAddress)
+
+	secured.POST("/user", handler.Require(security.PermUserWrite), handler.CreateUser)
+	secured.GET("/user/:id", handler.Require(security.PermUserRead), handler.GetByIdUser)
+	secured.GET("/user", handler.Require(security.PermUserRead), handler.GetListUser)	
+	secured.PUT("/user/:id", handler.Require(security.PermUserWrite), handler.UpdateUser)
+	secured.PATCH("/user/:id", handler.Require(security.PermUserWrite), handler.UpdatePatchUser)
+	secured.DELETE("/user/:id", handler.Require(security.PermUserDelete), handler.DeleteUser)
+
+	secured.POST("/courier", handler.Require(security.PermCourierWrite), handler.CreateCourier)
+	secured.GET("/courier/nearby", handler.Require(security.PermCourierRead), handler.GetNearbyCourier)
+	secured.GET("/courier/:id", handler.Require(security.PermCourierRead), handler.GetByIdCourier)
+	secured.GET("/courier", handler.Require(security.PermCourierRead), handler.GetListCourier)	
+	secured.PUT("/courier/:id", handler.Require(security.PermCourierWrite), handler.UpdateCourier)
+	secured.PATCH("/courier/:id", handler.Require(security.PermCourierWrite), handler.UpdatePatchCourier)
+	secured.DELETE("/courier/:id", handler.Require(security.PermCourierDelete), handler.DeleteCourier)
+	secured.POST("/courier/:id/status", handler.Require(security.PermCourierStatus), handler.UpdateStatusCourier)
+	secured.POST("/courier/:id/location", handler.Require(security.PermCourierLocation), handler.AddLocationCourier)
+	secured.GET("/courier/:id/locations", handler.Require(security.PermCourierRead), handler.GetLocationsCourier)
+
+	secured.POST("/category", handler.Require(security.PermCategoryWrite), handler.CreateCategory)
+	secured.GET("/category/:id", handler.Require(security.PermCategoryRead), handler.GetByIdCategory)
+	secured.GET("/category", handler.Require(security.PermCategoryRead), handler.GetListCategory)	
+	secured.PUT("/category/:id", handler.Require(security.PermCategoryWrite), handler.UpdateCategory)
+	secured.PATCH("/category/:id", handler.Require(security.PermCategoryWrite), handler.UpdatePatchCategory)
+	secured.DELETE("/category/:id", handler.Require(security.PermCategoryDelete), handler.DeleteCategory)
+
+	secured.POST("/product", handler.Require(security.PermProductWrite), handler.CreateProduct)
+	secured.GET("/product/:id", handler.Require(security.PermProductRead), handler.GetByIdProduct)
+	secured.GET("/product", handler.Require(security.PermProductRead), handler.GetListProduct)	
+	secured.PUT("/product/:id", handler.Require(security.PermProductWrite), handler.UpdateProduct)
+	secured.PATCH("/product/:id", handler.Require(security.PermProductWrite), handler.UpdatePatchProduct)
+	secured.DELETE("/product/:id", handler.Require(security.PermProductDelete), handler.DeleteProduct)
+	secured.GET("/product/:id/stock", handler.Require(security.PermStockRead), handler.GetStockProduct)
+	secured.POST("/product/:id/stock/adjust", handler.Require(security.PermStockWrite), handler.AdjustStockProduct)
+	secured.POST("/product/:id/stock/transfer", handler.Require(security.PermStockWrite), handler.TransferStockProduct)
+	secured.GET("/product/:id/stock/movements", handler.Require(security.PermStockRead), handler.GetStockMovementsProduct)
+
+	secured.POST("/warehouse", handler.Require(security.PermWarehouseWrite), handler.CreateWarehouse)
+	secured.GET("/warehouse/:id", handler.Require(security.PermWarehouseRead), handler.GetByIdWarehouse)
+	secured.GET("/warehouse", handler.Require(security.PermWarehouseRead), handler.GetListWarehouse)
+	secured.PUT("/warehouse/:id", handler.Require(security.PermWarehouseWrite), handler.UpdateWarehouse)
+	secured.PATCH("/warehouse/:id", handler.Require(security.PermWarehouseWrite), handler.UpdatePatchWarehouse)
+	secured.DELETE("/warehouse/:id", handler.Require(security.PermWarehouseDelete), handler.DeleteWarehouse)
+
+	secured.POST("/delivery-zone", handler.Require(security.PermDeliveryZoneWrite), handler.CreateDeliveryZone)
+	secured.GET("/delivery-zone/:id", handler.Require(security.PermDeliveryZoneRead), handler.GetByIdDeliveryZone)
+	secured.GET("/delivery-zone", handler.Require(security.PermDeliveryZoneRead), handler.GetListDeliveryZone)
+	secured.PUT("/delivery-zone/:id", handler.Require(security.PermDeliveryZoneWrite), handler.UpdateDeliveryZone)
+	secured.PATCH("/delivery-zone/:id", handler.Require(security.PermDeliveryZoneWrite), handler.UpdatePatchDeliveryZone)
+	secured.DELETE("/delivery-zone/:id", handler.Require(security.PermDeliveryZoneDelete), handler.DeleteDeliveryZone)
+
+	secured.POST("/tax-class", handler.Require(security.PermTaxClassWrite), handler.CreateTaxClass)
+	secured.GET("/tax-class/:id", handler.Require(security.PermTaxClassRead), handler.GetByIdTaxClass)
+	secured.GET("/tax-class", handler.Require(security.PermTaxClassRead), handler.GetListTaxClass)
+	secured.PUT("/tax-class/:id", handler.Require(security.PermTaxClassWrite), handler.UpdateTaxClass)
+	secured.PATCH("/tax-class/:id", handler.Require(security.PermTaxClassWrite), handler.UpdatePatchTaxClass)
+	secured.DELETE("/tax-class/:id", handler.Require(security.PermTaxClassDelete), handler.DeleteTaxClass)
+
+	secured.POST("/exchange-rate", handler.Require(security.PermExchangeRateWrite), handler.CreateExchangeRate)
+	secured.GET("/exchange-rate/:id", handler.Require(security.PermExchangeRateRead), handler.GetByIdExchangeRate)
+	secured.GET("/exchange-rate", handler.Require(security.PermExchangeRateRead), handler.GetListExchangeRate)
+	secured.PUT("/exchange-rate/:id", handler.Require(security.PermExchangeRateWrite), handler.UpdateExchangeRate)
+	secured.DELETE("/exchange-rate/:id", handler.Require(security.PermExchangeRateDelete), handler.DeleteExchangeRate)
+
+	secured.POST("/promotion", handler.Require(security.PermPromotionWrite), handler.CreatePromotion)
+	secured.GET("/promotion/:id", handler.Require(security.PermPromotionRead), handler.GetByIdPromotion)
+	secured.GET("/promotion", handler.Require(security.PermPromotionRead), handler.GetListPromotion)
+	secured.PUT("/promotion/:id", handler.Require(security.PermPromotionWrite), handler.UpdatePromotion)
+	secured.PATCH("/promotion/:id", handler.Require(security.PermPromotionWrite), handler.UpdatePatchPromotion)
+	secured.DELETE("/promotion/:id", handler.Require(security.PermPromotionDelete), handler.DeletePromotion)
+
+	secured.POST("/order", handler.Require(security.PermOrderWrite), handler.CreateOrder)
+	secured.GET("/order/stream", handler.Require(security.PermOrderRead), handler.StreamOrders)
+	secured.GET("/order/:id", handler.Require(security.PermOrderRead), handler.GetByIdOrder)
+	secured.GET("/order", handler.Require(security.PermOrderRead), handler.GetListOrder)	
+	secured.PUT("/order/:id", handler.Require(security.PermOrderWrite), handler.UpdateOrder)
+	secured.PATCH("/order/:id", handler.Require(security.PermOrderWrite), handler.UpdatePatchOrder)
+	secured.DELETE("/order/:id", handler.Require(security.PermOrderDelete), handler.DeleteOrder)
+	secured.POST("/order/:id/transition", handler.Require(security.PermOrderTransition), handler.TransitionOrder)
+	secured.GET("/order/:id/history", handler.Require(security.PermOrderRead), handler.GetOrderHistory)
+	secured.POST("/order/:id/invoice", handler.Require(security.PermOrderWrite), handler.IssueOrderInvoice)
+	secured.GET("/order/:id/receipt", handler.Require(security.PermOrderRead), handler.GetOrderReceipt)
+	secured.POST("/order/:id/payments", handler.Require(security.PermPaymentWrite), handler.InitiatePayment)
+	secured.GET("/order/:id/payments", handler.Require(security.PermPaymentRead), handler.GetListPayment)
+	secured.POST("/order/:id/refunds", handler.Require(security.PermOrderRefund), handler.CreateRefund)
+	secured.GET("/order/:id/refunds", handler.Require(security.PermPaymentRead), handler.GetListRefund)
+
+	secured.GET("/payment/:id", handler.Require(security.PermPaymentRead), handler.GetByIdPayment)
+	secured.POST("/payment/:id/confirm", handler.Require(security.PermPaymentWrite), handler.ConfirmPayment)
+	secured.POST("/payment/:id/fail", handler.Require(security.PermPaymentWrite), handler.FailPayment)
+
+	secured.POST("/webhook", handler.Require(security.PermWebhookWrite), handler.CreateWebhook)
+	secured.GET("/webhook/dead-letters", handler.Require(security.PermWebhookRead), handler.GetWebhookDeadLetters)
+	secured.POST("/webhook/dead-letters/:id/retry", handler.Require(security.PermWebhookWrite), handler.RetryWebhookDeadLetter)
+	secured.GET("/webhook/:id", handler.Require(security.PermWebhookRead), handler.GetByIdWebhook)
+	secured.GET("/webhook", handler.Require(security.PermWebhookRead), handler.GetListWebhook)
+	secured.PUT("/webhook/:id", handler.Require(security.PermWebhookWrite), handler.UpdateWebhook)
+	secured.DELETE("/webhook/:id", handler.Require(security.PermWebhookDelete), handler.DeleteWebhook)
 
 
 	url := ginSwagger.URL("swagger/doc.json") // The url pointing to API definition
@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/security"
+	"app/storage"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+// Login godoc
+// @ID login
+// @Router /auth/login [POST]
+// @Summary Login
+// @Description Exchange a User login and password for access and refresh tokens
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param login body models.LoginRequest true "LoginRequest"
+// @Success 200 {object} Response{data=models.TokenResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) Login(c *gin.Context) {
+
+	var login models.LoginRequest
+
+	err := c.ShouldBindJSON(&login)
+	if err != nil {
+		h.handlerResponse(c, "login", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(login.Login) <= 0 || len(login.Password) <= 0 {
+		h.handlerResponse(c, "login", http.StatusBadRequest, "login and password are required")
+		return
+	}
+
+	user, err := h.storages.User().GetByLogin(context.Background(), &models.UserLoginKey{Login: login.Login})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.handlerResponse(c, "storage.user.getByLogin", http.StatusUnauthorized, "invalid login or password")
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.user.getByLogin", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !security.ComparePassword(user.PasswordHash, login.Password) {
+		h.handlerResponse(c, "login", http.StatusUnauthorized, "invalid login or password")
+		return
+	}
+
+	resp, err := h.issueTokens(security.Claims{UserId: user.Id, Role: user.Role, CourierId: user.CourierId})
+	if err != nil {
+		h.handlerResponse(c, "login", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "login", http.StatusOK, resp)
+}
+
+// Refresh Token godoc
+// @ID refresh_token
+// @Router /auth/refresh [POST]
+// @Summary Refresh Token
+// @Description Exchange a refresh token for a new pair of tokens
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshTokenRequest true "RefreshTokenRequest"
+// @Success 200 {object} Response{data=models.TokenResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RefreshToken(c *gin.Context) {
+
+	var refresh models.RefreshTokenRequest
+
+	err := c.ShouldBindJSON(&refresh)
+	if err != nil {
+		h.handlerResponse(c, "refresh token", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	claims, err := security.ParseToken(h.cfg.JWTSecret, refresh.RefreshToken, security.TokenTypeRefresh)
+	if err != nil {
+		h.handlerResponse(c, "refresh token", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// reload the user so that deleted users cannot refresh and role changes take effect
+	user, err := h.storages.User().GetByID(context.Background(), &models.UserPrimaryKey{Id: claims.UserId})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.handlerResponse(c, "storage.user.getByID", http.StatusUnauthorized, security.ErrInvalidToken.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.user.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.issueTokens(security.Claims{UserId: user.Id, Role: user.Role, CourierId: user.CourierId})
+	if err != nil {
+		h.handlerResponse(c, "refresh token", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "refresh token", http.StatusOK, resp)
+}
+
+func (h *Handler) issueTokens(claims security.Claims) (*models.TokenResponse, error) {
+
+	claims.Type = security.TokenTypeAccess
+
+	accessToken, err := security.GenerateToken(h.cfg.JWTSecret, claims, h.cfg.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	claims.Type = security.TokenTypeRefresh
+
+	refreshToken, err := security.GenerateToken(h.cfg.JWTSecret, claims, h.cfg.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.cfg.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Request Customer OTP godoc
+// @ID request_customer_otp
+// @Router /auth/customer/otp/request [POST]
+// @Summary Request Customer OTP
+// @Description Send a one-time login code to the Customer phone
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param otp body models.RequestCustomerOTP true "RequestCustomerOTP"
+// @Success 200 {object} Response{data=models.RequestCustomerOTPResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 429 {object} Response{data=string} "Too Many Requests"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RequestCustomerOTP(c *gin.Context) {
+
+	var request models.RequestCustomerOTP
+
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		h.handlerResponse(c, "request customer otp", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !helper.IsValidPhone(request.Phone) {
+		h.handlerResponse(c, "request customer otp", http.StatusBadRequest, "invalid phone")
+		return
+	}
+
+	code, err := helper.GenerateOTP(h.cfg.OTPLength)
+	if err != nil {
+		h.handlerResponse(c, "request customer otp", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	codeHash, err := security.HashPassword(code)
+	if err != nil {
+		h.handlerResponse(c, "request customer otp", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = h.storages.Customer().CreateOTP(context.Background(), &models.CreateCustomerOTP{
+		Phone:       request.Phone,
+		CodeHash:    codeHash,
+		ExpiresIn:   int64(h.cfg.OTPTTL.Seconds()),
+		ResendAfter: int64(h.cfg.OTPResendInterval.Seconds()),
+	})
+	if errors.Is(err, storage.ErrOTPTooSoon) || errors.Is(err, storage.ErrOTPAttemptsExceeded) {
+		h.handlerResponse(c, "storage.customer.createOTP", http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.customer.createOTP", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = h.sms.Send(context.Background(), request.Phone, fmt.Sprintf("Your login code is %s", code))
+	if err != nil {
+		h.handlerResponse(c, "sms.send", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "request customer otp", http.StatusOK, &models.RequestCustomerOTPResponse{
+		ExpiresIn: int64(h.cfg.OTPTTL.Seconds()),
+	})
+}
+
+// Verify Customer OTP godoc
+// @ID verify_customer_otp
+// @Router /auth/customer/otp/verify [POST]
+// @Summary Verify Customer OTP
+// @Description Exchange a one-time code for a Customer token, the Customer is created on first login
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param otp body models.VerifyCustomerOTP true "VerifyCustomerOTP"
+// @Success 200 {object} Response{data=models.CustomerTokenResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 401 {object} Response{data=string} "Unauthorized"
+// @Response 429 {object} Response{data=string} "Too Many Requests"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) VerifyCustomerOTP(c *gin.Context) {
+
+	var verify models.VerifyCustomerOTP
+
+	err := c.ShouldBindJSON(&verify)
+	if err != nil {
+		h.handlerResponse(c, "verify customer otp", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !helper.IsValidPhone(verify.Phone) || len(verify.Code) <= 0 {
+		h.handlerResponse(c, "verify customer otp", http.StatusBadRequest, "phone and code are required")
+		return
+	}
+
+	id, err := h.storages.Customer().CheckOTP(context.Background(), &models.CheckCustomerOTP{
+		Phone:       verify.Phone,
+		Code:        verify.Code,
+		MaxAttempts: h.cfg.OTPMaxAttempts,
+		LockoutFor:  int64(h.cfg.OTPLockout.Seconds()),
+	})
+	if errors.Is(err, storage.ErrOTPInvalid) {
+		h.handlerResponse(c, "storage.customer.checkOTP", http.StatusUnauthorized, err.Error())
+		return
+	} else if errors.Is(err, storage.ErrOTPAttemptsExceeded) {
+		h.handlerResponse(c, "storage.customer.checkOTP", http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.customer.checkOTP", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	customer, err := h.storages.Customer().GetByID(context.Background(), &models.CustomerPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	token, err := security.GenerateToken(h.cfg.JWTSecret, security.Claims{
+		CustomerId: id,
+		Type:       security.TokenTypeCustomer,
+	}, h.cfg.CustomerTokenTTL)
+	if err != nil {
+		h.handlerResponse(c, "verify customer otp", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "verify customer otp", http.StatusOK, &models.CustomerTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.cfg.CustomerTokenTTL.Seconds()),
+		Customer:    customer,
+	})
+}
@@ -15,6 +15,7 @@ import (
 // @Summary Create Category
 // @Description Create Category
 // @Tags Category
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param Category body models.CreateCategory true "CreateCategoryRequest"
@@ -52,6 +53,7 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 // @Summary Get By ID Category
 // @Description Get By ID Category
 // @Tags Category
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -82,6 +84,7 @@ func (h *Handler) GetByIdCategory(c *gin.Context) {
 // @Summary Get List Category
 // @Description Get List Category
 // @Tags Category
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param offset query string false "offset"
@@ -123,6 +126,7 @@ func (h *Handler) GetListCategory(c *gin.Context) {
 // @Summary Update Category
 // @Description Update Category
 // @Tags Category
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -175,6 +179,7 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 // @Summary Update Patch Category
 // @Description Update Patch Category
 // @Tags Category
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -227,6 +232,7 @@ func (h *Handler) UpdatePatchCategory(c *gin.Context) {
 // @Summary Delete Category
 // @Description Delete Category
 // @Tags Category
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -5,6 +5,7 @@ import (
 	"app/pkg/helper"
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +16,7 @@ import (
 // @Summary Create Courier
 // @Description Create Courier
 // @Tags Courier
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param Courier body models.CreateCourier true "CreateCourierRequest"
@@ -52,6 +54,7 @@ func (h *Handler) CreateCourier(c *gin.Context) {
 // @Summary Get By ID Courier
 // @Description Get By ID Courier
 // @Tags Courier
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -82,6 +85,7 @@ func (h *Handler) GetByIdCourier(c *gin.Context) {
 // @Summary Get List Courier
 // @Description Get List Courier
 // @Tags Courier
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param offset query string false "offset"
@@ -123,6 +127,7 @@ func (h *Handler) GetListCourier(c *gin.Context) {
 // @Summary Update Courier
 // @Description Update Courier
 // @Tags Courier
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -175,6 +180,7 @@ func (h *Handler) UpdateCourier(c *gin.Context) {
 // @Summary Update Patch Courier
 // @Description Update Patch Courier
 // @Tags Courier
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -227,6 +233,7 @@ func (h *Handler) UpdatePatchCourier(c *gin.Context) {
 // @Summary Delete Courier
 // @Description Delete Courier
 // @Tags Courier
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -251,3 +258,235 @@ func (h *Handler) DeleteCourier(c *gin.Context) {
 
 	h.handlerResponse(c, "update courier", http.StatusAccepted, nil)
 }
+
+// Update Status Courier godoc
+// @ID update_status_courier
+// @Router /courier/{id}/status [POST]
+// @Summary Update Status Courier
+// @Description Set Courier availability: online, offline or busy. Picking an order up makes an online courier busy until nothing is left to deliver; going online hands out ready orders still waiting for a courier
+// @Tags Courier
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param courier body models.UpdateCourierStatus true "UpdateCourierStatusRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateStatusCourier(c *gin.Context) {
+
+	var updateStatus models.UpdateCourierStatus
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update status Courier", http.StatusBadRequest, "invalid Courier id")
+		return
+	}
+
+	if courierId, ok := courierScope(c); ok && courierId != id {
+		h.handlerResponse(c, "update status Courier", http.StatusForbidden, "couriers may only update themselves")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateStatus)
+	if err != nil {
+		h.handlerResponse(c, "update status Courier", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch updateStatus.Status {
+	case models.CourierStatusOnline, models.CourierStatusOffline, models.CourierStatusBusy:
+	default:
+		h.handlerResponse(c, "update status Courier", http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	updateStatus.Id = id
+
+	rowsAffected, err := h.storages.Courier().UpdateStatus(context.Background(), &updateStatus)
+	if err != nil {
+		h.handlerResponse(c, "storage.Courier.updateStatus", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.Courier.updateStatus", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	if updateStatus.Status == models.CourierStatusOnline {
+		h.redispatch()
+	}
+
+	resp, err := h.storages.Courier().GetByID(context.Background(), &models.CourierPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Courier.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update status Courier", http.StatusOK, resp)
+}
+
+// Add Location Courier godoc
+// @ID add_location_courier
+// @Router /courier/{id}/location [POST]
+// @Summary Add Location Courier
+// @Description Store a Courier GPS ping
+// @Tags Courier
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param location body models.CreateCourierLocation true "CreateCourierLocationRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AddLocationCourier(c *gin.Context) {
+
+	var createLocation models.CreateCourierLocation
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "add location Courier", http.StatusBadRequest, "invalid Courier id")
+		return
+	}
+
+	if courierId, ok := courierScope(c); ok && courierId != id {
+		h.handlerResponse(c, "add location Courier", http.StatusForbidden, "couriers may only update themselves")
+		return
+	}
+
+	err := c.ShouldBindJSON(&createLocation)
+	if err != nil {
+		h.handlerResponse(c, "add location Courier", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !helper.IsValidCoordinate(createLocation.Lat, createLocation.Lng) {
+		h.handlerResponse(c, "add location Courier", http.StatusBadRequest, "invalid coordinates")
+		return
+	}
+
+	createLocation.CourierId = id
+
+	rowsAffected, err := h.storages.Courier().AddLocation(context.Background(), &createLocation)
+	if err != nil {
+		h.handlerResponse(c, "storage.Courier.addLocation", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.Courier.addLocation", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	h.handlerResponse(c, "add location Courier", http.StatusCreated, nil)
+}
+
+// Get Locations Courier godoc
+// @ID get_locations_courier
+// @Router /courier/{id}/locations [GET]
+// @Summary Get Locations Courier
+// @Description Get recent Courier GPS pings, newest first
+// @Tags Courier
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param limit query string false "limit"
+// @Success 200 {object} Response{data=models.GetListCourierLocationResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetLocationsCourier(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get locations Courier", http.StatusBadRequest, "invalid Courier id")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get locations Courier", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.Courier().GetLocations(context.Background(), &models.GetListCourierLocationRequest{
+		CourierId: id,
+		Limit:     limit,
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.Courier.getLocations", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get locations Courier", http.StatusOK, resp)
+}
+
+// Get Nearby Courier godoc
+// @ID get_nearby_courier
+// @Router /courier/nearby [GET]
+// @Summary Get Nearby Courier
+// @Description Get online Couriers ordered by distance from a point
+// @Tags Courier
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param lat query string true "lat"
+// @Param lng query string true "lng"
+// @Param radius query string false "radius in meters"
+// @Param limit query string false "limit"
+// @Success 200 {object} Response{data=models.GetNearbyCourierResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetNearbyCourier(c *gin.Context) {
+
+	var radius float64 = 5000
+
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil {
+		h.handlerResponse(c, "get nearby Courier", http.StatusBadRequest, "invalid lat")
+		return
+	}
+
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil {
+		h.handlerResponse(c, "get nearby Courier", http.StatusBadRequest, "invalid lng")
+		return
+	}
+
+	if !helper.IsValidCoordinate(lat, lng) {
+		h.handlerResponse(c, "get nearby Courier", http.StatusBadRequest, "invalid coordinates")
+		return
+	}
+
+	if len(c.Query("radius")) > 0 {
+		radius, err = strconv.ParseFloat(c.Query("radius"), 64)
+		if err != nil || radius <= 0 {
+			h.handlerResponse(c, "get nearby Courier", http.StatusBadRequest, "invalid radius")
+			return
+		}
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get nearby Courier", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.Courier().GetNearby(context.Background(), &models.GetNearbyCourierRequest{
+		Lat:    lat,
+		Lng:    lng,
+		Radius: radius,
+		Limit:  limit,
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.Courier.getNearby", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get nearby Courier", http.StatusOK, resp)
+}
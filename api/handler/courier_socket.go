@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/courierhub"
+	"app/pkg/helper"
+	"app/pkg/logger"
+	"app/pkg/notification"
+	"app/pkg/security"
+	"app/storage"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var courierUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Courier apps are not served from our origin and authenticate with a
+	// token rather than cookies, so the origin is not checked.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Courier Socket godoc
+// @ID courier_socket
+// @Router /ws/courier [GET]
+// @Summary Courier WebSocket
+// @Description Two-way channel for couriers. Authenticate with a courier access token in the Authorization header or,
+// @Description where headers cannot be set, the access_token query. The server sends
+// @Description {"id","type":"assignment","data":order} for new assignments, kept until the courier replies
+// @Description {"type":"ack","id"}; assignments made while offline arrive on the next connection. The courier sends
+// @Description {"type":"picked_up"|"delivered","order_id","note","ref"} and {"type":"location","lat","lng","ref"} and
+// @Description gets {"type":"result","ref","ok","error"} back.
+// @Tags Courier
+// @Param access_token query string false "access_token"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+func (h *Handler) CourierSocket(c *gin.Context) {
+
+	token := bearerToken(c)
+	if len(token) <= 0 {
+		token = c.Query("access_token")
+	}
+
+	if len(token) <= 0 {
+		h.handlerResponse(c, "courier socket", http.StatusUnauthorized, "missing access token")
+		return
+	}
+
+	claims, err := security.ParseToken(h.cfg.JWTSecret, token, security.TokenTypeAccess)
+	if err != nil {
+		h.handlerResponse(c, "courier socket", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if claims.Role != security.RoleCourier || len(claims.CourierId) <= 0 {
+		h.handlerResponse(c, "courier socket", http.StatusForbidden, "user is not linked to a courier")
+		return
+	}
+
+	conn, err := courierUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.Warn("upgrade courier socket", logger.String("courier_id", claims.CourierId), logger.Error(err))
+		return
+	}
+
+	h.logger.Info("courier connected", logger.String("courier_id", claims.CourierId))
+
+	h.hub.Serve(context.Background(), claims.CourierId, conn, h.handleCourierMessage)
+
+	h.logger.Info("courier disconnected", logger.String("courier_id", claims.CourierId))
+}
+
+// handleCourierMessage applies a courier request; it enforces the same rules as
+// the REST endpoints for order transitions and location pings.
+func (h *Handler) handleCourierMessage(ctx context.Context, courierId string, message *courierhub.Incoming) error {
+
+	switch message.Type {
+	case courierhub.MessagePickedUp, courierhub.MessageDelivered:
+		return h.transitionCourierOrder(ctx, courierId, message)
+	case courierhub.MessageLocation:
+		if !helper.IsValidCoordinate(message.Lat, message.Lng) {
+			return errors.New("invalid coordinates")
+		}
+
+		_, err := h.storages.Courier().AddLocation(ctx, &models.CreateCourierLocation{
+			CourierId: courierId,
+			Lat:       message.Lat,
+			Lng:       message.Lng,
+		})
+		return err
+	default:
+		return fmt.Errorf("unknown message type: %q", message.Type)
+	}
+}
+
+func (h *Handler) transitionCourierOrder(ctx context.Context, courierId string, message *courierhub.Incoming) error {
+
+	if !helper.IsValidUUID(message.OrderId) {
+		return errors.New("invalid order id")
+	}
+
+	order, err := h.storages.Order().GetByID(ctx, &models.OrderPrimaryKey{Id: message.OrderId})
+	if err != nil {
+		return err
+	}
+
+	if order.Courier.Id != courierId {
+		return errors.New("order is not assigned to you")
+	}
+
+	status := models.OrderStatusPickedUp
+	if message.Type == courierhub.MessageDelivered {
+		status = models.OrderStatusDelivered
+	}
+
+	err = h.storages.Order().Transition(ctx, &models.TransitionOrder{
+		Id:        message.OrderId,
+		Status:    status,
+		ActorType: models.ActorTypeCourier,
+		ActorId:   courierId,
+		Note:      message.Note,
+	})
+	if errors.Is(err, storage.ErrInvalidOrderTransition) {
+		return err
+	} else if err != nil {
+		h.logger.Error("storage.order.transition", logger.String("order_id", message.OrderId), logger.Error(err))
+		return errors.New("could not update the order")
+	}
+
+	if status == models.OrderStatusDelivered {
+		h.notify(notification.EventOrderDelivered, message.OrderId)
+		h.redispatch()
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/notification"
 	"context"
 	"net/http"
 
@@ -15,6 +16,7 @@ import (
 // @Summary Create Customer
 // @Description Create Customer
 // @Tags Customer
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param customer body models.CreateCustomer true "CreateCustomerRequest"
@@ -31,6 +33,15 @@ func (h *Handler) CreateCustomer(c *gin.Context) {
 		return
 	}
 
+	if len(createBook.Language) <= 0 {
+		createBook.Language = notification.DefaultLanguage
+	}
+
+	if !notification.IsSupportedLanguage(createBook.Language) {
+		h.handlerResponse(c, "create customer", http.StatusBadRequest, "unsupported language")
+		return
+	}
+
 	id, err := h.storages.Customer().Create(context.Background(), &createBook)
 	if err != nil {
 		h.handlerResponse(c, "storage.customer.create", http.StatusInternalServerError, err.Error())
@@ -52,6 +63,7 @@ func (h *Handler) CreateCustomer(c *gin.Context) {
 // @Summary Get By ID Customer
 // @Description Get By ID Customer
 // @Tags Customer
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -82,6 +94,7 @@ func (h *Handler) GetByIdCustomer(c *gin.Context) {
 // @Summary Get List customer
 // @Description Get List customer
 // @Tags Customer
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param offset query string false "offset"
@@ -123,6 +136,7 @@ func (h *Handler) GetListCustomer(c *gin.Context) {
 // @Summary Update Customer
 // @Description Update Csutomer
 // @Tags Customer
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -147,6 +161,15 @@ func (h *Handler) UpdateCustomer(c *gin.Context) {
 		return
 	}
 
+	if len(updateCustomer.Language) <= 0 {
+		updateCustomer.Language = notification.DefaultLanguage
+	}
+
+	if !notification.IsSupportedLanguage(updateCustomer.Language) {
+		h.handlerResponse(c, "update customer", http.StatusBadRequest, "unsupported language")
+		return
+	}
+
 	updateCustomer.Id = id
 
 	rowsAffected, err := h.storages.Customer().Update(context.Background(), &updateCustomer)
@@ -175,6 +198,7 @@ func (h *Handler) UpdateCustomer(c *gin.Context) {
 // @Summary Update Patch Customer
 // @Description Update Patch Customer
 // @Tags Customer
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -227,6 +251,7 @@ func (h *Handler) UpdatePatchCustomer(c *gin.Context) {
 // @Summary Delete Customer
 // @Description Delete Customer
 // @Tags Customer
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Customer Address godoc
+// @ID create_customer_address
+// @Router /customer/{id}/addresses [POST]
+// @Summary Create Customer Address
+// @Description Add an address to the Customer address book
+// @Tags Customer
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param address body models.CreateCustomerAddress true "CreateCustomerAddressRequest"
+// @Success 200 {object} Response{data=models.CustomerAddress} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateCustomerAddress(c *gin.Context) {
+
+	var createAddress models.CreateCustomerAddress
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "create customer address", http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&createAddress)
+	if err != nil {
+		h.handlerResponse(c, "create customer address", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateAddress(createAddress.Street, createAddress.Lat, createAddress.Lng)
+	if err != nil {
+		h.handlerResponse(c, "create customer address", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	createAddress.CustomerId = id
+
+	addressId, err := h.storages.Customer().CreateAddress(context.Background(), &createAddress)
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.createAddress", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Customer().GetAddress(context.Background(), &models.CustomerAddressPrimaryKey{Id: addressId, CustomerId: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.getAddress", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create customer address", http.StatusCreated, resp)
+}
+
+// Get By ID Customer Address godoc
+// @ID get_by_id_customer_address
+// @Router /customer/{id}/addresses/{address_id} [GET]
+// @Summary Get By ID Customer Address
+// @Description Get By ID Customer Address
+// @Tags Customer
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param address_id path string true "address_id"
+// @Success 200 {object} Response{data=models.CustomerAddress} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdCustomerAddress(c *gin.Context) {
+
+	id := c.Param("id")
+	addressId := c.Param("address_id")
+
+	if !helper.IsValidUUID(id) || !helper.IsValidUUID(addressId) {
+		h.handlerResponse(c, "get by id customer address", http.StatusBadRequest, "invalid customer or address id")
+		return
+	}
+
+	resp, err := h.storages.Customer().GetAddress(context.Background(), &models.CustomerAddressPrimaryKey{Id: addressId, CustomerId: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.getAddress", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id customer address", http.StatusOK, resp)
+}
+
+// Get List Customer Address godoc
+// @ID get_list_customer_address
+// @Router /customer/{id}/addresses [GET]
+// @Summary Get List Customer Address
+// @Description Get the Customer address book, default address first
+// @Tags Customer
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.GetListCustomerAddressResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListCustomerAddress(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get list customer address", http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	resp, err := h.storages.Customer().GetAddresses(context.Background(), &models.GetListCustomerAddressRequest{CustomerId: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.getAddresses", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list customer address", http.StatusOK, resp)
+}
+
+// Update Customer Address godoc
+// @ID update_customer_address
+// @Router /customer/{id}/addresses/{address_id} [PUT]
+// @Summary Update Customer Address
+// @Description Update Customer Address
+// @Tags Customer
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param address_id path string true "address_id"
+// @Param address body models.UpdateCustomerAddress true "UpdateCustomerAddressRequest"
+// @Success 200 {object} Response{data=models.CustomerAddress} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateCustomerAddress(c *gin.Context) {
+
+	var updateAddress models.UpdateCustomerAddress
+
+	id := c.Param("id")
+	addressId := c.Param("address_id")
+
+	if !helper.IsValidUUID(id) || !helper.IsValidUUID(addressId) {
+		h.handlerResponse(c, "update customer address", http.StatusBadRequest, "invalid customer or address id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateAddress)
+	if err != nil {
+		h.handlerResponse(c, "update customer address", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateAddress(updateAddress.Street, updateAddress.Lat, updateAddress.Lng)
+	if err != nil {
+		h.handlerResponse(c, "update customer address", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updateAddress.Id = addressId
+	updateAddress.CustomerId = id
+
+	rowsAffected, err := h.storages.Customer().UpdateAddress(context.Background(), &updateAddress)
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.updateAddress", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.customer.updateAddress", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.Customer().GetAddress(context.Background(), &models.CustomerAddressPrimaryKey{Id: addressId, CustomerId: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.getAddress", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update customer address", http.StatusAccepted, resp)
+}
+
+// Delete Customer Address godoc
+// @ID delete_customer_address
+// @Router /customer/{id}/addresses/{address_id} [DELETE]
+// @Summary Delete Customer Address
+// @Description Delete Customer Address, past orders keep their copy of it
+// @Tags Customer
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param address_id path string true "address_id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeleteCustomerAddress(c *gin.Context) {
+
+	id := c.Param("id")
+	addressId := c.Param("address_id")
+
+	if !helper.IsValidUUID(id) || !helper.IsValidUUID(addressId) {
+		h.handlerResponse(c, "delete customer address", http.StatusBadRequest, "invalid customer or address id")
+		return
+	}
+
+	rowsAffected, err := h.storages.Customer().DeleteAddress(context.Background(), &models.CustomerAddressPrimaryKey{Id: addressId, CustomerId: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.customer.deleteAddress", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.customer.deleteAddress", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	h.handlerResponse(c, "delete customer address", http.StatusAccepted, nil)
+}
+
+func validateAddress(street string, lat, lng float64) error {
+
+	if len(strings.TrimSpace(street)) <= 0 {
+		return errors.New("street is required")
+	}
+
+	if (lat == 0 && lng == 0) || !helper.IsValidCoordinate(lat, lng) {
+		return errors.New("invalid coordinates")
+	}
+
+	return nil
+}
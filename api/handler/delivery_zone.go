@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/money"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Delivery Zone godoc
+// @ID create_delivery_zone
+// @Router /delivery-zone [POST]
+// @Summary Create Delivery Zone
+// @Description Create Delivery Zone
+// @Tags Delivery Zone
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param DeliveryZone body models.CreateDeliveryZone true "CreateDeliveryZoneRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateDeliveryZone(c *gin.Context) {
+
+	var createDeliveryZone models.CreateDeliveryZone
+
+	err := c.ShouldBindJSON(&createDeliveryZone)
+	if err != nil {
+		h.handlerResponse(c, "create delivery_zone", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateDeliveryZone(createDeliveryZone.Polygon, &createDeliveryZone.DeliveryFee, &createDeliveryZone.MinOrderAmount)
+	if err != nil {
+		h.handlerResponse(c, "create delivery_zone", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storages.DeliveryZone().Create(context.Background(), &createDeliveryZone)
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.DeliveryZone().GetByID(context.Background(), &models.DeliveryZonePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create DeliveryZone", http.StatusCreated, resp)
+}
+
+// Get By ID Delivery Zone godoc
+// @ID get_by_id_delivery_zone
+// @Router /delivery-zone/{id} [GET]
+// @Summary Get By ID Delivery Zone
+// @Description Get By ID Delivery Zone
+// @Tags Delivery Zone
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdDeliveryZone(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id DeliveryZone", http.StatusBadRequest, "invalid delivery zone id")
+		return
+	}
+
+	resp, err := h.storages.DeliveryZone().GetByID(context.Background(), &models.DeliveryZonePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id DeliveryZone", http.StatusCreated, resp)
+}
+
+// Get List Delivery Zone godoc
+// @ID get_list_DeliveryZone
+// @Router /delivery-zone [GET]
+// @Summary Get List Delivery Zone
+// @Description Get List Delivery Zone
+// @Tags Delivery Zone
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param search query string false "search"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListDeliveryZone(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get list DeliveryZone", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get list DeliveryZone", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.DeliveryZone().GetList(context.Background(), &models.GetListDeliveryZoneRequest{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.getlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list DeliveryZone response", http.StatusOK, resp)
+}
+
+// Get Update Delivery Zone godoc
+// @ID update_delivery_zone
+// @Router /delivery-zone/{id} [PUT]
+// @Summary Update Delivery Zone
+// @Description Update Delivery Zone
+// @Tags Delivery Zone
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param delivery_zone body models.UpdateDeliveryZone true "UpdateDeliveryZoneRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateDeliveryZone(c *gin.Context) {
+
+	var updateDeliveryZone models.UpdateDeliveryZone
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id DeliveryZone", http.StatusBadRequest, "invalid delivery zone id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateDeliveryZone)
+	if err != nil {
+		h.handlerResponse(c, "update DeliveryZone", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateDeliveryZone(updateDeliveryZone.Polygon, &updateDeliveryZone.DeliveryFee, &updateDeliveryZone.MinOrderAmount)
+	if err != nil {
+		h.handlerResponse(c, "update DeliveryZone", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updateDeliveryZone.Id = id
+
+	rowsAffected, err := h.storages.DeliveryZone().Update(context.Background(), &updateDeliveryZone)
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.DeliveryZone.update", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.DeliveryZone().GetByID(context.Background(), &models.DeliveryZonePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update DeliveryZone", http.StatusAccepted, resp)
+}
+
+// Update Patch Delivery Zone godoc
+// @ID updat_patch_delivery_zone
+// @Router /delivery-zone/{id} [PATCH]
+// @Summary Update Patch Delivery Zone
+// @Description Update Patch Delivery Zone
+// @Tags Delivery Zone
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param DeliveryZone body models.PatchRequest true "UpdatePatchDeliveryZoneRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdatePatchDeliveryZone(c *gin.Context) {
+
+	var object models.PatchRequest
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id DeliveryZone", http.StatusBadRequest, "invalid delivery zone id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&object)
+	if err != nil {
+		h.handlerResponse(c, "update patch DeliveryZone", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	object.ID = id
+
+	rowsAffected, err := h.storages.DeliveryZone().Patch(context.Background(), &object)
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.DeliveryZone.patch", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.DeliveryZone().GetByID(context.Background(), &models.DeliveryZonePrimaryKey{Id: object.ID})
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update patch DeliveryZone", http.StatusAccepted, resp)
+}
+
+// Delete Delivery Zone godoc
+// @ID delete_delivery_zone
+// @Router /delivery-zone/{id} [DELETE]
+// @Summary Delete Delivery Zone
+// @Description Delete Delivery Zone
+// @Tags Delivery Zone
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param DeliveryZone body models.DeliveryZonePrimaryKey true "DeleteDeliveryZoneRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeleteDeliveryZone(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id DeliveryZone", http.StatusBadRequest, "invalid delivery zone id")
+		return
+	}
+
+	err := h.storages.DeliveryZone().Delete(context.Background(), &models.DeliveryZonePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.DeliveryZone.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update DeliveryZone", http.StatusAccepted, nil)
+}
+
+func validateDeliveryZone(polygon []models.Point, deliveryFee, minOrderAmount *money.Money) error {
+
+	if len(polygon) < 3 {
+		return errors.New("polygon must have at least three points")
+	}
+
+	for _, point := range polygon {
+		if !helper.IsValidCoordinate(point.Lat, point.Lng) {
+			return errors.New("invalid polygon coordinates")
+		}
+	}
+
+	return normalizeMoney(deliveryFee, minOrderAmount)
+}
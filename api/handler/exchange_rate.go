@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/money"
+	"app/storage"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Exchange Rate godoc
+// @ID create_exchange_rate
+// @Router /exchange-rate [POST]
+// @Summary Create Exchange Rate
+// @Description Create Exchange Rate; an empty effective_from means now
+// @Tags Exchange Rate
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param ExchangeRate body models.CreateExchangeRate true "CreateExchangeRateRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Exchange Rate Exists"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateExchangeRate(c *gin.Context) {
+
+	var createExchangeRate models.CreateExchangeRate
+
+	err := c.ShouldBindJSON(&createExchangeRate)
+	if err != nil {
+		h.handlerResponse(c, "create exchange rate", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateExchangeRate(&createExchangeRate.BaseCurrency, &createExchangeRate.QuoteCurrency, createExchangeRate.Rate, createExchangeRate.EffectiveFrom)
+	if err != nil {
+		h.handlerResponse(c, "create exchange rate", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storages.ExchangeRate().Create(context.Background(), &createExchangeRate)
+	if errors.Is(err, storage.ErrExchangeRateExists) {
+		h.handlerResponse(c, "storage.ExchangeRate.create", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.ExchangeRate().GetByID(context.Background(), &models.ExchangeRatePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create exchange rate", http.StatusCreated, resp)
+}
+
+// Get By ID Exchange Rate godoc
+// @ID get_by_id_exchange_rate
+// @Router /exchange-rate/{id} [GET]
+// @Summary Get By ID Exchange Rate
+// @Description Get By ID Exchange Rate
+// @Tags Exchange Rate
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.ExchangeRate} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdExchangeRate(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id exchange rate", http.StatusBadRequest, "invalid exchange rate id")
+		return
+	}
+
+	resp, err := h.storages.ExchangeRate().GetByID(context.Background(), &models.ExchangeRatePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id exchange rate", http.StatusOK, resp)
+}
+
+// Get List Exchange Rate godoc
+// @ID get_list_exchange_rate
+// @Router /exchange-rate [GET]
+// @Summary Get List Exchange Rate
+// @Description Get List Exchange Rate, newest first per currency pair
+// @Tags Exchange Rate
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param currency query string false "currency"
+// @Success 200 {object} Response{data=models.GetListExchangeRateResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListExchangeRate(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get list exchange rate", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get list exchange rate", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.ExchangeRate().GetList(context.Background(), &models.GetListExchangeRateRequest{
+		Offset:   offset,
+		Limit:    limit,
+		Currency: strings.ToUpper(c.Query("currency")),
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.getlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list exchange rate response", http.StatusOK, resp)
+}
+
+// Update Exchange Rate godoc
+// @ID update_exchange_rate
+// @Router /exchange-rate/{id} [PUT]
+// @Summary Update Exchange Rate
+// @Description Update Exchange Rate; placed orders keep the rate they were converted at
+// @Tags Exchange Rate
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param ExchangeRate body models.UpdateExchangeRate true "UpdateExchangeRateRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Exchange Rate Exists"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateExchangeRate(c *gin.Context) {
+
+	var updateExchangeRate models.UpdateExchangeRate
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update exchange rate", http.StatusBadRequest, "invalid exchange rate id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateExchangeRate)
+	if err != nil {
+		h.handlerResponse(c, "update exchange rate", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateExchangeRate(&updateExchangeRate.BaseCurrency, &updateExchangeRate.QuoteCurrency, updateExchangeRate.Rate, updateExchangeRate.EffectiveFrom)
+	if err != nil {
+		h.handlerResponse(c, "update exchange rate", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updateExchangeRate.Id = id
+
+	rowsAffected, err := h.storages.ExchangeRate().Update(context.Background(), &updateExchangeRate)
+	if errors.Is(err, storage.ErrExchangeRateExists) {
+		h.handlerResponse(c, "storage.ExchangeRate.update", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.ExchangeRate.update", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.ExchangeRate().GetByID(context.Background(), &models.ExchangeRatePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update exchange rate", http.StatusAccepted, resp)
+}
+
+// Delete Exchange Rate godoc
+// @ID delete_exchange_rate
+// @Router /exchange-rate/{id} [DELETE]
+// @Summary Delete Exchange Rate
+// @Description Delete Exchange Rate; placed orders keep the rate they were converted at
+// @Tags Exchange Rate
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeleteExchangeRate(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "delete exchange rate", http.StatusBadRequest, "invalid exchange rate id")
+		return
+	}
+
+	err := h.storages.ExchangeRate().Delete(context.Background(), &models.ExchangeRatePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.ExchangeRate.delete", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "delete exchange rate", http.StatusAccepted, nil)
+}
+
+// validateExchangeRate upper-cases the currency codes in place and checks the
+// rate is a positive decimal between two different currencies.
+func validateExchangeRate(base, quote *string, rate, effectiveFrom string) error {
+
+	*base = strings.ToUpper(*base)
+	*quote = strings.ToUpper(*quote)
+
+	if !money.IsValidCurrency(*base) || !money.IsValidCurrency(*quote) {
+		return money.ErrInvalidCurrency
+	}
+
+	if *base == *quote {
+		return errors.New("base and quote currency must differ")
+	}
+
+	_, err := money.ParseRate(rate)
+	if err != nil {
+		return err
+	}
+
+	if len(effectiveFrom) > 0 {
+		_, err = time.Parse("2006-01-02 15:04:05", effectiveFrom)
+		if err != nil {
+			return errors.New("effective_from must look like 2006-01-02 15:04:05")
+		}
+	}
+
+	return nil
+}
@@ -1,18 +1,34 @@
 package handler
 
 import (
+	"app/api/models"
 	"app/config"
+	"app/pkg/courierhub"
+	"app/pkg/dispatch"
 	"app/pkg/logger"
+	"app/pkg/money"
+	"app/pkg/notification"
+	"app/pkg/outbox"
+	"app/pkg/payment"
+	"app/pkg/sms"
 	"app/storage"
+	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	cfg      *config.Config
-	logger   logger.LoggerI
-	storages storage.StorageI
+	cfg        *config.Config
+	logger     logger.LoggerI
+	storages   storage.StorageI
+	dispatcher *dispatch.Dispatcher
+	notifier   *notification.Notifier
+	sms        sms.Sender
+	events     *outbox.Broker
+	hub        *courierhub.Hub
+	payments   *payment.Registry
 }
 
 type Response struct {
@@ -21,11 +37,38 @@ type Response struct {
 	Data        interface{}
 }
 
-func NewHandler(cfg *config.Config, store storage.StorageI, logger logger.LoggerI) *Handler {
+func NewHandler(cfg *config.Config, store storage.StorageI, log logger.LoggerI, relay *outbox.Relay) *Handler {
+
+	strategy, err := dispatch.NewStrategy(cfg.DispatchStrategy)
+	if err != nil {
+		log.Warn("falling back to least loaded dispatch", logger.Error(err))
+		strategy = dispatch.LeastLoaded()
+	}
+
+	notifier := notification.NewNotifier(store)
+
+	events := outbox.NewBroker()
+	relay.Subscribe(models.OutboxAggregateOrder+".*", events.Handle)
+
+	hub := courierhub.NewHub(store, log)
+	relay.Subscribe(models.OutboxAggregateOrder+"."+models.OutboxActionAssigned, hub.Handle)
+
+	payments := payment.DefaultRegistry()
+	if cfg.PaymentFakeCard {
+		log.Warn("card payments go through the fake provider, no money is collected")
+		payments.Register(payment.ProviderCard, payment.NewFakeCardProvider())
+	}
+
 	return &Handler{
-		cfg:      cfg,
-		logger:   logger,
-		storages: store,
+		cfg:        cfg,
+		logger:     log,
+		storages:   store,
+		dispatcher: dispatch.NewDispatcher(store, strategy, notifier, log),
+		notifier:   notifier,
+		sms:        sms.NewLogSender(log),
+		events:     events,
+		hub:        hub,
+		payments:   payments,
 	}
 }
 
@@ -63,3 +106,38 @@ func (h *Handler) getLimitQuery(limit string) (int, error) {
 
 	return strconv.Atoi(limit)
 }
+
+// normalizeMoney validates amounts that belong together: none may be negative
+// and all must share one currency. Amounts sent without a currency take that
+// currency, or the default one when none has it.
+func normalizeMoney(amounts ...*money.Money) error {
+
+	var currency string
+
+	for _, amount := range amounts {
+
+		if amount.IsNegative() {
+			return errors.New("amounts must not be negative")
+		}
+
+		code := strings.ToUpper(amount.Currency)
+		if len(code) <= 0 {
+			continue
+		}
+
+		if !money.IsValidCurrency(code) {
+			return money.ErrInvalidCurrency
+		}
+
+		if len(currency) > 0 && code != currency {
+			return errors.New("all amounts must be in the same currency")
+		}
+		currency = code
+	}
+
+	for _, amount := range amounts {
+		*amount = money.New(amount.Amount, currency)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"app/pkg/security"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context keys set by AuthMiddleware for the authenticated user.
+const (
+	ContextUserId    = "user_id"
+	ContextRole      = "role"
+	ContextCourierId = "courier_id"
+)
+
+// AuthMiddleware rejects requests without a valid "Bearer" access token.
+func (h *Handler) AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		token := bearerToken(c)
+		if len(token) <= 0 {
+			h.handlerResponse(c, "auth middleware", http.StatusUnauthorized, "missing bearer token")
+			c.Abort()
+			return
+		}
+
+		claims, err := security.ParseToken(h.cfg.JWTSecret, token, security.TokenTypeAccess)
+		if err != nil {
+			h.handlerResponse(c, "auth middleware", http.StatusUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextUserId, claims.UserId)
+		c.Set(ContextRole, claims.Role)
+		c.Set(ContextCourierId, claims.CourierId)
+
+		c.Next()
+	}
+}
+
+// Require rejects the request with 403 unless the user's role grants permission.
+// It must run after AuthMiddleware.
+func (h *Handler) Require(permission security.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		if !security.HasPermission(c.GetString(ContextRole), permission) {
+			h.handlerResponse(c, "require permission", http.StatusForbidden, fmt.Sprintf("missing permission: %s", permission))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// courierScope returns the courier a user with the courier role acts as; such
+// users may only see and change what belongs to that courier.
+func courierScope(c *gin.Context) (string, bool) {
+
+	if c.GetString(ContextRole) != security.RoleCourier {
+		return "", false
+	}
+
+	return c.GetString(ContextCourierId), true
+}
+
+// bearerToken returns the token of a "Bearer" Authorization header, if any.
+func bearerToken(c *gin.Context) string {
+
+	header := c.GetHeader("Authorization")
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header {
+		return ""
+	}
+
+	return token
+}
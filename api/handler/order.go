@@ -3,10 +3,20 @@ package handler
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/logger"
+	"app/pkg/money"
+	"app/pkg/notification"
+	"app/pkg/receipt"
+	"app/storage"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 )
 
 // Create Order godoc
@@ -15,11 +25,13 @@ import (
 // @Summary Create Order
 // @Description Create Order
 // @Tags Order
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param Order body models.CreateOrder true "CreateOrderRequest"
 // @Success 200 {object} Response{data=string} "Success Request"
 // @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Insufficient Stock"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) CreateOrder(c *gin.Context) {
 
@@ -31,8 +43,38 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	id, err := h.storages.Order().Create(context.Background(), &createOrder)
+	err = validateOrderItems(createOrder.Items)
 	if err != nil {
+		h.handlerResponse(c, "create order", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateOrderAddress(&createOrder)
+	if err != nil {
+		h.handlerResponse(c, "create order", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(createOrder.Currency) > 0 {
+		createOrder.Currency = strings.ToUpper(createOrder.Currency)
+		if !money.IsValidCurrency(createOrder.Currency) {
+			h.handlerResponse(c, "create order", http.StatusBadRequest, money.ErrInvalidCurrency.Error())
+			return
+		}
+	}
+
+	createOrder.UserId = c.GetString(ContextUserId)
+
+	id, err := h.storages.Order().Create(context.Background(), &createOrder)
+	if errors.Is(err, storage.ErrInsufficientStock) {
+		h.handlerResponse(c, "storage.order.create", http.StatusConflict, err.Error())
+		return
+	} else if errors.Is(err, storage.ErrOutOfDeliveryZone) || errors.Is(err, storage.ErrBelowMinimumOrder) ||
+		errors.Is(err, storage.ErrAddressNotFound) || errors.Is(err, storage.ErrAddressRequired) ||
+		errors.Is(err, storage.ErrExchangeRateNotFound) || errors.Is(err, storage.ErrProductNotFound) || isPromoError(err) {
+		h.handlerResponse(c, "storage.order.create", http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
 		h.handlerResponse(c, "storage.order.create", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -52,6 +94,7 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 // @Summary Get By ID Order
 // @Description Get By ID Order
 // @Tags Order
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -73,6 +116,10 @@ func (h *Handler) GetByIdOrder(c *gin.Context) {
 		return
 	}
 
+	if !h.checkCourierOrder(c, "get by id order", resp) {
+		return
+	}
+
 	h.handlerResponse(c, "get by id order", http.StatusCreated, resp)
 }
 
@@ -82,6 +129,7 @@ func (h *Handler) GetByIdOrder(c *gin.Context) {
 // @Summary Get List Order
 // @Description Get List Order
 // @Tags Order
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param offset query string false "offset"
@@ -104,11 +152,21 @@ func (h *Handler) GetListOrder(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.storages.Order().GetList(context.Background(), &models.GetListOrderRequest{
+	request := &models.GetListOrderRequest{
 		Offset: offset,
 		Limit:  limit,
 		Search: c.Query("search"),
-	})
+	}
+
+	if courierId, ok := courierScope(c); ok {
+		if len(courierId) <= 0 {
+			h.handlerResponse(c, "get list order", http.StatusForbidden, "user is not linked to a courier")
+			return
+		}
+		request.CourierId = courierId
+	}
+
+	resp, err := h.storages.Order().GetList(context.Background(), request)
 	if err != nil {
 		h.handlerResponse(c, "storage.order.getlist", http.StatusInternalServerError, err.Error())
 		return
@@ -123,12 +181,14 @@ func (h *Handler) GetListOrder(c *gin.Context) {
 // @Summary Update Order
 // @Description Update Order
 // @Tags Order
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
 // @Param order body models.UpdateOrder true "UpdateOrderRequest"
 // @Success 200 {object} Response{data=string} "Success Request"
 // @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Conflict"
 // @Failure 500 {object} Response{data=string} "Server Error"
 func (h *Handler) UpdateOrder(c *gin.Context) {
 
@@ -147,10 +207,26 @@ func (h *Handler) UpdateOrder(c *gin.Context) {
 		return
 	}
 
+	if len(updateOrder.Items) > 0 {
+		err = validateOrderItems(updateOrder.Items)
+		if err != nil {
+			h.handlerResponse(c, "update Order", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	updateOrder.Id = id
+	updateOrder.ActorType, updateOrder.ActorId = orderActor(c)
 
 	rowsAffected, err := h.storages.Order().Update(context.Background(), &updateOrder)
-	if err != nil {
+	if errors.Is(err, storage.ErrInsufficientStock) || errors.Is(err, storage.ErrOrderLocked) {
+		h.handlerResponse(c, "storage.Order.update", http.StatusConflict, err.Error())
+		return
+	} else if errors.Is(err, storage.ErrBelowMinimumOrder) || errors.Is(err, storage.ErrExchangeRateNotFound) ||
+		errors.Is(err, storage.ErrProductNotFound) || isPromoError(err) {
+		h.handlerResponse(c, "storage.Order.update", http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
 		h.handlerResponse(c, "storage.Order.update", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -175,6 +251,7 @@ func (h *Handler) UpdateOrder(c *gin.Context) {
 // @Summary Update Patch Order
 // @Description Update Patch Order
 // @Tags Order
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -200,10 +277,18 @@ func (h *Handler) UpdatePatchOrder(c *gin.Context) {
 	}
 
 	object.ID = id
+	object.ActorType, object.ActorId = orderActor(c)
+
+	if courierId, ok := object.Fields["courier_id"]; ok {
+		h.logger.Info("manual courier override", logger.String("order_id", id), logger.Any("courier_id", courierId))
+	}
 
 	rowsAffected, err := h.storages.Order().Patch(context.Background(), &object)
-	if err != nil {
-		h.handlerResponse(c, "storage.Order.getByID", http.StatusInternalServerError, err.Error())
+	if errors.Is(err, storage.ErrOrderStatusReadOnly) || errors.Is(err, storage.ErrFieldNotPatchable) {
+		h.handlerResponse(c, "storage.Order.patch", http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.Order.patch", http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -212,6 +297,10 @@ func (h *Handler) UpdatePatchOrder(c *gin.Context) {
 		return
 	}
 
+	if courierId, ok := object.Fields["courier_id"]; ok && courierId != nil {
+		h.notify(notification.EventCourierAssigned, id)
+	}
+
 	resp, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: object.ID})
 	if err != nil {
 		h.handlerResponse(c, "storage.Order.getByID", http.StatusInternalServerError, err.Error())
@@ -227,6 +316,7 @@ func (h *Handler) UpdatePatchOrder(c *gin.Context) {
 // @Summary Delete Order
 // @Description Delete Order
 // @Tags Order
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -251,3 +341,347 @@ func (h *Handler) DeleteOrder(c *gin.Context) {
 
 	h.handlerResponse(c, "update Order", http.StatusAccepted, nil)
 }
+
+// Transition Order godoc
+// @ID transition_order
+// @Router /order/{id}/transition [POST]
+// @Summary Transition Order
+// @Description Move Order to another status
+// @Tags Order
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param order body models.TransitionOrder true "TransitionOrderRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 404 {object} Response{data=string} "Order Not Found"
+// @Response 409 {object} Response{data=string} "Conflict"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TransitionOrder(c *gin.Context) {
+
+	var transitionOrder models.TransitionOrder
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "transition order", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&transitionOrder)
+	if err != nil {
+		h.handlerResponse(c, "transition order", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(transitionOrder.Status) <= 0 {
+		h.handlerResponse(c, "transition order", http.StatusBadRequest, "status is required")
+		return
+	}
+
+	if _, ok := courierScope(c); ok {
+		order, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: id})
+		if errors.Is(err, pgx.ErrNoRows) {
+			h.handlerResponse(c, "storage.order.getByID", http.StatusNotFound, storage.ErrOrderNotFound.Error())
+			return
+		} else if err != nil {
+			h.handlerResponse(c, "storage.order.getByID", http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if !h.checkCourierOrder(c, "transition order", order) {
+			return
+		}
+	}
+
+	transitionOrder.Id = id
+	transitionOrder.ActorType, transitionOrder.ActorId = orderActor(c)
+
+	err = h.storages.Order().Transition(context.Background(), &transitionOrder)
+	if errors.Is(err, storage.ErrInvalidOrderTransition) {
+		h.handlerResponse(c, "storage.order.transition", http.StatusConflict, err.Error())
+		return
+	} else if errors.Is(err, storage.ErrOrderNotFound) {
+		h.handlerResponse(c, "storage.order.transition", http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.order.transition", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	switch transitionOrder.Status {
+	case models.OrderStatusAccepted:
+		h.notify(notification.EventOrderAccepted, id)
+	case models.OrderStatusReady:
+		_, err = h.dispatcher.Dispatch(context.Background(), id)
+		if err != nil {
+			h.logger.Warn("dispatch order", logger.String("order_id", id), logger.Error(err))
+		}
+	case models.OrderStatusDelivered:
+		h.notify(notification.EventOrderDelivered, id)
+		h.redispatch()
+	case models.OrderStatusReturned, models.OrderStatusCancelled:
+		h.redispatch()
+	}
+
+	resp, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.order.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "transition order", http.StatusOK, resp)
+}
+
+// Get Order History godoc
+// @ID get_order_history
+// @Router /order/{id}/history [GET]
+// @Summary Get Order History
+// @Description Get Order status history timeline
+// @Tags Order
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.GetOrderHistoryResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetOrderHistory(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get order history", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	if _, ok := courierScope(c); ok {
+		order, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: id})
+		if err != nil {
+			h.handlerResponse(c, "storage.order.getByID", http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if !h.checkCourierOrder(c, "get order history", order) {
+			return
+		}
+	}
+
+	resp, err := h.storages.Order().History(context.Background(), &models.OrderPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.order.history", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get order history", http.StatusOK, resp)
+}
+
+// Issue Order Invoice godoc
+// @ID issue_order_invoice
+// @Router /order/{id}/invoice [POST]
+// @Summary Issue Order Invoice
+// @Description Number the invoice of an order before it is delivered; delivered orders are invoiced automatically and an order keeps its first invoice
+// @Tags Order
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 201 {object} Response{data=models.OrderInvoice} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 404 {object} Response{data=string} "Order Not Found"
+// @Response 409 {object} Response{data=string} "Order Cancelled"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) IssueOrderInvoice(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "issue order invoice", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	req := models.IssueOrderInvoice{Id: id}
+	req.ActorType, req.ActorId = orderActor(c)
+
+	resp, err := h.storages.Order().IssueInvoice(context.Background(), &req)
+	if errors.Is(err, storage.ErrOrderNotFound) {
+		h.handlerResponse(c, "storage.order.issueInvoice", http.StatusNotFound, err.Error())
+		return
+	} else if errors.Is(err, storage.ErrOrderNotInvoiceable) {
+		h.handlerResponse(c, "storage.order.issueInvoice", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.order.issueInvoice", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "issue order invoice", http.StatusCreated, resp)
+}
+
+// Get Order Receipt godoc
+// @ID get_order_receipt
+// @Router /order/{id}/receipt [GET]
+// @Summary Get Order Receipt
+// @Description Render the invoice of an order; orders are invoiced when delivered or through POST /order/{id}/invoice
+// @Tags Order
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce html,plain,application/pdf
+// @Param id path string true "id"
+// @Param format query string false "html (default), pdf or txt"
+// @Success 200 {file} file "Receipt"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 404 {object} Response{data=string} "Order Not Found"
+// @Response 409 {object} Response{data=string} "Order Not Invoiced"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetOrderReceipt(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get order receipt", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	format := c.DefaultQuery("format", receipt.FormatHTML)
+	if !receipt.IsValidFormat(format) {
+		h.handlerResponse(c, "get order receipt", http.StatusBadRequest, receipt.ErrUnknownFormat.Error())
+		return
+	}
+
+	order, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.handlerResponse(c, "storage.order.getByID", http.StatusNotFound, storage.ErrOrderNotFound.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.order.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !h.checkCourierOrder(c, "get order receipt", order) {
+		return
+	}
+
+	invoice, err := h.storages.Order().GetInvoice(context.Background(), &models.OrderPrimaryKey{Id: id})
+	if errors.Is(err, storage.ErrInvoiceNotIssued) {
+		h.handlerResponse(c, "storage.order.getInvoice", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.order.getInvoice", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var (
+		body bytes.Buffer
+		doc  = &receipt.Receipt{Invoice: invoice, Order: order}
+	)
+
+	err = receipt.Render(&body, format, doc)
+	if err != nil {
+		h.handlerResponse(c, "render order receipt", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.logger.Info("get order receipt", logger.String("order_id", id), logger.String("invoice", doc.Number()))
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", doc.Number()+"."+format))
+	c.Data(http.StatusOK, receipt.ContentType(format), body.Bytes())
+}
+
+func validateOrderItems(items []*models.CreateOrderItem) error {
+
+	if len(items) <= 0 {
+		return errors.New("order must contain at least one item")
+	}
+
+	for _, item := range items {
+		if item == nil || !helper.IsValidUUID(item.ProductId) {
+			return errors.New("invalid product id")
+		}
+
+		if item.Quantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+	}
+
+	return nil
+}
+
+func validateOrderAddress(order *models.CreateOrder) error {
+
+	if len(order.AddressId) > 0 {
+		if !helper.IsValidUUID(order.AddressId) {
+			return errors.New("invalid address id")
+		}
+		return nil
+	}
+
+	if order.Address != nil {
+		return validateAddress(order.Address.Street, order.Address.Lat, order.Address.Lng)
+	}
+
+	if !helper.IsValidUUID(order.CustomerId) {
+		return errors.New("address id or address is required when the order has no customer")
+	}
+
+	return nil
+}
+
+// isPromoError reports whether err rejects the promo code of an order.
+func isPromoError(err error) bool {
+	return errors.Is(err, storage.ErrPromoCodeInvalid) || errors.Is(err, storage.ErrPromoUsageExceeded) ||
+		errors.Is(err, storage.ErrPromoNotApplicable)
+}
+
+// checkCourierOrder answers 403 and returns false when the caller is a courier
+// and the order is not assigned to them.
+func (h *Handler) checkCourierOrder(c *gin.Context, path string, order *models.Order) bool {
+
+	courierId, ok := courierScope(c)
+	if !ok {
+		return true
+	}
+
+	if len(courierId) <= 0 || order.Courier.Id != courierId {
+		h.handlerResponse(c, path, http.StatusForbidden, "order is not assigned to you")
+		return false
+	}
+
+	return true
+}
+
+// orderActor is who an order change is recorded as made by: the courier a
+// courier user acts as, otherwise the user of the token.
+func orderActor(c *gin.Context) (string, string) {
+
+	if courierId, ok := courierScope(c); ok {
+		return models.ActorTypeCourier, courierId
+	}
+
+	return models.ActorTypeUser, c.GetString(ContextUserId)
+}
+
+// redispatch hands waiting ready orders to couriers that became available; a
+// failure here must not fail the request that freed the courier.
+func (h *Handler) redispatch() {
+
+	dispatched, err := h.dispatcher.Redispatch(context.Background())
+	if err != nil {
+		h.logger.Warn("redispatch orders", logger.Error(err))
+	}
+
+	if dispatched > 0 {
+		h.logger.Info("redispatched orders", logger.Int("count", dispatched))
+	}
+}
+
+// notify queues the notifications of an order event; a failure here must not
+// fail the request that caused the event.
+func (h *Handler) notify(event, orderId string) {
+
+	err := h.notifier.Notify(context.Background(), event, orderId)
+	if err != nil {
+		h.logger.Warn("notify "+event, logger.String("order_id", orderId), logger.Error(err))
+	}
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/logger"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	orderStreamHeartbeat  = 15 * time.Second
+	orderStreamReplayPage = 500
+	lastEventIdHeader     = "Last-Event-ID"
+)
+
+// orderStreamFilter holds the optional filters of an order stream; the order
+// row of every event is matched against the ones that are set.
+type orderStreamFilter struct {
+	CourierId  string `json:"courier_id"`
+	CustomerId string `json:"customer_id"`
+	Status     string `json:"status"`
+}
+
+func (f *orderStreamFilter) matches(event *models.OutboxEvent) bool {
+
+	var order orderStreamFilter
+
+	err := json.Unmarshal([]byte(event.Payload), &order)
+	if err != nil {
+		return false
+	}
+
+	if len(f.CourierId) > 0 && f.CourierId != order.CourierId {
+		return false
+	}
+
+	if len(f.CustomerId) > 0 && f.CustomerId != order.CustomerId {
+		return false
+	}
+
+	if len(f.Status) > 0 && f.Status != order.Status {
+		return false
+	}
+
+	return true
+}
+
+// Stream Orders godoc
+// @ID stream_orders
+// @Router /order/stream [GET]
+// @Summary Stream Orders
+// @Description Server-Sent Events of order changes. Every event has its relay sequence number as its id, the event type
+// @Description (order.created, order.updated, order.status_changed, order.courier_assigned, order.deleted) as its name
+// @Description and the order row as JSON data. Reconnect with the Last-Event-ID header (or last_event_id query) to
+// @Description receive the events missed in between.
+// @Tags Order
+// @Security ApiKeyAuth
+// @Produce text/event-stream
+// @Param courier_id query string false "courier_id"
+// @Param customer_id query string false "customer_id"
+// @Param status query string false "status"
+// @Param last_event_id query string false "last_event_id"
+// @Param Last-Event-ID header string false "Last-Event-ID"
+// @Success 200 {string} string "event stream"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) StreamOrders(c *gin.Context) {
+
+	filter := orderStreamFilter{
+		CourierId:  c.Query("courier_id"),
+		CustomerId: c.Query("customer_id"),
+		Status:     c.Query("status"),
+	}
+
+	if len(filter.CourierId) > 0 && !helper.IsValidUUID(filter.CourierId) {
+		h.handlerResponse(c, "stream orders", http.StatusBadRequest, "invalid courier id")
+		return
+	}
+
+	if len(filter.CustomerId) > 0 && !helper.IsValidUUID(filter.CustomerId) {
+		h.handlerResponse(c, "stream orders", http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	if courierId, ok := courierScope(c); ok {
+		if len(courierId) <= 0 {
+			h.handlerResponse(c, "stream orders", http.StatusForbidden, "user is not linked to a courier")
+			return
+		}
+		filter.CourierId = courierId
+	}
+
+	lastEventId := c.GetHeader(lastEventIdHeader)
+	if len(lastEventId) <= 0 {
+		lastEventId = c.Query("last_event_id")
+	}
+
+	var (
+		afterSeq int64
+		err      error
+	)
+
+	if len(lastEventId) > 0 {
+		afterSeq, err = strconv.ParseInt(lastEventId, 10, 64)
+		if err != nil || afterSeq < 0 {
+			h.handlerResponse(c, "stream orders", http.StatusBadRequest, "invalid last event id")
+			return
+		}
+	}
+
+	// Listen before replaying so that nothing published in between is lost;
+	// events seen during the replay are skipped when they arrive live.
+	events, stop := h.events.Listen(models.OutboxAggregateOrder + ".*")
+	defer stop()
+
+	var backlog []*models.OutboxEvent
+
+	if afterSeq > 0 {
+		backlog, err = h.storages.Outbox().GetList(context.Background(), &models.GetListOutboxEventRequest{
+			AggregateType: models.OutboxAggregateOrder,
+			AfterSeq:      afterSeq,
+			Limit:         orderStreamReplayPage,
+		})
+		if err != nil {
+			h.handlerResponse(c, "storage.outbox.getList", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	sse.Event{}.WriteContentType(c.Writer)
+
+	replayed := make(map[int64]bool)
+
+	for len(backlog) > 0 {
+		for _, event := range backlog {
+			replayed[event.Id] = true
+			h.writeOrderEvent(c, &filter, event)
+			afterSeq = event.Seq
+		}
+
+		if len(backlog) < orderStreamReplayPage {
+			break
+		}
+
+		backlog, err = h.storages.Outbox().GetList(context.Background(), &models.GetListOutboxEventRequest{
+			AggregateType: models.OutboxAggregateOrder,
+			AfterSeq:      afterSeq,
+			Limit:         orderStreamReplayPage,
+		})
+		if err != nil {
+			h.logger.Error("storage.outbox.getList", logger.Error(err))
+			return
+		}
+	}
+
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(orderStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+
+			if !replayed[event.Id] {
+				h.writeOrderEvent(c, &filter, event)
+			}
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+func (h *Handler) writeOrderEvent(c *gin.Context, filter *orderStreamFilter, event *models.OutboxEvent) {
+
+	if !filter.matches(event) {
+		return
+	}
+
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.Seq, 10),
+		Event: event.EventType,
+		Data:  event.Payload,
+	})
+}
@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/logger"
+	"app/pkg/payment"
+	"app/storage"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Initiate Payment godoc
+// @ID initiate_payment
+// @Router /order/{id}/payments [POST]
+// @Summary Initiate Payment
+// @Description Start a payment for an order; amount defaults to the outstanding balance
+// @Tags Payment
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "order id"
+// @Param Payment body models.InitiatePayment true "InitiatePaymentRequest"
+// @Success 201 {object} Response{data=models.Payment} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 502 {object} Response{data=string} "Provider Error"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) InitiatePayment(c *gin.Context) {
+
+	var initiatePayment models.InitiatePayment
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "initiate payment", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&initiatePayment)
+	if err != nil {
+		h.handlerResponse(c, "initiate payment", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	provider, err := h.payments.Get(initiatePayment.Provider)
+	if err != nil {
+		h.handlerResponse(c, "initiate payment", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	order, ok := h.paymentOrder(c, "initiate payment", id)
+	if !ok {
+		return
+	}
+
+	if initiatePayment.Amount.IsZero() {
+		initiatePayment.Amount = order.Outstanding
+	} else if len(initiatePayment.Amount.Currency) <= 0 {
+		initiatePayment.Amount.Currency = order.TotalPrice.Currency
+	}
+
+	if !initiatePayment.Amount.IsPositive() {
+		h.handlerResponse(c, "initiate payment", http.StatusBadRequest, "nothing to pay")
+		return
+	}
+
+	paymentId, err := h.storages.Payment().Create(context.Background(), &models.CreatePayment{
+		OrderId:  id,
+		Provider: initiatePayment.Provider,
+		Amount:   initiatePayment.Amount,
+	})
+	if errors.Is(err, storage.ErrPaymentExceedsBalance) || errors.Is(err, storage.ErrCurrencyMismatch) {
+		h.handlerResponse(c, "storage.payment.create", http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.payment.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result, err := provider.Initiate(context.Background(), &payment.Charge{
+		PaymentId: paymentId,
+		OrderId:   id,
+		Amount:    initiatePayment.Amount,
+		Token:     initiatePayment.Token,
+	})
+	if err != nil {
+		h.failPayment(paymentId, err.Error())
+		h.handlerResponse(c, "payment.provider.initiate", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	if result.Status != models.PaymentStatusPending || len(result.ExternalId) > 0 {
+		err = h.storages.Payment().UpdateStatus(context.Background(), &models.UpdatePaymentStatus{
+			Id:            paymentId,
+			Status:        result.Status,
+			ExternalId:    result.ExternalId,
+			FailureReason: result.FailureReason,
+		})
+		if err != nil {
+			h.handlerResponse(c, "storage.payment.updateStatus", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	resp, err := h.storages.Payment().GetByID(context.Background(), &models.PaymentPrimaryKey{Id: paymentId})
+	if err != nil {
+		h.handlerResponse(c, "storage.payment.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "initiate payment", http.StatusCreated, resp)
+}
+
+// Get List Payment godoc
+// @ID get_list_payment
+// @Router /order/{id}/payments [GET]
+// @Summary Get List Payment
+// @Description Get the payments of an order
+// @Tags Payment
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "order id"
+// @Success 200 {object} Response{data=models.GetListPaymentResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListPayment(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get list payment", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	if _, ok := h.paymentOrder(c, "get list payment", id); !ok {
+		return
+	}
+
+	resp, err := h.storages.Payment().GetList(context.Background(), &models.GetListPaymentRequest{OrderId: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.payment.getList", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list payment", http.StatusOK, resp)
+}
+
+// Get By ID Payment godoc
+// @ID get_by_id_payment
+// @Router /payment/{id} [GET]
+// @Summary Get By ID Payment
+// @Description Get By ID Payment
+// @Tags Payment
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.Payment} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdPayment(c *gin.Context) {
+
+	resp, ok := h.getPayment(c, "get by id payment")
+	if !ok {
+		return
+	}
+
+	h.handlerResponse(c, "get by id payment", http.StatusOK, resp)
+}
+
+// Confirm Payment godoc
+// @ID confirm_payment
+// @Router /payment/{id}/confirm [POST]
+// @Summary Confirm Payment
+// @Description Capture a pending payment and add it to the paid amount of its order
+// @Tags Payment
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.Payment} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Payment Not Pending"
+// @Response 502 {object} Response{data=string} "Provider Error"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) ConfirmPayment(c *gin.Context) {
+
+	current, ok := h.getPayment(c, "confirm payment")
+	if !ok {
+		return
+	}
+
+	if current.Status != models.PaymentStatusPending {
+		h.handlerResponse(c, "confirm payment", http.StatusConflict, storage.ErrPaymentNotPending.Error())
+		return
+	}
+
+	provider, err := h.payments.Get(current.Provider)
+	if err != nil {
+		h.handlerResponse(c, "confirm payment", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result, err := provider.Confirm(context.Background(), current.ExternalId)
+	if err != nil {
+		h.handlerResponse(c, "payment.provider.confirm", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	h.settlePayment(c, "confirm payment", current.Id, result)
+}
+
+// Fail Payment godoc
+// @ID fail_payment
+// @Router /payment/{id}/fail [POST]
+// @Summary Fail Payment
+// @Description Give up on a pending payment
+// @Tags Payment
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Payment body models.FailPayment true "FailPaymentRequest"
+// @Success 200 {object} Response{data=models.Payment} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Payment Not Pending"
+// @Response 502 {object} Response{data=string} "Provider Error"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) FailPayment(c *gin.Context) {
+
+	var failPayment models.FailPayment
+
+	err := c.ShouldBindJSON(&failPayment)
+	if err != nil {
+		h.handlerResponse(c, "fail payment", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(failPayment.Reason) <= 0 {
+		h.handlerResponse(c, "fail payment", http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	current, ok := h.getPayment(c, "fail payment")
+	if !ok {
+		return
+	}
+
+	if current.Status != models.PaymentStatusPending {
+		h.handlerResponse(c, "fail payment", http.StatusConflict, storage.ErrPaymentNotPending.Error())
+		return
+	}
+
+	provider, err := h.payments.Get(current.Provider)
+	if err != nil {
+		h.handlerResponse(c, "fail payment", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = provider.Cancel(context.Background(), current.ExternalId)
+	if err != nil {
+		h.handlerResponse(c, "payment.provider.cancel", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	h.settlePayment(c, "fail payment", current.Id, &payment.Result{
+		Status:        models.PaymentStatusFailed,
+		FailureReason: failPayment.Reason,
+	})
+}
+
+// paymentOrder loads the order of a payment request; couriers may only handle
+// payments of orders assigned to them.
+func (h *Handler) paymentOrder(c *gin.Context, path, orderId string) (*models.Order, bool) {
+
+	order, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: orderId})
+	if err != nil {
+		h.handlerResponse(c, "storage.order.getByID", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	if !h.checkCourierOrder(c, path, order) {
+		return nil, false
+	}
+
+	return order, true
+}
+
+func (h *Handler) getPayment(c *gin.Context, path string) (*models.Payment, bool) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, path, http.StatusBadRequest, "invalid payment id")
+		return nil, false
+	}
+
+	resp, err := h.storages.Payment().GetByID(context.Background(), &models.PaymentPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.payment.getByID", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	if _, ok := h.paymentOrder(c, path, resp.OrderId); !ok {
+		return nil, false
+	}
+
+	return resp, true
+}
+
+func (h *Handler) settlePayment(c *gin.Context, path, id string, result *payment.Result) {
+
+	err := h.storages.Payment().UpdateStatus(context.Background(), &models.UpdatePaymentStatus{
+		Id:            id,
+		Status:        result.Status,
+		ExternalId:    result.ExternalId,
+		FailureReason: result.FailureReason,
+	})
+	if errors.Is(err, storage.ErrPaymentNotPending) {
+		h.handlerResponse(c, "storage.payment.updateStatus", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.payment.updateStatus", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Payment().GetByID(context.Background(), &models.PaymentPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.payment.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, path, http.StatusOK, resp)
+}
+
+// failPayment marks a payment failed after its provider errored; the error
+// itself is what the caller is told about.
+func (h *Handler) failPayment(id, reason string) {
+
+	err := h.storages.Payment().UpdateStatus(context.Background(), &models.UpdatePaymentStatus{
+		Id:            id,
+		Status:        models.PaymentStatusFailed,
+		FailureReason: reason,
+	})
+	if err != nil {
+		h.logger.Warn("fail payment", logger.String("payment_id", id), logger.Error(err))
+	}
+}
@@ -3,7 +3,9 @@ package handler
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/storage"
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +17,7 @@ import (
 // @Summary Create Product
 // @Description Create Product
 // @Tags Product
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param Product body models.CreateProduct true "CreateProductRequest"
@@ -31,6 +34,12 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	err = normalizeMoney(&createProduct.Price)
+	if err != nil {
+		h.handlerResponse(c, "create Product", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id, err := h.storages.Product().Create(context.Background(), &createProduct)
 	if err != nil {
 		h.handlerResponse(c, "storage.Product.create", http.StatusInternalServerError, err.Error())
@@ -52,6 +61,7 @@ func (h *Handler) CreateProduct(c *gin.Context) {
 // @Summary Get By ID Product
 // @Description Get By ID Product
 // @Tags Product
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -82,6 +92,7 @@ func (h *Handler) GetByIdProduct(c *gin.Context) {
 // @Summary Get List Product
 // @Description Get List Product
 // @Tags Product
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param offset query string false "offset"
@@ -123,6 +134,7 @@ func (h *Handler) GetListProduct(c *gin.Context) {
 // @Summary Update Product
 // @Description Update Product
 // @Tags Product
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -147,6 +159,12 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	err = normalizeMoney(&updateProduct.Price)
+	if err != nil {
+		h.handlerResponse(c, "update Product", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	updateProduct.Id = id
 
 	rowsAffected, err := h.storages.Product().Update(context.Background(), &updateProduct)
@@ -175,6 +193,7 @@ func (h *Handler) UpdateProduct(c *gin.Context) {
 // @Summary Update Patch Product
 // @Description Update Patch Product
 // @Tags Product
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -227,6 +246,7 @@ func (h *Handler) UpdatePatchProduct(c *gin.Context) {
 // @Summary Delete Product
 // @Description Delete Product
 // @Tags Product
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -251,3 +271,223 @@ func (h *Handler) DeleteProduct(c *gin.Context) {
 
 	h.handlerResponse(c, "update Product", http.StatusAccepted, nil)
 }
+
+// Adjust Stock Product godoc
+// @ID adjust_stock_product
+// @Router /product/{id}/stock/adjust [POST]
+// @Summary Adjust Stock Product
+// @Description Adjust Product stock with a reason code
+// @Tags Product
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param stock body models.AdjustStock true "AdjustStockRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Insufficient Stock"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) AdjustStockProduct(c *gin.Context) {
+
+	var adjustStock models.AdjustStock
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "adjust stock Product", http.StatusBadRequest, "invalid Product id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&adjustStock)
+	if err != nil {
+		h.handlerResponse(c, "adjust stock Product", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !helper.IsValidUUID(adjustStock.WarehouseId) {
+		h.handlerResponse(c, "adjust stock Product", http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	if adjustStock.Quantity == 0 {
+		h.handlerResponse(c, "adjust stock Product", http.StatusBadRequest, "quantity must not be zero")
+		return
+	}
+
+	switch adjustStock.Reason {
+	case models.StockReasonRestock,
+		models.StockReasonCorrection,
+		models.StockReasonDamage,
+		models.StockReasonLoss,
+		models.StockReasonReturn:
+	default:
+		h.handlerResponse(c, "adjust stock Product", http.StatusBadRequest, "invalid reason")
+		return
+	}
+
+	adjustStock.ProductId = id
+
+	err = h.storages.Product().AdjustStock(context.Background(), &adjustStock)
+	if errors.Is(err, storage.ErrInsufficientStock) {
+		h.handlerResponse(c, "storage.Product.adjustStock", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.Product.adjustStock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Product().GetByID(context.Background(), &models.ProductPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Product.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "adjust stock Product", http.StatusOK, resp)
+}
+
+// Get Stock Movements Product godoc
+// @ID get_stock_movements_product
+// @Router /product/{id}/stock/movements [GET]
+// @Summary Get Stock Movements Product
+// @Description Get Product stock ledger
+// @Tags Product
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Success 200 {object} Response{data=models.GetListStockMovementResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetStockMovementsProduct(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get stock movements Product", http.StatusBadRequest, "invalid Product id")
+		return
+	}
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get stock movements Product", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get stock movements Product", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.Product().GetStockMovements(context.Background(), &models.GetListStockMovementRequest{
+		ProductId: id,
+		Offset:    offset,
+		Limit:     limit,
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.Product.getStockMovements", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get stock movements Product", http.StatusOK, resp)
+}
+
+// Transfer Stock Product godoc
+// @ID transfer_stock_product
+// @Router /product/{id}/stock/transfer [POST]
+// @Summary Transfer Stock Product
+// @Description Move Product stock between warehouses
+// @Tags Product
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param stock body models.TransferStock true "TransferStockRequest"
+// @Success 200 {object} Response{data=models.GetProductStockResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Insufficient Stock"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) TransferStockProduct(c *gin.Context) {
+
+	var transferStock models.TransferStock
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "transfer stock Product", http.StatusBadRequest, "invalid Product id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&transferStock)
+	if err != nil {
+		h.handlerResponse(c, "transfer stock Product", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !helper.IsValidUUID(transferStock.FromWarehouseId) || !helper.IsValidUUID(transferStock.ToWarehouseId) {
+		h.handlerResponse(c, "transfer stock Product", http.StatusBadRequest, "invalid warehouse id")
+		return
+	}
+
+	if transferStock.FromWarehouseId == transferStock.ToWarehouseId {
+		h.handlerResponse(c, "transfer stock Product", http.StatusBadRequest, "warehouses must be different")
+		return
+	}
+
+	if transferStock.Quantity <= 0 {
+		h.handlerResponse(c, "transfer stock Product", http.StatusBadRequest, "quantity must be greater than zero")
+		return
+	}
+
+	transferStock.ProductId = id
+
+	err = h.storages.Product().TransferStock(context.Background(), &transferStock)
+	if errors.Is(err, storage.ErrInsufficientStock) {
+		h.handlerResponse(c, "storage.Product.transferStock", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.Product.transferStock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Product().GetStock(context.Background(), &models.ProductPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Product.getStock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "transfer stock Product", http.StatusOK, resp)
+}
+
+// Get Stock Product godoc
+// @ID get_stock_product
+// @Router /product/{id}/stock [GET]
+// @Summary Get Stock Product
+// @Description Get Product availability per warehouse
+// @Tags Product
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.GetProductStockResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetStockProduct(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get stock Product", http.StatusBadRequest, "invalid Product id")
+		return
+	}
+
+	resp, err := h.storages.Product().GetStock(context.Background(), &models.ProductPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Product.getStock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get stock Product", http.StatusOK, resp)
+}
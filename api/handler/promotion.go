@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/storage"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const promotionTimeLayout = "2006-01-02 15:04:05"
+
+// Create Promotion godoc
+// @ID create_promotion
+// @Router /promotion [POST]
+// @Summary Create Promotion
+// @Description Create Promotion
+// @Tags Promotion
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param Promotion body models.CreatePromotion true "CreatePromotionRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Code Taken"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreatePromotion(c *gin.Context) {
+
+	var createPromotion models.CreatePromotion
+
+	err := c.ShouldBindJSON(&createPromotion)
+	if err != nil {
+		h.handlerResponse(c, "create promotion", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validatePromotion(&createPromotion)
+	if err != nil {
+		h.handlerResponse(c, "create promotion", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storages.Promotion().Create(context.Background(), &createPromotion)
+	if errors.Is(err, storage.ErrPromoCodeTaken) {
+		h.handlerResponse(c, "storage.promotion.create", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.promotion.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Promotion().GetByID(context.Background(), &models.PromotionPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.promotion.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create promotion", http.StatusCreated, resp)
+}
+
+// Get By ID Promotion godoc
+// @ID get_by_id_promotion
+// @Router /promotion/{id} [GET]
+// @Summary Get By ID Promotion
+// @Description Get By ID Promotion
+// @Tags Promotion
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.Promotion} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdPromotion(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id promotion", http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	resp, err := h.storages.Promotion().GetByID(context.Background(), &models.PromotionPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.promotion.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id promotion", http.StatusOK, resp)
+}
+
+// Get List Promotion godoc
+// @ID get_list_promotion
+// @Router /promotion [GET]
+// @Summary Get List Promotion
+// @Description Get List Promotion
+// @Tags Promotion
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param search query string false "search"
+// @Success 200 {object} Response{data=models.GetListPromotionResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListPromotion(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get list promotion", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get list promotion", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.Promotion().GetList(context.Background(), &models.GetListPromotionRequest{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.promotion.getlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list promotion response", http.StatusOK, resp)
+}
+
+// Update Promotion godoc
+// @ID update_promotion
+// @Router /promotion/{id} [PUT]
+// @Summary Update Promotion
+// @Description Update Promotion
+// @Tags Promotion
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Promotion body models.UpdatePromotion true "UpdatePromotionRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Code Taken"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdatePromotion(c *gin.Context) {
+
+	var updatePromotion models.UpdatePromotion
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update promotion", http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updatePromotion)
+	if err != nil {
+		h.handlerResponse(c, "update promotion", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = normalizeMoney(&updatePromotion.Amount, &updatePromotion.MaxDiscount, &updatePromotion.MinOrderAmount)
+	if err != nil {
+		h.handlerResponse(c, "update promotion", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validatePromotion(&models.CreatePromotion{
+		Code:             updatePromotion.Code,
+		Name:             updatePromotion.Name,
+		DiscountType:     updatePromotion.DiscountType,
+		Value:            updatePromotion.Value,
+		Amount:           updatePromotion.Amount,
+		MaxDiscount:      updatePromotion.MaxDiscount,
+		MinOrderAmount:   updatePromotion.MinOrderAmount,
+		UsageLimit:       updatePromotion.UsageLimit,
+		PerCustomerLimit: updatePromotion.PerCustomerLimit,
+		StartsAt:         updatePromotion.StartsAt,
+		EndsAt:           updatePromotion.EndsAt,
+		ProductIds:       updatePromotion.ProductIds,
+		CategoryIds:      updatePromotion.CategoryIds,
+	})
+	if err != nil {
+		h.handlerResponse(c, "update promotion", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updatePromotion.Id = id
+
+	rowsAffected, err := h.storages.Promotion().Update(context.Background(), &updatePromotion)
+	if errors.Is(err, storage.ErrPromoCodeTaken) {
+		h.handlerResponse(c, "storage.promotion.update", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.promotion.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.promotion.update", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.Promotion().GetByID(context.Background(), &models.PromotionPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.promotion.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update promotion", http.StatusAccepted, resp)
+}
+
+// Update Patch Promotion godoc
+// @ID update_patch_promotion
+// @Router /promotion/{id} [PATCH]
+// @Summary Update Patch Promotion
+// @Description Update Patch Promotion
+// @Tags Promotion
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Promotion body models.PatchRequest true "UpdatePatchPromotionRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Code Taken"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdatePatchPromotion(c *gin.Context) {
+
+	var object models.PatchRequest
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update patch promotion", http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&object)
+	if err != nil {
+		h.handlerResponse(c, "update patch promotion", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	object.ID = id
+
+	rowsAffected, err := h.storages.Promotion().Patch(context.Background(), &object)
+	if errors.Is(err, storage.ErrPromoCodeTaken) {
+		h.handlerResponse(c, "storage.promotion.patch", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.promotion.patch", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.promotion.patch", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.Promotion().GetByID(context.Background(), &models.PromotionPrimaryKey{Id: object.ID})
+	if err != nil {
+		h.handlerResponse(c, "storage.promotion.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update patch promotion", http.StatusAccepted, resp)
+}
+
+// Delete Promotion godoc
+// @ID delete_promotion
+// @Router /promotion/{id} [DELETE]
+// @Summary Delete Promotion
+// @Description Delete a promotion that was never redeemed
+// @Tags Promotion
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Promotion In Use"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeletePromotion(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "delete promotion", http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	err := h.storages.Promotion().Delete(context.Background(), &models.PromotionPrimaryKey{Id: id})
+	if errors.Is(err, storage.ErrPromotionInUse) {
+		h.handlerResponse(c, "storage.promotion.delete", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.promotion.delete", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "delete promotion", http.StatusAccepted, nil)
+}
+
+func validatePromotion(promotion *models.CreatePromotion) error {
+
+	if len(promotion.Name) <= 0 {
+		return errors.New("name is required")
+	}
+
+	switch promotion.DiscountType {
+	case models.DiscountTypePercentage:
+		if promotion.Value <= 0 || promotion.Value > 100 {
+			return errors.New("percentage must be between 0 and 100")
+		}
+	case models.DiscountTypeFixed:
+		if !promotion.Amount.IsPositive() {
+			return errors.New("amount must be greater than zero")
+		}
+	default:
+		return errors.New("discount type must be percentage or fixed")
+	}
+
+	err := normalizeMoney(&promotion.Amount, &promotion.MaxDiscount, &promotion.MinOrderAmount)
+	if err != nil {
+		return err
+	}
+
+	if promotion.UsageLimit < 0 || promotion.PerCustomerLimit < 0 {
+		return errors.New("limits must not be negative")
+	}
+
+	var startsAt, endsAt time.Time
+
+	if len(promotion.StartsAt) > 0 {
+		t, err := time.Parse(promotionTimeLayout, promotion.StartsAt)
+		if err != nil {
+			return errors.New("starts_at must look like " + promotionTimeLayout)
+		}
+		startsAt = t
+	}
+
+	if len(promotion.EndsAt) > 0 {
+		t, err := time.Parse(promotionTimeLayout, promotion.EndsAt)
+		if err != nil {
+			return errors.New("ends_at must look like " + promotionTimeLayout)
+		}
+		endsAt = t
+	}
+
+	if !startsAt.IsZero() && !endsAt.IsZero() && !endsAt.After(startsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	for _, id := range promotion.ProductIds {
+		if !helper.IsValidUUID(id) {
+			return errors.New("invalid product id")
+		}
+	}
+
+	for _, id := range promotion.CategoryIds {
+		if !helper.IsValidUUID(id) {
+			return errors.New("invalid category id")
+		}
+	}
+
+	return nil
+}
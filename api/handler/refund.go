@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/storage"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Refund godoc
+// @ID create_refund
+// @Router /order/{id}/refunds [POST]
+// @Summary Create Refund
+// @Description Refund order lines, or everything still refundable when no items are given
+// @Tags Refund
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "order id"
+// @Param Refund body models.CreateRefund true "CreateRefundRequest"
+// @Success 201 {object} Response{data=models.Order} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 404 {object} Response{data=string} "Order Not Found"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateRefund(c *gin.Context) {
+
+	var createRefund models.CreateRefund
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "create refund", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&createRefund)
+	if err != nil {
+		h.handlerResponse(c, "create refund", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateRefund(&createRefund)
+	if err != nil {
+		h.handlerResponse(c, "create refund", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	createRefund.OrderId = id
+	createRefund.ActorType, createRefund.ActorId = models.ActorTypeUser, c.GetString(ContextUserId)
+
+	_, err = h.storages.Order().Refund(context.Background(), &createRefund)
+	if errors.Is(err, storage.ErrOrderNotFound) {
+		h.handlerResponse(c, "storage.order.refund", http.StatusNotFound, err.Error())
+		return
+	} else if errors.Is(err, storage.ErrRefundExceedsPayments) || errors.Is(err, storage.ErrRefundItemNotFound) ||
+		errors.Is(err, storage.ErrRefundQuantityExceeded) {
+		h.handlerResponse(c, "storage.order.refund", http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.order.refund", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Order().GetByID(context.Background(), &models.OrderPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.order.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create refund", http.StatusCreated, resp)
+}
+
+// Get List Refund godoc
+// @ID get_list_refund
+// @Router /order/{id}/refunds [GET]
+// @Summary Get List Refund
+// @Description Get the refunds of an order
+// @Tags Refund
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "order id"
+// @Success 200 {object} Response{data=models.GetListRefundResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListRefund(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get list refund", http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	if _, ok := h.paymentOrder(c, "get list refund", id); !ok {
+		return
+	}
+
+	resp, err := h.storages.Order().GetRefunds(context.Background(), &models.OrderPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.order.getRefunds", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list refund", http.StatusOK, resp)
+}
+
+func validateRefund(refund *models.CreateRefund) error {
+
+	if len(refund.Reason) <= 0 {
+		return errors.New("reason is required")
+	}
+
+	seen := make(map[string]bool, len(refund.Items))
+
+	for _, item := range refund.Items {
+		if item == nil || !helper.IsValidUUID(item.OrderItemId) {
+			return errors.New("invalid order item id")
+		}
+
+		if item.Quantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+
+		if seen[item.OrderItemId] {
+			return errors.New("order item is listed more than once")
+		}
+		seen[item.OrderItemId] = true
+	}
+
+	return nil
+}
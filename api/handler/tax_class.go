@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/storage"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Tax Class godoc
+// @ID create_tax_class
+// @Router /tax-class [POST]
+// @Summary Create Tax Class
+// @Description Create Tax Class
+// @Tags Tax Class
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param TaxClass body models.CreateTaxClass true "CreateTaxClassRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateTaxClass(c *gin.Context) {
+
+	var createTaxClass models.CreateTaxClass
+
+	err := c.ShouldBindJSON(&createTaxClass)
+	if err != nil {
+		h.handlerResponse(c, "create tax class", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateTaxClass(createTaxClass.Name, createTaxClass.Rate)
+	if err != nil {
+		h.handlerResponse(c, "create tax class", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storages.TaxClass().Create(context.Background(), &createTaxClass)
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.TaxClass().GetByID(context.Background(), &models.TaxClassPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create tax class", http.StatusCreated, resp)
+}
+
+// Get By ID Tax Class godoc
+// @ID get_by_id_tax_class
+// @Router /tax-class/{id} [GET]
+// @Summary Get By ID Tax Class
+// @Description Get By ID Tax Class
+// @Tags Tax Class
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=models.TaxClass} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdTaxClass(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id tax class", http.StatusBadRequest, "invalid tax class id")
+		return
+	}
+
+	resp, err := h.storages.TaxClass().GetByID(context.Background(), &models.TaxClassPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id tax class", http.StatusOK, resp)
+}
+
+// Get List Tax Class godoc
+// @ID get_list_tax_class
+// @Router /tax-class [GET]
+// @Summary Get List Tax Class
+// @Description Get List Tax Class
+// @Tags Tax Class
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param search query string false "search"
+// @Success 200 {object} Response{data=models.GetListTaxClassResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListTaxClass(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get list tax class", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get list tax class", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.TaxClass().GetList(context.Background(), &models.GetListTaxClassRequest{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.getlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list tax class response", http.StatusOK, resp)
+}
+
+// Update Tax Class godoc
+// @ID update_tax_class
+// @Router /tax-class/{id} [PUT]
+// @Summary Update Tax Class
+// @Description Update Tax Class; placed orders keep the rate they were taxed at
+// @Tags Tax Class
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param TaxClass body models.UpdateTaxClass true "UpdateTaxClassRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateTaxClass(c *gin.Context) {
+
+	var updateTaxClass models.UpdateTaxClass
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update tax class", http.StatusBadRequest, "invalid tax class id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateTaxClass)
+	if err != nil {
+		h.handlerResponse(c, "update tax class", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateTaxClass(updateTaxClass.Name, updateTaxClass.Rate)
+	if err != nil {
+		h.handlerResponse(c, "update tax class", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updateTaxClass.Id = id
+
+	rowsAffected, err := h.storages.TaxClass().Update(context.Background(), &updateTaxClass)
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.TaxClass.update", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.TaxClass().GetByID(context.Background(), &models.TaxClassPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update tax class", http.StatusAccepted, resp)
+}
+
+// Update Patch Tax Class godoc
+// @ID update_patch_tax_class
+// @Router /tax-class/{id} [PATCH]
+// @Summary Update Patch Tax Class
+// @Description Update Patch Tax Class
+// @Tags Tax Class
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param TaxClass body models.PatchRequest true "UpdatePatchTaxClassRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdatePatchTaxClass(c *gin.Context) {
+
+	var object models.PatchRequest
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update patch tax class", http.StatusBadRequest, "invalid tax class id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&object)
+	if err != nil {
+		h.handlerResponse(c, "update patch tax class", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	object.ID = id
+
+	rowsAffected, err := h.storages.TaxClass().Patch(context.Background(), &object)
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.patch", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.TaxClass.patch", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.TaxClass().GetByID(context.Background(), &models.TaxClassPrimaryKey{Id: object.ID})
+	if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update patch tax class", http.StatusAccepted, resp)
+}
+
+// Delete Tax Class godoc
+// @ID delete_tax_class
+// @Router /tax-class/{id} [DELETE]
+// @Summary Delete Tax Class
+// @Description Delete a tax class that no category or product uses
+// @Tags Tax Class
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Response 409 {object} Response{data=string} "Tax Class In Use"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeleteTaxClass(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "delete tax class", http.StatusBadRequest, "invalid tax class id")
+		return
+	}
+
+	err := h.storages.TaxClass().Delete(context.Background(), &models.TaxClassPrimaryKey{Id: id})
+	if errors.Is(err, storage.ErrTaxClassInUse) {
+		h.handlerResponse(c, "storage.TaxClass.delete", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		h.handlerResponse(c, "storage.TaxClass.delete", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "delete tax class", http.StatusAccepted, nil)
+}
+
+func validateTaxClass(name string, rate float64) error {
+
+	if len(name) <= 0 {
+		return errors.New("name is required")
+	}
+
+	if rate < 0 || rate >= 100 {
+		return errors.New("rate must be a percentage from 0 up to 100")
+	}
+
+	return nil
+}
@@ -3,7 +3,10 @@ package handler
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/security"
+	"app/storage"
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +18,7 @@ import (
 // @Summary Create User
 // @Description Create User
 // @Tags User
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param user body models.CreateUser true "CreateUserRequest"
@@ -31,8 +35,27 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	id, err := h.storages.User().Create(context.Background(), &createUser)
+	err = validateCredentials(createUser.Login, createUser.Password, true)
+	if err != nil {
+		h.handlerResponse(c, "create user", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(createUser.Role) <= 0 {
+		createUser.Role = security.RoleAnalyst
+	}
+
+	err = validateRole(createUser.Role, createUser.CourierId)
 	if err != nil {
+		h.handlerResponse(c, "create user", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storages.User().Create(context.Background(), &createUser)
+	if errors.Is(err, storage.ErrLoginTaken) {
+		h.handlerResponse(c, "storage.user.create", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
 		h.handlerResponse(c, "storage.user.create", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -52,6 +75,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 // @Summary Get By ID User
 // @Description Get By ID User
 // @Tags User
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -82,6 +106,7 @@ func (h *Handler) GetByIdUser(c *gin.Context) {
 // @Summary Get List user
 // @Description Get List user
 // @Tags User
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param offset query string false "offset"
@@ -123,6 +148,7 @@ func (h *Handler) GetListUser(c *gin.Context) {
 // @Summary Update User
 // @Description Update User
 // @Tags User
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -147,10 +173,29 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	err = validateCredentials(updateUser.Login, updateUser.Password, false)
+	if err != nil {
+		h.handlerResponse(c, "update user", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(updateUser.Role) <= 0 {
+		updateUser.Role = security.RoleAnalyst
+	}
+
+	err = validateRole(updateUser.Role, updateUser.CourierId)
+	if err != nil {
+		h.handlerResponse(c, "update user", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	updateUser.Id = id
 
 	rowsAffected, err := h.storages.User().Update(context.Background(), &updateUser)
-	if err != nil {
+	if errors.Is(err, storage.ErrLoginTaken) {
+		h.handlerResponse(c, "storage.user.update", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
 		h.handlerResponse(c, "storage.user.update", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -175,6 +220,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 // @Summary Update Patch User
 // @Description Update Patch User
 // @Tags User
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -201,8 +247,23 @@ func (h *Handler) UpdatePatchUser(c *gin.Context) {
 
 	object.ID = id
 
+	if password, ok := object.Fields["password"].(string); ok && len(password) < minPasswordLength {
+		h.handlerResponse(c, "update patch user", http.StatusBadRequest, "password is too short")
+		return
+	}
+
+	if role, ok := object.Fields["role"]; ok {
+		if role, _ := role.(string); !security.IsValidRole(role) {
+			h.handlerResponse(c, "update patch user", http.StatusBadRequest, "invalid role")
+			return
+		}
+	}
+
 	rowsAffected, err := h.storages.User().Patch(context.Background(), &object)
-	if err != nil {
+	if errors.Is(err, storage.ErrLoginTaken) {
+		h.handlerResponse(c, "storage.user.patch", http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
 		h.handlerResponse(c, "storage.user.getByID", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -227,6 +288,7 @@ func (h *Handler) UpdatePatchUser(c *gin.Context) {
 // @Summary Delete User
 // @Description Delete User
 // @Tags User
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
@@ -251,3 +313,44 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 
 	h.handlerResponse(c, "update user", http.StatusAccepted, nil)
 }
+
+const minPasswordLength = 8
+
+// validateCredentials checks the login and password of a user; on update an empty
+// password keeps the current one.
+func validateCredentials(login, password string, create bool) error {
+
+	if len(login) <= 0 {
+		if len(password) > 0 {
+			return errors.New("password requires a login")
+		}
+		return nil
+	}
+
+	if len(password) <= 0 && !create {
+		return nil
+	}
+
+	if len(password) < minPasswordLength {
+		return errors.New("password is too short")
+	}
+
+	return nil
+}
+
+func validateRole(role, courierId string) error {
+
+	if !security.IsValidRole(role) {
+		return errors.New("invalid role")
+	}
+
+	if role == security.RoleCourier && !helper.IsValidUUID(courierId) {
+		return errors.New("courier role requires a courier id")
+	}
+
+	if role != security.RoleCourier && len(courierId) > 0 {
+		return errors.New("only users with the courier role can have a courier id")
+	}
+
+	return nil
+}
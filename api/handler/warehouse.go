@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Warehouse godoc
+// @ID create_warehouse
+// @Router /warehouse [POST]
+// @Summary Create Warehouse
+// @Description Create Warehouse
+// @Tags Warehouse
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param Warehouse body models.CreateWarehouse true "CreateWarehouseRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateWarehouse(c *gin.Context) {
+
+	var createWarehouse models.CreateWarehouse
+
+	err := c.ShouldBindJSON(&createWarehouse)
+	if err != nil {
+		h.handlerResponse(c, "create warehouse", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storages.Warehouse().Create(context.Background(), &createWarehouse)
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Warehouse().GetByID(context.Background(), &models.WarehousePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create Warehouse", http.StatusCreated, resp)
+}
+
+// Get By ID Warehouse godoc
+// @ID get_by_id_warehouse
+// @Router /warehouse/{id} [GET]
+// @Summary Get By ID Warehouse
+// @Description Get By ID Warehouse
+// @Tags Warehouse
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdWarehouse(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id Warehouse", http.StatusBadRequest, "invalid Warehouse id")
+		return
+	}
+
+	resp, err := h.storages.Warehouse().GetByID(context.Background(), &models.WarehousePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id Warehouse", http.StatusCreated, resp)
+}
+
+// Get List Warehouse godoc
+// @ID get_list_Warehouse
+// @Router /warehouse [GET]
+// @Summary Get List Warehouse
+// @Description Get List Warehouse
+// @Tags Warehouse
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param search query string false "search"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListWarehouse(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get list Warehouse", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get list Warehouse", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.Warehouse().GetList(context.Background(), &models.GetListWarehouseRequest{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.getlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list Warehouse response", http.StatusOK, resp)
+}
+
+// Get Update Warehouse godoc
+// @ID update_warehouse
+// @Router /warehouse/{id} [PUT]
+// @Summary Update Warehouse
+// @Description Update Warehouse
+// @Tags Warehouse
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param warehouse body models.UpdateWarehouse true "UpdateWarehouseRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateWarehouse(c *gin.Context) {
+
+	var updateWarehouse models.UpdateWarehouse
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id Warehouse", http.StatusBadRequest, "invalid Warehouse id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateWarehouse)
+	if err != nil {
+		h.handlerResponse(c, "update Warehouse", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updateWarehouse.Id = id
+
+	rowsAffected, err := h.storages.Warehouse().Update(context.Background(), &updateWarehouse)
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.Warehouse.update", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.Warehouse().GetByID(context.Background(), &models.WarehousePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update Warehouse", http.StatusAccepted, resp)
+}
+
+// Update Patch Warehouse godoc
+// @ID updat_patch_warehouse
+// @Router /warehouse/{id} [PATCH]
+// @Summary Update Patch Warehouse
+// @Description Update Patch Warehouse
+// @Tags Warehouse
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Warehouse body models.PatchRequest true "UpdatePatchWarehouseRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdatePatchWarehouse(c *gin.Context) {
+
+	var object models.PatchRequest
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id Warehouse", http.StatusBadRequest, "invalid Warehouse id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&object)
+	if err != nil {
+		h.handlerResponse(c, "update patch Warehouse", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	object.ID = id
+
+	rowsAffected, err := h.storages.Warehouse().Patch(context.Background(), &object)
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.Warehouse.patch", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.Warehouse().GetByID(context.Background(), &models.WarehousePrimaryKey{Id: object.ID})
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update patch Warehouse", http.StatusAccepted, resp)
+}
+
+// Delete Warehouse godoc
+// @ID delete_warehouse
+// @Router /warehouse/{id} [DELETE]
+// @Summary Delete Warehouse
+// @Description Delete Warehouse
+// @Tags Warehouse
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Warehouse body models.WarehousePrimaryKey true "DeleteWarehouseRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeleteWarehouse(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id Warehouse", http.StatusBadRequest, "invalid Warehouse id")
+		return
+	}
+
+	err := h.storages.Warehouse().Delete(context.Background(), &models.WarehousePrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.Warehouse.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update Warehouse", http.StatusAccepted, nil)
+}
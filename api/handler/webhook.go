@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/webhook"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create Webhook godoc
+// @ID create_webhook
+// @Router /webhook [POST]
+// @Summary Create Webhook
+// @Description Create Webhook. A secret is generated when none is given; an empty event list subscribes to every event.
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param webhook body models.CreateWebhook true "CreateWebhookRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) CreateWebhook(c *gin.Context) {
+
+	var createWebhook models.CreateWebhook
+
+	err := c.ShouldBindJSON(&createWebhook)
+	if err != nil {
+		h.handlerResponse(c, "create webhook", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateWebhook(createWebhook.Url, createWebhook.Events)
+	if err != nil {
+		h.handlerResponse(c, "create webhook", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(createWebhook.Secret) <= 0 {
+		createWebhook.Secret, err = webhook.GenerateSecret()
+		if err != nil {
+			h.handlerResponse(c, "webhook.generateSecret", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	id, err := h.storages.Webhook().Create(context.Background(), &createWebhook)
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.create", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp, err := h.storages.Webhook().GetByID(context.Background(), &models.WebhookPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "create webhook", http.StatusCreated, resp)
+}
+
+// Get By ID Webhook godoc
+// @ID get_by_id_webhook
+// @Router /webhook/{id} [GET]
+// @Summary Get By ID Webhook
+// @Description Get By ID Webhook
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetByIdWebhook(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "get by id webhook", http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	resp, err := h.storages.Webhook().GetByID(context.Background(), &models.WebhookPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get by id webhook", http.StatusOK, resp)
+}
+
+// Get List Webhook godoc
+// @ID get_list_webhook
+// @Router /webhook [GET]
+// @Summary Get List Webhook
+// @Description Get List Webhook
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param search query string false "search"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetListWebhook(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get list webhook", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get list webhook", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	resp, err := h.storages.Webhook().GetList(context.Background(), &models.GetListWebhookRequest{
+		Offset: offset,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.getlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get list webhook response", http.StatusOK, resp)
+}
+
+// Update Webhook godoc
+// @ID update_webhook
+// @Router /webhook/{id} [PUT]
+// @Summary Update Webhook
+// @Description Update Webhook. An empty secret keeps the current one.
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param webhook body models.UpdateWebhook true "UpdateWebhookRequest"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+
+	var updateWebhook models.UpdateWebhook
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "update webhook", http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	err := c.ShouldBindJSON(&updateWebhook)
+	if err != nil {
+		h.handlerResponse(c, "update webhook", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateWebhook(updateWebhook.Url, updateWebhook.Events)
+	if err != nil {
+		h.handlerResponse(c, "update webhook", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(updateWebhook.Secret) <= 0 {
+		current, err := h.storages.Webhook().GetByID(context.Background(), &models.WebhookPrimaryKey{Id: id})
+		if err != nil {
+			h.handlerResponse(c, "storage.webhook.getByID", http.StatusInternalServerError, err.Error())
+			return
+		}
+		updateWebhook.Secret = current.Secret
+	}
+
+	updateWebhook.Id = id
+
+	rowsAffected, err := h.storages.Webhook().Update(context.Background(), &updateWebhook)
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.update", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.webhook.update", http.StatusBadRequest, "no rows affected")
+		return
+	}
+
+	resp, err := h.storages.Webhook().GetByID(context.Background(), &models.WebhookPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.getByID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "update webhook", http.StatusAccepted, resp)
+}
+
+// Delete Webhook godoc
+// @ID delete_webhook
+// @Router /webhook/{id} [DELETE]
+// @Summary Delete Webhook
+// @Description Delete Webhook together with its deliveries
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "delete webhook", http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	err := h.storages.Webhook().Delete(context.Background(), &models.WebhookPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.delete", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "delete webhook", http.StatusAccepted, nil)
+}
+
+// Get Webhook Dead Letters godoc
+// @ID get_webhook_dead_letters
+// @Router /webhook/dead-letters [GET]
+// @Summary Get Webhook Dead Letters
+// @Description Deliveries that failed every attempt, newest first
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param offset query string false "offset"
+// @Param limit query string false "limit"
+// @Param webhook_id query string false "webhook_id"
+// @Success 200 {object} Response{data=models.GetListWebhookDeliveryResponse} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) GetWebhookDeadLetters(c *gin.Context) {
+
+	offset, err := h.getOffsetQuery(c.Query("offset"))
+	if err != nil {
+		h.handlerResponse(c, "get webhook dead letters", http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	limit, err := h.getLimitQuery(c.Query("limit"))
+	if err != nil {
+		h.handlerResponse(c, "get webhook dead letters", http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	webhookId := c.Query("webhook_id")
+	if len(webhookId) > 0 && !helper.IsValidUUID(webhookId) {
+		h.handlerResponse(c, "get webhook dead letters", http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	resp, err := h.storages.Webhook().GetDeliveries(context.Background(), &models.GetListWebhookDeliveryRequest{
+		Offset:    offset,
+		Limit:     limit,
+		WebhookId: webhookId,
+		Status:    models.WebhookDeliveryDead,
+	})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.getDeliveries", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.handlerResponse(c, "get webhook dead letters", http.StatusOK, resp)
+}
+
+// Retry Webhook Dead Letter godoc
+// @ID retry_webhook_dead_letter
+// @Router /webhook/dead-letters/{id}/retry [POST]
+// @Summary Retry Webhook Dead Letter
+// @Description Queue a dead delivery again with a fresh attempt budget
+// @Tags Webhook
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "delivery id"
+// @Success 200 {object} Response{data=string} "Success Request"
+// @Response 400 {object} Response{data=string} "Bad Request"
+// @Failure 500 {object} Response{data=string} "Server Error"
+func (h *Handler) RetryWebhookDeadLetter(c *gin.Context) {
+
+	id := c.Param("id")
+
+	if !helper.IsValidUUID(id) {
+		h.handlerResponse(c, "retry webhook dead letter", http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	rowsAffected, err := h.storages.Webhook().RetryDelivery(context.Background(), &models.WebhookDeliveryPrimaryKey{Id: id})
+	if err != nil {
+		h.handlerResponse(c, "storage.webhook.retryDelivery", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rowsAffected <= 0 {
+		h.handlerResponse(c, "storage.webhook.retryDelivery", http.StatusBadRequest, "no dead delivery with this id")
+		return
+	}
+
+	h.handlerResponse(c, "retry webhook dead letter", http.StatusAccepted, nil)
+}
+
+func validateWebhook(rawUrl string, events []string) error {
+
+	u, err := url.ParseRequestURI(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) <= 0 {
+		return errors.New("webhook url must be an absolute http or https url")
+	}
+
+	for _, event := range events {
+		if !webhook.IsValidFilter(event) {
+			return errors.New("unknown webhook event: " + event)
+		}
+	}
+
+	return nil
+}
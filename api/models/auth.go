@@ -0,0 +1,56 @@
+package models
+
+type LoginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RequestCustomerOTP struct {
+	Phone string `json:"phone"`
+}
+
+type RequestCustomerOTPResponse struct {
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+type VerifyCustomerOTP struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+}
+
+type CustomerTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	Customer    *Customer `json:"customer"`
+}
+
+// CreateCustomerOTP replaces the pending code of a phone, unless the previous
+// one was sent less than ResendAfter seconds ago.
+type CreateCustomerOTP struct {
+	Phone       string
+	CodeHash    string
+	ExpiresIn   int64
+	ResendAfter int64
+}
+
+// CheckCustomerOTP verifies a code, allowing at most MaxAttempts wrong guesses.
+// The count survives resends; reaching it locks the phone for LockoutFor
+// seconds, after which the next code starts over.
+type CheckCustomerOTP struct {
+	Phone       string
+	Code        string
+	MaxAttempts int
+	LockoutFor  int64
+}
@@ -1,10 +1,11 @@
 package models
 
 type Category struct {
-	Id        string `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	TaxClassId string `json:"tax_class_id"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
 }
 
 type ReturnCategory struct {
@@ -16,12 +17,14 @@ type CategoryPrimaryKey struct {
 }
 
 type CreateCategory struct {
-	Name string `json:"name"`
+	Name       string `json:"name"`
+	TaxClassId string `json:"tax_class_id"`
 }
 
 type UpdateCategory struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	TaxClassId string `json:"tax_class_id"`
 }
 
 type GetListCategoryRequest struct {
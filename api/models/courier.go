@@ -1,18 +1,37 @@
 package models
 
+const (
+	CourierStatusOnline  = "online"
+	CourierStatusOffline = "offline"
+	CourierStatusBusy    = "busy"
+)
+
 type Courier struct {
-	Id        string `json:"id"`
-	Name      string `json:"name"`
-	Phone     string `json:"phone"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Id             string  `json:"id"`
+	Name           string  `json:"name"`
+	Phone          string  `json:"phone"`
+	Status         string  `json:"status"`
+	LastLat        float64 `json:"last_lat"`
+	LastLng        float64 `json:"last_lng"`
+	LastLocationAt string  `json:"last_location_at"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
 }
 
 type ReturnCourier struct {
+	Id    string `json:"id"`
 	Name  string `json:"name"`
 	Phone string `json:"phone"`
 }
 
+// CourierLoad is an available courier together with the number of orders it is carrying.
+type CourierLoad struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	ActiveOrders int    `json:"active_orders"`
+}
+
 type CourierPrimaryKey struct {
 	Id string `json:"id"`
 }
@@ -29,6 +48,11 @@ type UpdateCourier struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+type UpdateCourierStatus struct {
+	Id     string `json:"id"`
+	Status string `json:"status"`
+}
+
 type GetListCourierRequest struct {
 	Offset int    `json:"offset"`
 	Limit  int    `json:"limit"`
@@ -39,3 +63,69 @@ type GetListCourierResponse struct {
 	Count    int        `json:"count"`
 	Couriers []*Courier `json:"couriers"`
 }
+
+type CourierLocation struct {
+	CourierId  string  `json:"courier_id"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	RecordedAt string  `json:"recorded_at"`
+}
+
+type CreateCourierLocation struct {
+	CourierId string  `json:"courier_id"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+}
+
+type GetListCourierLocationRequest struct {
+	CourierId string `json:"courier_id"`
+	Limit     int    `json:"limit"`
+}
+
+type GetListCourierLocationResponse struct {
+	Count     int                `json:"count"`
+	Locations []*CourierLocation `json:"locations"`
+}
+
+type NearbyCourier struct {
+	Id       string  `json:"id"`
+	Name     string  `json:"name"`
+	Phone    string  `json:"phone"`
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	Distance float64 `json:"distance"`
+}
+
+type GetNearbyCourierRequest struct {
+	Lat    float64 `json:"lat"`
+	Lng    float64 `json:"lng"`
+	Radius float64 `json:"radius"`
+	Limit  int     `json:"limit"`
+}
+
+type GetNearbyCourierResponse struct {
+	Count    int              `json:"count"`
+	Couriers []*NearbyCourier `json:"couriers"`
+}
+
+// CourierMessage is a message for a courier's WebSocket channel. It stays
+// pending until the courier acknowledges it, so couriers that were offline
+// receive it when they reconnect.
+type CourierMessage struct {
+	Id        string `json:"id"`
+	CourierId string `json:"courier_id"`
+	Type      string `json:"type"`
+	Payload   string `json:"payload"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CourierMessagePrimaryKey struct {
+	Id        string `json:"id"`
+	CourierId string `json:"courier_id"`
+}
+
+type CreateCourierMessage struct {
+	CourierId string `json:"courier_id"`
+	Type      string `json:"type"`
+	Payload   []byte `json:"payload"`
+}
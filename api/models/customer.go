@@ -4,11 +4,13 @@ type Customer struct {
 	Id        string `json:"id"`
 	Name      string `json:"name"`
 	Phone     string `json:"phone"`
+	Language  string `json:"language"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
 
 type ReturnCustomer struct {
+	Id    string `json:"id"`
 	Name  string `json:"name"`
 	Phone string `json:"phone"`
 }
@@ -18,14 +20,16 @@ type CustomerPrimaryKey struct {
 }
 
 type CreateCustomer struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Language string `json:"language"`
 }
 
 type UpdateCustomer struct {
-	Id    string `json:"id"`
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Language string `json:"language"`
 }
 
 type GetListCustomerRequest struct {
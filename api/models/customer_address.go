@@ -0,0 +1,64 @@
+package models
+
+type CustomerAddress struct {
+	Id         string  `json:"id"`
+	CustomerId string  `json:"customer_id"`
+	Label      string  `json:"label"`
+	Street     string  `json:"street"`
+	Apartment  string  `json:"apartment"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	Notes      string  `json:"notes"`
+	IsDefault  bool    `json:"is_default"`
+	CreatedAt  string  `json:"created_at"`
+	UpdatedAt  string  `json:"updated_at"`
+}
+
+type CustomerAddressPrimaryKey struct {
+	Id         string `json:"id"`
+	CustomerId string `json:"customer_id"`
+}
+
+type CreateCustomerAddress struct {
+	CustomerId string  `json:"customer_id"`
+	Label      string  `json:"label"`
+	Street     string  `json:"street"`
+	Apartment  string  `json:"apartment"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	Notes      string  `json:"notes"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+type UpdateCustomerAddress struct {
+	Id         string  `json:"id"`
+	CustomerId string  `json:"customer_id"`
+	Label      string  `json:"label"`
+	Street     string  `json:"street"`
+	Apartment  string  `json:"apartment"`
+	Lat        float64 `json:"lat"`
+	Lng        float64 `json:"lng"`
+	Notes      string  `json:"notes"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+type GetListCustomerAddressRequest struct {
+	CustomerId string `json:"customer_id"`
+}
+
+type GetListCustomerAddressResponse struct {
+	Count     int                `json:"count"`
+	Addresses []*CustomerAddress `json:"addresses"`
+}
+
+// OrderAddress is the delivery address copied onto an order when it is placed,
+// so later edits to the address book do not change past orders.
+type OrderAddress struct {
+	AddressId string  `json:"address_id"`
+	Label     string  `json:"label"`
+	Street    string  `json:"street"`
+	Apartment string  `json:"apartment"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	Notes     string  `json:"notes"`
+}
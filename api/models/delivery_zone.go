@@ -0,0 +1,56 @@
+package models
+
+import "app/pkg/money"
+
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+type DeliveryZone struct {
+	Id             string      `json:"id"`
+	Name           string      `json:"name"`
+	Polygon        []Point     `json:"polygon"`
+	DeliveryFee    money.Money `json:"delivery_fee"`
+	MinOrderAmount money.Money `json:"min_order_amount"`
+	Active         bool        `json:"active"`
+	CreatedAt      string      `json:"created_at"`
+	UpdatedAt      string      `json:"updated_at"`
+}
+
+type ReturnDeliveryZone struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type DeliveryZonePrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateDeliveryZone struct {
+	Name           string      `json:"name"`
+	Polygon        []Point     `json:"polygon"`
+	DeliveryFee    money.Money `json:"delivery_fee"`
+	MinOrderAmount money.Money `json:"min_order_amount"`
+	Active         bool        `json:"active"`
+}
+
+type UpdateDeliveryZone struct {
+	Id             string      `json:"id"`
+	Name           string      `json:"name"`
+	Polygon        []Point     `json:"polygon"`
+	DeliveryFee    money.Money `json:"delivery_fee"`
+	MinOrderAmount money.Money `json:"min_order_amount"`
+	Active         bool        `json:"active"`
+}
+
+type GetListDeliveryZoneRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
+}
+
+type GetListDeliveryZoneResponse struct {
+	Count         int             `json:"count"`
+	DeliveryZones []*DeliveryZone `json:"delivery_zones"`
+}
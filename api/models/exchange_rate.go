@@ -0,0 +1,53 @@
+package models
+
+// ExchangeRate is the price of one BaseCurrency unit in QuoteCurrency from
+// EffectiveFrom on. Rates are decimal strings so they are never rounded.
+type ExchangeRate struct {
+	Id            string `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+	EffectiveFrom string `json:"effective_from"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type ExchangeRatePrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// CreateExchangeRate adds a rate; an empty EffectiveFrom means now.
+type CreateExchangeRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+	EffectiveFrom string `json:"effective_from"`
+}
+
+type UpdateExchangeRate struct {
+	Id            string `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+	EffectiveFrom string `json:"effective_from"`
+}
+
+type GetListExchangeRateRequest struct {
+	Offset   int    `json:"offset"`
+	Limit    int    `json:"limit"`
+	Currency string `json:"currency"`
+}
+
+type GetListExchangeRateResponse struct {
+	Count         int             `json:"count"`
+	ExchangeRates []*ExchangeRate `json:"exchange_rates"`
+}
+
+// OrderExchangeRate is a rate an order was converted with, kept so its totals
+// never change when the rate table does.
+type OrderExchangeRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+	EffectiveFrom string `json:"effective_from"`
+}
@@ -0,0 +1,52 @@
+package models
+
+const (
+	NotificationStatusPending = "pending"
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed"
+)
+
+type Notification struct {
+	Id            string `json:"id"`
+	Channel       string `json:"channel"`
+	Recipient     string `json:"recipient"`
+	Event         string `json:"event"`
+	Language      string `json:"language"`
+	Subject       string `json:"subject"`
+	Body          string `json:"body"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error"`
+	NextAttemptAt string `json:"next_attempt_at"`
+	CreatedAt     string `json:"created_at"`
+	SentAt        string `json:"sent_at"`
+}
+
+type NotificationPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateNotification struct {
+	Channel   string `json:"channel"`
+	Recipient string `json:"recipient"`
+	Event     string `json:"event"`
+	Language  string `json:"language"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+}
+
+// ClaimNotifications picks up to Limit due notifications and hides them from
+// other workers for Lease seconds while they are being sent.
+type ClaimNotifications struct {
+	Limit int   `json:"limit"`
+	Lease int64 `json:"lease"`
+}
+
+// FailNotification records a failed send; the notification is retried after
+// RetryAfter seconds unless Dead is set.
+type FailNotification struct {
+	Id         string `json:"id"`
+	Error      string `json:"error"`
+	RetryAfter int64  `json:"retry_after"`
+	Dead       bool   `json:"dead"`
+}
@@ -1,46 +1,142 @@
 package models
 
+import "app/pkg/money"
+
+const (
+	OrderStatusNew       = "new"
+	OrderStatusAccepted  = "accepted"
+	OrderStatusPreparing = "preparing"
+	OrderStatusReady     = "ready"
+	OrderStatusPickedUp  = "picked_up"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusReturned  = "returned"
+)
+
 type Order struct {
-	Id         string         `json:"id"`
-	Name       string         `json:"name"`
-	Price      float64        `json:"product_price"`
-	TotalPrice float64        `json:"total_price"`
-	Quantity   int32          `json:"quantity"`
-	User       ReturnUser     `json:"user"`
-	Courier    ReturnCourier  `json:"courier"`
-	Customer   ReturnCustomer `json:"customer"`
-	Product    ReturnProduct  `json:"product"`
-	CreatedAt  string         `json:"created_at"`
-	UpdatedAt  string         `json:"updated_at"`
+	Id              string               `json:"id"`
+	Name            string               `json:"name"`
+	Status          string               `json:"status"`
+	WarehouseId     string               `json:"warehouse_id"`
+	DeliveryAddress OrderAddress         `json:"delivery_address"`
+	DeliveryZone    ReturnDeliveryZone   `json:"delivery_zone"`
+	Subtotal        money.Money          `json:"subtotal"`
+	DeliveryFee     money.Money          `json:"delivery_fee"`
+	DiscountAmount  money.Money          `json:"discount_amount"`
+	Discounts       []*OrderDiscount     `json:"discounts"`
+	PromoCode       string               `json:"promo_code"`
+	TaxInclusive    bool                 `json:"tax_inclusive"`
+	TaxAmount       money.Money          `json:"tax_amount"`
+	Taxes           []*OrderTax          `json:"taxes"`
+	ExchangeRates   []*OrderExchangeRate `json:"exchange_rates"`
+	TotalPrice      money.Money          `json:"total_price"`
+	Currency        string               `json:"currency"`
+	PaidAmount      money.Money          `json:"paid_amount"`
+	RefundedAmount  money.Money          `json:"refunded_amount"`
+	Outstanding     money.Money          `json:"outstanding"`
+	PaymentStatus   string               `json:"payment_status"`
+	User            ReturnUser           `json:"user"`
+	Courier         ReturnCourier        `json:"courier"`
+	Customer        ReturnCustomer       `json:"customer"`
+	Items           []*OrderItem         `json:"items"`
+	CreatedAt       string               `json:"created_at"`
+	UpdatedAt       string               `json:"updated_at"`
+}
+
+type OrderItem struct {
+	Id               string      `json:"id"`
+	ProductId        string      `json:"product_id"`
+	ProductName      string      `json:"product_name"`
+	CategoryId       string      `json:"category_id"`
+	CategoryName     string      `json:"category_name"`
+	Quantity         int32       `json:"quantity"`
+	Price            money.Money `json:"price"`
+	TotalPrice       money.Money `json:"total_price"`
+	RefundedQuantity int32       `json:"refunded_quantity"`
+	TaxRate          float64     `json:"tax_rate"`
+	TaxableAmount    money.Money `json:"taxable_amount"`
+	TaxAmount        money.Money `json:"tax_amount"`
 }
 
 type OrderPrimaryKey struct {
 	Id string `json:"id"`
 }
 
+// OrderInvoice is the invoice number an order got when it was delivered or
+// invoiced explicitly.
+type OrderInvoice struct {
+	OrderId  string `json:"order_id"`
+	Number   int64  `json:"number"`
+	IssuedAt string `json:"issued_at"`
+}
+
+type IssueOrderInvoice struct {
+	Id        string `json:"id"`
+	ActorType string `json:"-"`
+	ActorId   string `json:"-"`
+}
+
+type CreateOrderItem struct {
+	ProductId string `json:"product_id"`
+	Quantity  int32  `json:"quantity"`
+}
+
+type CreateOrderAddress struct {
+	Label     string  `json:"label"`
+	Street    string  `json:"street"`
+	Apartment string  `json:"apartment"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	Notes     string  `json:"notes"`
+}
+
+// CreateOrder takes either an AddressId from the customer's address book or an
+// inline Address; with neither, the customer's default address is used.
+// Currency defaults to UZS; prices in other currencies are converted into it.
+// CreateOrder is placed by the user of the token; UserId is set from it and
+// never taken from the request body.
 type CreateOrder struct {
-	Name       string `json:"name"`
-	Quantity   int32  `json:"quantity"`
-	UserId     string `json:"user_id"`
-	CustomerId string `json:"customer_id"`
-	ProductId  string `json:"product_id"`
-	CourierId  string `json:"courier_id"`
+	Name       string              `json:"name"`
+	UserId     string              `json:"-"`
+	CustomerId string              `json:"customer_id"`
+	AddressId  string              `json:"address_id"`
+	Address    *CreateOrderAddress `json:"address"`
+	Items      []*CreateOrderItem  `json:"items"`
+	PromoCode  string              `json:"promo_code"`
+	Currency   string              `json:"currency"`
 }
 
 type UpdateOrder struct {
-	Id         string `json:"id"`
-	Name       string `json:"name"`
-	Quantity   int32  `json:"quantity"`
-	UserId     string `json:"user_id"`
-	CustomerId string `json:"customer_id"`
-	ProductId  string `json:"product_id"`
-	CourierId  string `json:"courier_id"`
+	Id         string             `json:"id"`
+	Name       string             `json:"name"`
+	UserId     string             `json:"user_id"`
+	CustomerId string             `json:"customer_id"`
+	Items      []*CreateOrderItem `json:"items"`
+	ActorType  string             `json:"-"`
+	ActorId    string             `json:"-"`
+}
+
+type AssignCourier struct {
+	OrderId   string `json:"order_id"`
+	CourierId string `json:"courier_id"`
+	Note      string `json:"note"`
+}
+
+// TransitionOrder is recorded as made by ActorType/ActorId, which come from the
+// caller's token, never from the request body.
+type TransitionOrder struct {
+	Id        string `json:"id"`
+	Status    string `json:"status"`
+	ActorType string `json:"-"`
+	ActorId   string `json:"-"`
+	Note      string `json:"note"`
 }
 
 type GetListOrderRequest struct {
-	Offset int    `json:"offset"`
-	Limit  int    `json:"limit"`
-	Search string `json:"search"`
+	Offset    int    `json:"offset"`
+	Limit     int    `json:"limit"`
+	Search    string `json:"search"`
+	CourierId string `json:"courier_id"`
 }
 
 type GetListOrderResponse struct {
@@ -0,0 +1,33 @@
+package models
+
+const (
+	ActorTypeUser     = "user"
+	ActorTypeCourier  = "courier"
+	ActorTypeCustomer = "customer"
+)
+
+const (
+	OrderEventCreated         = "created"
+	OrderEventUpdated         = "updated"
+	OrderEventStatusChanged   = "status_changed"
+	OrderEventCourierAssigned = "courier_assigned"
+	OrderEventRefunded        = "refunded"
+	OrderEventInvoiced        = "invoiced"
+)
+
+type OrderEvent struct {
+	Id         string `json:"id"`
+	OrderId    string `json:"order_id"`
+	ActorType  string `json:"actor_type"`
+	ActorId    string `json:"actor_id"`
+	Event      string `json:"event"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Note       string `json:"note"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type GetOrderHistoryResponse struct {
+	Count  int           `json:"count"`
+	Events []*OrderEvent `json:"events"`
+}
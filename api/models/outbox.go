@@ -0,0 +1,69 @@
+package models
+
+const (
+	OutboxAggregateCustomer     = "customer"
+	OutboxAggregateAddress      = "customer_address"
+	OutboxAggregateUser         = "user"
+	OutboxAggregateCourier      = "courier"
+	OutboxAggregateCategory     = "category"
+	OutboxAggregateProduct      = "product"
+	OutboxAggregateStock        = "stock_movement"
+	OutboxAggregateOrder        = "order"
+	OutboxAggregateWarehouse    = "warehouse"
+	OutboxAggregateDeliveryZone = "delivery_zone"
+	OutboxAggregateWebhook      = "webhook"
+	OutboxAggregatePayment      = "payment"
+	OutboxAggregatePromotion    = "promotion"
+	OutboxAggregateTaxClass     = "tax_class"
+	OutboxAggregateExchangeRate = "exchange_rate"
+)
+
+const (
+	OutboxActionCreated         = "created"
+	OutboxActionUpdated         = "updated"
+	OutboxActionDeleted         = "deleted"
+	OutboxActionStatusChanged   = "status_changed"
+	OutboxActionLocationChanged = "location_changed"
+	OutboxActionAssigned        = "courier_assigned"
+	OutboxActionRefunded        = "refunded"
+)
+
+// OutboxEvent is a domain event written by a repo in the same transaction as
+// the change it describes. EventType is "<aggregate>.<action>", e.g.
+// "order.created"; Payload is the row as JSON after the change, or before it
+// for deletes. Seq is the position the relay handed the event out at, zero
+// until then.
+type OutboxEvent struct {
+	Id            int64  `json:"id"`
+	Seq           int64  `json:"seq"`
+	AggregateType string `json:"aggregate_type"`
+	AggregateId   string `json:"aggregate_id"`
+	EventType     string `json:"event_type"`
+	Payload       string `json:"payload"`
+	Attempts      int    `json:"attempts"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type OutboxEventPrimaryKey struct {
+	Id int64 `json:"id"`
+}
+
+type GetPendingOutboxEvents struct {
+	Limit int `json:"limit"`
+}
+
+// FailOutboxEvent records a failed relay attempt; Dead parks the event so the
+// relay moves on to the next one.
+type FailOutboxEvent struct {
+	Id    int64  `json:"id"`
+	Error string `json:"error"`
+	Dead  bool   `json:"dead"`
+}
+
+// GetListOutboxEventRequest pages through the relayed events of one aggregate
+// after AfterSeq; it backs resuming event streams.
+type GetListOutboxEventRequest struct {
+	AggregateType string `json:"aggregate_type"`
+	AfterSeq      int64  `json:"after_seq"`
+	Limit         int    `json:"limit"`
+}
@@ -3,4 +3,8 @@ package models
 type PatchRequest struct {
 	ID     string `json:"id"`
 	Fields map[string]interface{}
+
+	// ActorType and ActorId are who made the change, for repos keeping a history.
+	ActorType string `json:"-"`
+	ActorId   string `json:"-"`
 }
@@ -0,0 +1,95 @@
+package models
+
+import "app/pkg/money"
+
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSucceeded = "succeeded"
+	PaymentStatusFailed    = "failed"
+)
+
+// Payment status of an order, derived from its paid amount and total.
+const (
+	OrderPaymentUnpaid        = "unpaid"
+	OrderPaymentPartiallyPaid = "partially_paid"
+	OrderPaymentPaid          = "paid"
+	OrderPaymentPartRefunded  = "partially_refunded"
+	OrderPaymentRefunded      = "refunded"
+)
+
+type Payment struct {
+	Id            string      `json:"id"`
+	OrderId       string      `json:"order_id"`
+	Provider      string      `json:"provider"`
+	Status        string      `json:"status"`
+	Amount        money.Money `json:"amount"`
+	ExternalId    string      `json:"external_id"`
+	FailureReason string      `json:"failure_reason"`
+	CreatedAt     string      `json:"created_at"`
+	UpdatedAt     string      `json:"updated_at"`
+	ConfirmedAt   string      `json:"confirmed_at"`
+}
+
+type PaymentPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+// InitiatePayment starts a payment for an order. Amount defaults to the
+// outstanding balance; Token is handed to card providers.
+type InitiatePayment struct {
+	Provider string      `json:"provider"`
+	Amount   money.Money `json:"amount"`
+	Token    string      `json:"token"`
+}
+
+type CreatePayment struct {
+	OrderId  string      `json:"order_id"`
+	Provider string      `json:"provider"`
+	Amount   money.Money `json:"amount"`
+}
+
+// UpdatePaymentStatus settles a pending payment. Succeeded payments are added
+// to the paid amount of their order in the same transaction.
+type UpdatePaymentStatus struct {
+	Id            string `json:"id"`
+	Status        string `json:"status"`
+	ExternalId    string `json:"external_id"`
+	FailureReason string `json:"failure_reason"`
+}
+
+type FailPayment struct {
+	Reason string `json:"reason"`
+}
+
+type GetListPaymentRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+type GetListPaymentResponse struct {
+	Count    int        `json:"count"`
+	Payments []*Payment `json:"payments"`
+}
+
+// OrderPaymentStatus compares what was paid and refunded with the order total.
+func OrderPaymentStatus(paidAmount, refundedAmount, totalPrice money.Money) string {
+	switch {
+	case paidAmount.IsPositive() && refundedAmount.Cmp(paidAmount) >= 0:
+		return OrderPaymentRefunded
+	case refundedAmount.IsPositive():
+		return OrderPaymentPartRefunded
+	case !paidAmount.IsPositive():
+		return OrderPaymentUnpaid
+	case paidAmount.Cmp(totalPrice) < 0:
+		return OrderPaymentPartiallyPaid
+	default:
+		return OrderPaymentPaid
+	}
+}
+
+// OrderOutstanding is what is still to be paid for an order, never negative.
+func OrderOutstanding(totalPrice, paidAmount money.Money) money.Money {
+	if paidAmount.Cmp(totalPrice) >= 0 {
+		return money.Zero(totalPrice.Currency)
+	}
+	return totalPrice.Sub(paidAmount)
+}
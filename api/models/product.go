@@ -1,17 +1,21 @@
 package models
 
+import "app/pkg/money"
+
 type Product struct {
-	Id        string         `json:"id"`
-	Name      string         `json:"name"`
-	Price     float64        `json:"price"`
-	Category  ReturnCategory `json:"category"`
-	CreatedAt string         `json:"created_at"`
-	UpdatedAt string         `json:"updated_at"`
+	Id            string         `json:"id"`
+	Name          string         `json:"name"`
+	Price         money.Money    `json:"price"`
+	StockQuantity int32          `json:"stock_quantity"`
+	TaxClassId    string         `json:"tax_class_id"`
+	Category      ReturnCategory `json:"category"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
 }
 
 type ReturnProduct struct {
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
+	Name  string      `json:"name"`
+	Price money.Money `json:"price"`
 }
 
 type ProductPrimaryKey struct {
@@ -19,17 +23,19 @@ type ProductPrimaryKey struct {
 }
 
 type CreateProduct struct {
-	Name       string  `json:"name"`
-	Price      float64 `json:"price"`
-	CategoryId string  `json:"category_id"`
+	Name       string      `json:"name"`
+	Price      money.Money `json:"price"`
+	CategoryId string      `json:"category_id"`
+	TaxClassId string      `json:"tax_class_id"`
 }
 
 type UpdateProduct struct {
-	Id         string  `json:"id"`
-	Name       string  `json:"name"`
-	Price      float64 `json:"price"`
-	CategoryId string  `json:"category_id"`
-	UpdatedAt  string  `json:"updated_at"`
+	Id         string      `json:"id"`
+	Name       string      `json:"name"`
+	Price      money.Money `json:"price"`
+	CategoryId string      `json:"category_id"`
+	TaxClassId string      `json:"tax_class_id"`
+	UpdatedAt  string      `json:"updated_at"`
 }
 
 type GetListProductRequest struct {
@@ -0,0 +1,94 @@
+package models
+
+import "app/pkg/money"
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// Promotion is a discount rule. Promotions without a code apply automatically;
+// coded ones only when the order names them. Empty ProductIds and CategoryIds
+// mean the whole order; zero limits and amounts mean unlimited. Value is the
+// percentage of percentage promotions, Amount what fixed ones take off.
+type Promotion struct {
+	Id               string      `json:"id"`
+	Code             string      `json:"code"`
+	Name             string      `json:"name"`
+	DiscountType     string      `json:"discount_type"`
+	Value            float64     `json:"value"`
+	Amount           money.Money `json:"amount"`
+	MaxDiscount      money.Money `json:"max_discount"`
+	MinOrderAmount   money.Money `json:"min_order_amount"`
+	UsageLimit       int         `json:"usage_limit"`
+	PerCustomerLimit int         `json:"per_customer_limit"`
+	StartsAt         string      `json:"starts_at"`
+	EndsAt           string      `json:"ends_at"`
+	ProductIds       []string    `json:"product_ids"`
+	CategoryIds      []string    `json:"category_ids"`
+	Stackable        bool        `json:"stackable"`
+	Active           bool        `json:"active"`
+	UsedCount        int         `json:"used_count"`
+	CreatedAt        string      `json:"created_at"`
+	UpdatedAt        string      `json:"updated_at"`
+}
+
+type PromotionPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreatePromotion struct {
+	Code             string      `json:"code"`
+	Name             string      `json:"name"`
+	DiscountType     string      `json:"discount_type"`
+	Value            float64     `json:"value"`
+	Amount           money.Money `json:"amount"`
+	MaxDiscount      money.Money `json:"max_discount"`
+	MinOrderAmount   money.Money `json:"min_order_amount"`
+	UsageLimit       int         `json:"usage_limit"`
+	PerCustomerLimit int         `json:"per_customer_limit"`
+	StartsAt         string      `json:"starts_at"`
+	EndsAt           string      `json:"ends_at"`
+	ProductIds       []string    `json:"product_ids"`
+	CategoryIds      []string    `json:"category_ids"`
+	Stackable        bool        `json:"stackable"`
+	Active           bool        `json:"active"`
+}
+
+type UpdatePromotion struct {
+	Id               string      `json:"id"`
+	Code             string      `json:"code"`
+	Name             string      `json:"name"`
+	DiscountType     string      `json:"discount_type"`
+	Value            float64     `json:"value"`
+	Amount           money.Money `json:"amount"`
+	MaxDiscount      money.Money `json:"max_discount"`
+	MinOrderAmount   money.Money `json:"min_order_amount"`
+	UsageLimit       int         `json:"usage_limit"`
+	PerCustomerLimit int         `json:"per_customer_limit"`
+	StartsAt         string      `json:"starts_at"`
+	EndsAt           string      `json:"ends_at"`
+	ProductIds       []string    `json:"product_ids"`
+	CategoryIds      []string    `json:"category_ids"`
+	Stackable        bool        `json:"stackable"`
+	Active           bool        `json:"active"`
+}
+
+type GetListPromotionRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
+}
+
+type GetListPromotionResponse struct {
+	Count      int          `json:"count"`
+	Promotions []*Promotion `json:"promotions"`
+}
+
+// OrderDiscount is one promotion applied to an order.
+type OrderDiscount struct {
+	PromotionId string      `json:"promotion_id"`
+	Code        string      `json:"code"`
+	Name        string      `json:"name"`
+	Amount      money.Money `json:"amount"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "app/pkg/money"
+
+type Refund struct {
+	Id         string        `json:"id"`
+	OrderId    string        `json:"order_id"`
+	Amount     money.Money   `json:"amount"`
+	Reason     string        `json:"reason"`
+	FullRefund bool          `json:"full_refund"`
+	ActorType  string        `json:"actor_type"`
+	ActorId    string        `json:"actor_id"`
+	Items      []*RefundItem `json:"items"`
+	CreatedAt  string        `json:"created_at"`
+}
+
+type RefundItem struct {
+	OrderItemId string      `json:"order_item_id"`
+	Quantity    int32       `json:"quantity"`
+	Amount      money.Money `json:"amount"`
+}
+
+// CreateRefund refunds the listed order lines, or everything that is still
+// refundable when Items is empty.
+type CreateRefund struct {
+	OrderId   string              `json:"-"`
+	Reason    string              `json:"reason"`
+	Items     []*CreateRefundItem `json:"items"`
+	ActorType string              `json:"-"`
+	ActorId   string              `json:"-"`
+}
+
+type CreateRefundItem struct {
+	OrderItemId string `json:"order_item_id"`
+	Quantity    int32  `json:"quantity"`
+}
+
+type GetListRefundResponse struct {
+	Count   int       `json:"count"`
+	Refunds []*Refund `json:"refunds"`
+}
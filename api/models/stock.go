@@ -0,0 +1,66 @@
+package models
+
+const (
+	StockReasonRestock      = "restock"
+	StockReasonCorrection   = "correction"
+	StockReasonDamage       = "damage"
+	StockReasonLoss         = "loss"
+	StockReasonReturn       = "return"
+	StockReasonOrderReserve = "order_reserve"
+	StockReasonOrderRelease = "order_release"
+	StockReasonTransferOut  = "transfer_out"
+	StockReasonTransferIn   = "transfer_in"
+)
+
+type StockMovement struct {
+	Id           string `json:"id"`
+	ProductId    string `json:"product_id"`
+	WarehouseId  string `json:"warehouse_id"`
+	OrderId      string `json:"order_id"`
+	TransferId   string `json:"transfer_id"`
+	Quantity     int32  `json:"quantity"`
+	BalanceAfter int32  `json:"balance_after"`
+	Reason       string `json:"reason"`
+	Note         string `json:"note"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type AdjustStock struct {
+	ProductId   string `json:"product_id"`
+	WarehouseId string `json:"warehouse_id"`
+	Quantity    int32  `json:"quantity"`
+	Reason      string `json:"reason"`
+	Note        string `json:"note"`
+}
+
+type TransferStock struct {
+	ProductId       string `json:"product_id"`
+	FromWarehouseId string `json:"from_warehouse_id"`
+	ToWarehouseId   string `json:"to_warehouse_id"`
+	Quantity        int32  `json:"quantity"`
+	Note            string `json:"note"`
+}
+
+type WarehouseStock struct {
+	WarehouseId   string `json:"warehouse_id"`
+	WarehouseName string `json:"warehouse_name"`
+	Quantity      int32  `json:"quantity"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type GetProductStockResponse struct {
+	ProductId  string            `json:"product_id"`
+	Total      int32             `json:"total"`
+	Warehouses []*WarehouseStock `json:"warehouses"`
+}
+
+type GetListStockMovementRequest struct {
+	ProductId string `json:"product_id"`
+	Offset    int    `json:"offset"`
+	Limit     int    `json:"limit"`
+}
+
+type GetListStockMovementResponse struct {
+	Count     int              `json:"count"`
+	Movements []*StockMovement `json:"movements"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "app/pkg/money"
+
+// TaxClass is a named tax rate, in percent, assignable to categories and
+// products. A product's own class wins over its category's.
+type TaxClass struct {
+	Id        string  `json:"id"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type TaxClassPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateTaxClass struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
+}
+
+type UpdateTaxClass struct {
+	Id   string  `json:"id"`
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"`
+}
+
+type GetListTaxClassRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
+}
+
+type GetListTaxClassResponse struct {
+	Count      int         `json:"count"`
+	TaxClasses []*TaxClass `json:"tax_classes"`
+}
+
+// OrderTax is the tax of an order at one rate.
+type OrderTax struct {
+	Rate          float64     `json:"rate"`
+	TaxableAmount money.Money `json:"taxable_amount"`
+	Amount        money.Money `json:"amount"`
+}
@@ -4,6 +4,9 @@ type User struct {
 	Id        string `json:"id"`
 	Name      string `json:"name"`
 	Phone     string `json:"phone"`
+	Login     string `json:"login"`
+	Role      string `json:"role"`
+	CourierId string `json:"courier_id"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -17,15 +20,38 @@ type UserPrimaryKey struct {
 	Id string `json:"id"`
 }
 
+// UserCredentials is what login needs to check a password; it is never returned by the API.
+type UserCredentials struct {
+	Id           string
+	Login        string
+	PasswordHash string
+	Role         string
+	CourierId    string
+}
+
+type UserLoginKey struct {
+	Login string `json:"login"`
+}
+
+// CreateUser links a user with the courier role to the courier it acts as through CourierId.
 type CreateUser struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
+	Name      string `json:"name"`
+	Phone     string `json:"phone"`
+	Login     string `json:"login"`
+	Password  string `json:"password"`
+	Role      string `json:"role"`
+	CourierId string `json:"courier_id"`
 }
 
+// UpdateUser keeps the current password when Password is empty.
 type UpdateUser struct {
 	Id        string `json:"id"`
 	Name      string `json:"name"`
 	Phone     string `json:"phone"`
+	Login     string `json:"login"`
+	Password  string `json:"password"`
+	Role      string `json:"role"`
+	CourierId string `json:"courier_id"`
 	UpdatedAt string `json:"updated_at"`
 }
 
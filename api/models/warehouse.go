@@ -0,0 +1,35 @@
+package models
+
+type Warehouse struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type WarehousePrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateWarehouse struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type UpdateWarehouse struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type GetListWarehouseRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
+}
+
+type GetListWarehouseResponse struct {
+	Count      int          `json:"count"`
+	Warehouses []*Warehouse `json:"warehouses"`
+}
@@ -0,0 +1,109 @@
+package models
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// Webhook is a partner subscription. An empty Events list receives every event;
+// entries may be exact ("order.created") or a resource wildcard ("order.*").
+type Webhook struct {
+	Id        string   `json:"id"`
+	Url       string   `json:"url"`
+	Secret    string   `json:"secret"`
+	Events    []string `json:"events"`
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+type WebhookPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateWebhook struct {
+	Url    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+type UpdateWebhook struct {
+	Id     string   `json:"id"`
+	Url    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+type GetListWebhookRequest struct {
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+	Search string `json:"search"`
+}
+
+type GetListWebhookResponse struct {
+	Count    int        `json:"count"`
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+type WebhookDelivery struct {
+	Id             string `json:"id"`
+	WebhookId      string `json:"webhook_id"`
+	Url            string `json:"url"`
+	Secret         string `json:"-"`
+	Event          string `json:"event"`
+	Payload        string `json:"payload"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	ResponseStatus int    `json:"response_status"`
+	LastError      string `json:"last_error"`
+	NextAttemptAt  string `json:"next_attempt_at"`
+	CreatedAt      string `json:"created_at"`
+	DeliveredAt    string `json:"delivered_at"`
+}
+
+type WebhookDeliveryPrimaryKey struct {
+	Id string `json:"id"`
+}
+
+type CreateWebhookDelivery struct {
+	WebhookId string `json:"webhook_id"`
+	Event     string `json:"event"`
+	Payload   []byte `json:"payload"`
+}
+
+// ClaimWebhookDeliveries picks up to Limit due deliveries and hides them from
+// other workers for Lease seconds.
+type ClaimWebhookDeliveries struct {
+	Limit int   `json:"limit"`
+	Lease int64 `json:"lease"`
+}
+
+type DeliveredWebhook struct {
+	Id             string `json:"id"`
+	ResponseStatus int    `json:"response_status"`
+}
+
+// FailWebhookDelivery records a failed attempt; the delivery is retried after
+// RetryAfter seconds unless Dead moves it to the dead-letter list.
+type FailWebhookDelivery struct {
+	Id             string `json:"id"`
+	ResponseStatus int    `json:"response_status"`
+	Error          string `json:"error"`
+	RetryAfter     int64  `json:"retry_after"`
+	Dead           bool   `json:"dead"`
+}
+
+type GetListWebhookDeliveryRequest struct {
+	Offset    int    `json:"offset"`
+	Limit     int    `json:"limit"`
+	WebhookId string `json:"webhook_id"`
+	Status    string `json:"status"`
+}
+
+type GetListWebhookDeliveryResponse struct {
+	Count      int                `json:"count"`
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
 
 	"app/api"
+	"app/api/models"
 	"app/config"
 	"app/pkg/logger"
+	"app/pkg/notification"
+	"app/pkg/outbox"
+	"app/pkg/security"
+	"app/pkg/webhook"
+	"app/storage"
 	"app/storage/postgres"
 )
 
@@ -45,11 +54,31 @@ func main() {
 	}
 	defer store.CloseDB()
 
+	err = createAdmin(context.Background(), &cfg, store)
+	if err != nil {
+		log.Panic("Error creating admin user: ", logger.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	worker := notification.NewWorker(store, notification.NewLogSender(log), log,
+		cfg.NotificationInterval, cfg.NotificationBackoff, cfg.NotificationMaxAttempts)
+	go worker.Run(ctx)
+
+	webhookWorker := webhook.NewWorker(store, log, cfg.WebhookInterval, cfg.WebhookBackoff, cfg.WebhookMaxAttempts)
+	go webhookWorker.Run(ctx)
+
+	relay := outbox.NewRelay(store, log, cfg.OutboxInterval, cfg.OutboxMaxAttempts)
+	relay.Subscribe(webhook.Wildcard, webhook.NewPublisher(store).Handle)
+	go relay.Run(ctx)
+
 	r := gin.New()
 
 	r.Use(gin.Recovery(), gin.Logger())
 
-	api.NewApi(r, &cfg, store, log)
+	api.NewApi(r, &cfg, store, log, relay)
 
 	fmt.Println("Listening Server", cfg.ServerHost+cfg.ServerPort)
 	err = r.Run(cfg.ServerHost + cfg.ServerPort)
@@ -58,3 +87,28 @@ func main() {
 		return
 	}
 }
+
+// createAdmin creates the user from ADMIN_LOGIN and ADMIN_PASSWORD unless it
+// already exists, so there is someone who can log in on a fresh database.
+func createAdmin(ctx context.Context, cfg *config.Config, store storage.StorageI) error {
+
+	if len(cfg.AdminLogin) <= 0 || len(cfg.AdminPassword) <= 0 {
+		return nil
+	}
+
+	_, err := store.User().GetByLogin(ctx, &models.UserLoginKey{Login: cfg.AdminLogin})
+	if err == nil {
+		return nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	_, err = store.User().Create(ctx, &models.CreateUser{
+		Name:     cfg.AdminLogin,
+		Login:    cfg.AdminLogin,
+		Password: cfg.AdminPassword,
+		Role:     security.RoleAdmin,
+	})
+
+	return err
+}
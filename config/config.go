@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -17,6 +19,10 @@ const (
 	ReleaseMode = "release"
 )
 
+// MinJWTSecretLength is the shortest JWT_SECRET accepted, in bytes; tokens
+// signed with a guessable secret would pass every permission check.
+const MinJWTSecretLength = 32
+
 type Config struct {
 	Environment string // debug, test, release
 
@@ -31,6 +37,37 @@ type Config struct {
 
 	DefaultOffset int
 	DefaultLimit  int
+
+	DispatchStrategy string
+
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	AdminLogin    string
+	AdminPassword string
+
+	OTPLength         int
+	OTPTTL            time.Duration
+	OTPResendInterval time.Duration
+	OTPMaxAttempts    int
+	OTPLockout        time.Duration
+	CustomerTokenTTL  time.Duration
+
+	NotificationInterval    time.Duration
+	NotificationBackoff     time.Duration
+	NotificationMaxAttempts int
+
+	WebhookInterval    time.Duration
+	WebhookBackoff     time.Duration
+	WebhookMaxAttempts int
+
+	OutboxInterval    time.Duration
+	OutboxMaxAttempts int
+
+	TaxInclusive bool // catalog prices already contain tax
+
+	PaymentFakeCard bool // accept card payments through the fake provider, never in production
 }
 
 func Load() Config {
@@ -53,9 +90,58 @@ func Load() Config {
 	cfg.DefaultOffset = cast.ToInt(getOrReturnDefaultValue("OFFSET", 0))
 	cfg.DefaultLimit = cast.ToInt(getOrReturnDefaultValue("LIMIT", 10))
 
+	cfg.DispatchStrategy = cast.ToString(getOrReturnDefaultValue("DISPATCH_STRATEGY", "least_loaded"))
+
+	cfg.JWTSecret = cast.ToString(getOrReturnDefaultValue("JWT_SECRET", ""))
+	cfg.AccessTokenTTL = cast.ToDuration(getOrReturnDefaultValue("ACCESS_TOKEN_TTL", "15m"))
+	cfg.RefreshTokenTTL = cast.ToDuration(getOrReturnDefaultValue("REFRESH_TOKEN_TTL", "168h"))
+
+	cfg.AdminLogin = cast.ToString(getOrReturnDefaultValue("ADMIN_LOGIN", ""))
+	cfg.AdminPassword = cast.ToString(getOrReturnDefaultValue("ADMIN_PASSWORD", ""))
+
+	cfg.OTPLength = cast.ToInt(getOrReturnDefaultValue("OTP_LENGTH", 6))
+	cfg.OTPTTL = cast.ToDuration(getOrReturnDefaultValue("OTP_TTL", "5m"))
+	cfg.OTPResendInterval = cast.ToDuration(getOrReturnDefaultValue("OTP_RESEND_INTERVAL", "1m"))
+	cfg.OTPMaxAttempts = cast.ToInt(getOrReturnDefaultValue("OTP_MAX_ATTEMPTS", 5))
+	cfg.OTPLockout = cast.ToDuration(getOrReturnDefaultValue("OTP_LOCKOUT", "15m"))
+	cfg.CustomerTokenTTL = cast.ToDuration(getOrReturnDefaultValue("CUSTOMER_TOKEN_TTL", "720h"))
+
+	cfg.NotificationInterval = cast.ToDuration(getOrReturnDefaultValue("NOTIFICATION_INTERVAL", "5s"))
+	cfg.NotificationBackoff = cast.ToDuration(getOrReturnDefaultValue("NOTIFICATION_BACKOFF", "30s"))
+	cfg.NotificationMaxAttempts = cast.ToInt(getOrReturnDefaultValue("NOTIFICATION_MAX_ATTEMPTS", 8))
+
+	cfg.WebhookInterval = cast.ToDuration(getOrReturnDefaultValue("WEBHOOK_INTERVAL", "5s"))
+	cfg.WebhookBackoff = cast.ToDuration(getOrReturnDefaultValue("WEBHOOK_BACKOFF", "30s"))
+	cfg.WebhookMaxAttempts = cast.ToInt(getOrReturnDefaultValue("WEBHOOK_MAX_ATTEMPTS", 10))
+
+	cfg.OutboxInterval = cast.ToDuration(getOrReturnDefaultValue("OUTBOX_INTERVAL", "1s"))
+	cfg.OutboxMaxAttempts = cast.ToInt(getOrReturnDefaultValue("OUTBOX_MAX_ATTEMPTS", 10))
+
+	cfg.TaxInclusive = cast.ToBool(getOrReturnDefaultValue("TAX_INCLUSIVE", true))
+
+	cfg.PaymentFakeCard = cast.ToBool(getOrReturnDefaultValue("PAYMENT_FAKE_CARD", false))
+
+	if err := cfg.validate(); err != nil {
+		fmt.Println("Invalid config:", err)
+		os.Exit(1)
+	}
+
 	return cfg
 }
 
+func (cfg *Config) validate() error {
+
+	if len(cfg.JWTSecret) <= 0 {
+		return errors.New("JWT_SECRET is required")
+	}
+
+	if len(cfg.JWTSecret) < MinJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d bytes", MinJWTSecretLength)
+	}
+
+	return nil
+}
+
 func getOrReturnDefaultValue(key string, defaultValue interface{}) interface{} {
 	val, exists := os.LookupEnv(key)
 
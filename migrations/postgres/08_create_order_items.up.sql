@@ -0,0 +1,28 @@
+CREATE TABLE order_items (
+    id VARCHAR PRIMARY KEY,
+    order_id VARCHAR NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id VARCHAR REFERENCES products(id),
+    quantity INT NOT NULL,
+    price NUMERIC NOT NULL DEFAULT 0,
+    total_price NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO order_items (id, order_id, product_id, quantity, price, total_price)
+SELECT
+    id,
+    id,
+    product_id,
+    quantity,
+    COALESCE(price, 0),
+    COALESCE(total_price, 0)
+FROM orders
+WHERE product_id IS NOT NULL;
+
+DROP TRIGGER IF EXISTS add_columns_orders_tg ON orders;
+DROP FUNCTION IF EXISTS add_columns_orders();
+
+ALTER TABLE orders
+    DROP COLUMN product_id,
+    DROP COLUMN quantity,
+    DROP COLUMN price;
@@ -0,0 +1,41 @@
+ALTER TABLE orders
+    ADD COLUMN price NUMERIC DEFAULT 0,
+    ADD COLUMN quantity INT NOT NULL DEFAULT 0,
+    ADD COLUMN product_id VARCHAR REFERENCES products(id);
+
+UPDATE orders AS o
+SET
+    product_id = oi.product_id,
+    quantity = oi.quantity,
+    price = oi.price
+FROM (
+    SELECT DISTINCT ON (order_id) order_id, product_id, quantity, price
+    FROM order_items
+    ORDER BY order_id, created_at
+) AS oi
+WHERE oi.order_id = o.id;
+
+DROP TABLE IF EXISTS order_items;
+
+CREATE OR REPLACE FUNCTION add_columns_orders() RETURNS TRIGGER LANGUAGE PLPGSQL
+AS
+$$
+DECLARE 
+    product_price NUMERIC;
+BEGIN
+    SELECT 
+        price
+    INTO product_price
+    FROM products;
+
+    NEW.price = product_price;
+    NEW.total_price = NEW.quantity * product_price;
+    RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER add_columns_orders_tg
+    BEFORE INSERT OR UPDATE
+ON orders
+FOR EACH ROW 
+    EXECUTE PROCEDURE add_columns_orders();
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'new'
+    CHECK (status IN ('new', 'accepted', 'preparing', 'ready', 'picked_up', 'delivered', 'cancelled', 'returned'));
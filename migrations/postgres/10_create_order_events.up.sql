@@ -0,0 +1,15 @@
+CREATE TABLE order_events (
+    id VARCHAR PRIMARY KEY,
+    order_id VARCHAR NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    actor_type VARCHAR CHECK (actor_type IN ('user', 'courier', 'customer')),
+    actor_id VARCHAR,
+    event VARCHAR NOT NULL,
+    from_status VARCHAR,
+    to_status VARCHAR,
+    note VARCHAR,
+    created_at TIMESTAMP DEFAULT clock_timestamp()
+);
+
+CREATE INDEX order_events_order_id_idx ON order_events(order_id, created_at);
+
+CREATE RULE order_events_no_update AS ON UPDATE TO order_events DO INSTEAD NOTHING;
@@ -0,0 +1,22 @@
+ALTER TABLE order_items
+    ADD COLUMN product_name VARCHAR,
+    ADD COLUMN category_id VARCHAR,
+    ADD COLUMN category_name VARCHAR;
+
+UPDATE order_items AS oi
+SET
+    price = p.price,
+    total_price = p.price * oi.quantity,
+    product_name = p.name,
+    category_id = p.category_id,
+    category_name = c.name
+FROM products AS p
+LEFT JOIN categories AS c ON p.category_id = c.id
+WHERE oi.product_id = p.id AND oi.product_name IS NULL;
+
+UPDATE orders AS o
+SET total_price = COALESCE((
+    SELECT SUM(oi.total_price)
+    FROM order_items AS oi
+    WHERE oi.order_id = o.id
+), 0);
@@ -0,0 +1,4 @@
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS product_name,
+    DROP COLUMN IF EXISTS category_id,
+    DROP COLUMN IF EXISTS category_name;
@@ -0,0 +1,15 @@
+ALTER TABLE products
+    ADD COLUMN stock_quantity INT NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0);
+
+CREATE TABLE stock_movements (
+    id VARCHAR PRIMARY KEY,
+    product_id VARCHAR NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    order_id VARCHAR REFERENCES orders(id) ON DELETE SET NULL,
+    quantity INT NOT NULL,
+    balance_after INT NOT NULL,
+    reason VARCHAR NOT NULL,
+    note VARCHAR,
+    created_at TIMESTAMP DEFAULT clock_timestamp()
+);
+
+CREATE INDEX stock_movements_product_id_idx ON stock_movements(product_id, created_at);
@@ -0,0 +1,39 @@
+CREATE TABLE warehouses (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    address VARCHAR,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE warehouse_stocks (
+    warehouse_id VARCHAR NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    product_id VARCHAR NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP,
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+ALTER TABLE stock_movements
+    ADD COLUMN warehouse_id VARCHAR REFERENCES warehouses(id) ON DELETE CASCADE,
+    ADD COLUMN transfer_id VARCHAR;
+
+ALTER TABLE orders
+    ADD COLUMN warehouse_id VARCHAR REFERENCES warehouses(id);
+
+-- Stock that existed before warehouses is moved into a default warehouse.
+INSERT INTO warehouses (id, name, updated_at)
+VALUES ('5c1d7a42-8f3e-4b6a-9d2c-1e0f3a4b5c6d', 'Main warehouse', NOW());
+
+INSERT INTO warehouse_stocks (warehouse_id, product_id, quantity, updated_at)
+SELECT '5c1d7a42-8f3e-4b6a-9d2c-1e0f3a4b5c6d', id, stock_quantity, NOW()
+FROM products
+WHERE stock_quantity > 0;
+
+UPDATE stock_movements SET warehouse_id = '5c1d7a42-8f3e-4b6a-9d2c-1e0f3a4b5c6d';
+
+UPDATE orders AS o
+SET warehouse_id = '5c1d7a42-8f3e-4b6a-9d2c-1e0f3a4b5c6d'
+WHERE EXISTS (SELECT 1 FROM stock_movements AS sm WHERE sm.order_id = o.id);
+
+ALTER TABLE products DROP COLUMN stock_quantity;
@@ -0,0 +1,20 @@
+ALTER TABLE products
+    ADD COLUMN stock_quantity INT NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0);
+
+UPDATE products AS p
+SET stock_quantity = ws.quantity
+FROM (
+    SELECT product_id, SUM(quantity) AS quantity
+    FROM warehouse_stocks
+    GROUP BY product_id
+) AS ws
+WHERE ws.product_id = p.id;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS warehouse_id;
+
+ALTER TABLE stock_movements
+    DROP COLUMN IF EXISTS warehouse_id,
+    DROP COLUMN IF EXISTS transfer_id;
+
+DROP TABLE IF EXISTS warehouse_stocks;
+DROP TABLE IF EXISTS warehouses;
@@ -0,0 +1,3 @@
+ALTER TABLE couriers
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'offline'
+    CHECK (status IN ('online', 'offline', 'busy'));
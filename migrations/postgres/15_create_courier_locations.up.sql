@@ -0,0 +1,14 @@
+ALTER TABLE couriers
+    ADD COLUMN last_lat DOUBLE PRECISION,
+    ADD COLUMN last_lng DOUBLE PRECISION,
+    ADD COLUMN last_location_at TIMESTAMP;
+
+CREATE TABLE courier_locations (
+    id VARCHAR PRIMARY KEY,
+    courier_id VARCHAR NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMP DEFAULT clock_timestamp()
+);
+
+CREATE INDEX courier_locations_courier_id_idx ON courier_locations(courier_id, recorded_at DESC);
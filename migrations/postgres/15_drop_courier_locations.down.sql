@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS courier_locations;
+
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS last_lat,
+    DROP COLUMN IF EXISTS last_lng,
+    DROP COLUMN IF EXISTS last_location_at;
@@ -0,0 +1,19 @@
+CREATE TABLE delivery_zones (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    polygon JSONB NOT NULL,
+    delivery_fee NUMERIC NOT NULL DEFAULT 0,
+    min_order_amount NUMERIC NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+ALTER TABLE orders
+    ADD COLUMN delivery_lat DOUBLE PRECISION,
+    ADD COLUMN delivery_lng DOUBLE PRECISION,
+    ADD COLUMN delivery_zone_id VARCHAR REFERENCES delivery_zones(id) ON DELETE SET NULL,
+    ADD COLUMN subtotal NUMERIC NOT NULL DEFAULT 0,
+    ADD COLUMN delivery_fee NUMERIC NOT NULL DEFAULT 0;
+
+UPDATE orders SET subtotal = COALESCE(total_price, 0);
@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_lat,
+    DROP COLUMN IF EXISTS delivery_lng,
+    DROP COLUMN IF EXISTS delivery_zone_id,
+    DROP COLUMN IF EXISTS subtotal,
+    DROP COLUMN IF EXISTS delivery_fee;
+
+DROP TABLE IF EXISTS delivery_zones;
@@ -0,0 +1,23 @@
+CREATE TABLE customer_addresses (
+    id VARCHAR PRIMARY KEY,
+    customer_id VARCHAR NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    label VARCHAR,
+    street VARCHAR NOT NULL,
+    apartment VARCHAR,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    notes VARCHAR,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX customer_addresses_customer_id_idx ON customer_addresses(customer_id);
+CREATE UNIQUE INDEX customer_addresses_default_idx ON customer_addresses(customer_id) WHERE is_default;
+
+ALTER TABLE orders
+    ADD COLUMN delivery_address_id VARCHAR REFERENCES customer_addresses(id) ON DELETE SET NULL,
+    ADD COLUMN delivery_label VARCHAR,
+    ADD COLUMN delivery_street VARCHAR,
+    ADD COLUMN delivery_apartment VARCHAR,
+    ADD COLUMN delivery_notes VARCHAR;
@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_address_id,
+    DROP COLUMN IF EXISTS delivery_label,
+    DROP COLUMN IF EXISTS delivery_street,
+    DROP COLUMN IF EXISTS delivery_apartment,
+    DROP COLUMN IF EXISTS delivery_notes;
+
+DROP TABLE IF EXISTS customer_addresses;
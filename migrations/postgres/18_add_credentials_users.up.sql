@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN login VARCHAR UNIQUE,
+    ADD COLUMN password_hash VARCHAR;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS login,
+    DROP COLUMN IF EXISTS password_hash;
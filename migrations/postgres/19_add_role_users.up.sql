@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR NOT NULL DEFAULT 'analyst',
+    ADD COLUMN courier_id VARCHAR REFERENCES couriers(id) ON DELETE SET NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE customer_otps (
+    phone VARCHAR PRIMARY KEY,
+    code_hash VARCHAR NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX customers_phone_idx ON customers(phone);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS customers_phone_idx;
+
+DROP TABLE IF EXISTS customer_otps;
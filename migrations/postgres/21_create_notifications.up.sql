@@ -0,0 +1,20 @@
+CREATE TABLE notifications (
+    id VARCHAR PRIMARY KEY,
+    channel VARCHAR NOT NULL,
+    recipient VARCHAR NOT NULL,
+    event VARCHAR NOT NULL,
+    language VARCHAR NOT NULL,
+    subject VARCHAR,
+    body TEXT NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX notifications_pending_idx ON notifications(next_attempt_at) WHERE status = 'pending';
+
+ALTER TABLE customers
+    ADD COLUMN language VARCHAR NOT NULL DEFAULT 'en';
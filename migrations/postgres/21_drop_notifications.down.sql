@@ -0,0 +1,4 @@
+ALTER TABLE customers
+    DROP COLUMN IF EXISTS language;
+
+DROP TABLE IF EXISTS notifications;
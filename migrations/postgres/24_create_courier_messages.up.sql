@@ -0,0 +1,10 @@
+CREATE TABLE courier_messages (
+    id VARCHAR PRIMARY KEY,
+    courier_id VARCHAR NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    type VARCHAR NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX courier_messages_pending_idx ON courier_messages(courier_id, created_at) WHERE delivered_at IS NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE payments (
+    id VARCHAR PRIMARY KEY,
+    order_id VARCHAR NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    amount NUMERIC NOT NULL,
+    external_id VARCHAR,
+    failure_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    confirmed_at TIMESTAMP
+);
+
+CREATE INDEX payments_order_idx ON payments(order_id, created_at);
+
+ALTER TABLE orders ADD COLUMN paid_amount NUMERIC NOT NULL DEFAULT 0;
@@ -0,0 +1,25 @@
+CREATE TABLE refunds (
+    id VARCHAR PRIMARY KEY,
+    order_id VARCHAR NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    amount NUMERIC NOT NULL,
+    reason VARCHAR NOT NULL,
+    full_refund BOOLEAN NOT NULL DEFAULT FALSE,
+    actor_type VARCHAR CHECK (actor_type IN ('user', 'courier', 'customer')),
+    actor_id VARCHAR,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX refunds_order_idx ON refunds(order_id, created_at);
+
+CREATE TABLE refund_items (
+    refund_id VARCHAR NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    order_item_id VARCHAR NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount NUMERIC NOT NULL,
+    PRIMARY KEY (refund_id, order_item_id)
+);
+
+ALTER TABLE orders ADD COLUMN refunded_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN refunded_quantity INT NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD CONSTRAINT order_items_refunded_quantity_check
+    CHECK (refunded_quantity >= 0 AND refunded_quantity <= quantity);
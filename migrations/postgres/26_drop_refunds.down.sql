@@ -0,0 +1,5 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS refunded_quantity;
+ALTER TABLE orders DROP COLUMN IF EXISTS refunded_amount;
+
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
@@ -0,0 +1,35 @@
+CREATE TABLE promotions (
+    id VARCHAR PRIMARY KEY,
+    code VARCHAR UNIQUE,
+    name VARCHAR NOT NULL,
+    discount_type VARCHAR NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    value NUMERIC NOT NULL CHECK (value > 0),
+    max_discount NUMERIC NOT NULL DEFAULT 0,
+    min_order_amount NUMERIC NOT NULL DEFAULT 0,
+    usage_limit INT NOT NULL DEFAULT 0,
+    per_customer_limit INT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    product_ids JSONB NOT NULL DEFAULT '[]',
+    category_ids JSONB NOT NULL DEFAULT '[]',
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE TABLE order_discounts (
+    order_id VARCHAR NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id VARCHAR NOT NULL REFERENCES promotions(id),
+    customer_id VARCHAR,
+    code VARCHAR,
+    name VARCHAR NOT NULL,
+    amount NUMERIC NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (order_id, promotion_id)
+);
+
+CREATE INDEX order_discounts_promotion_idx ON order_discounts(promotion_id, customer_id);
+
+ALTER TABLE orders ADD COLUMN promo_code VARCHAR;
+ALTER TABLE orders ADD COLUMN discount_amount NUMERIC NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+CREATE TABLE tax_classes (
+    id VARCHAR PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate >= 0 AND rate < 100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+ALTER TABLE categories ADD COLUMN tax_class_id VARCHAR REFERENCES tax_classes(id);
+ALTER TABLE products ADD COLUMN tax_class_id VARCHAR REFERENCES tax_classes(id);
+
+ALTER TABLE order_items ADD COLUMN tax_rate NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN taxable_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN tax_amount NUMERIC NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE orders ADD COLUMN tax_amount NUMERIC NOT NULL DEFAULT 0;
+
+UPDATE order_items SET taxable_amount = total_price;
@@ -0,0 +1,11 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_inclusive;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS taxable_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_rate;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS tax_class_id;
+
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,56 @@
+-- Money is stored as BIGINT minor units next to a currency code. Existing
+-- amounts are all in UZS, whose minor unit is a hundredth.
+
+ALTER TABLE products
+    ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100),
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'UZS';
+
+ALTER TABLE delivery_zones
+    ALTER COLUMN delivery_fee TYPE BIGINT USING ROUND(delivery_fee * 100),
+    ALTER COLUMN min_order_amount TYPE BIGINT USING ROUND(min_order_amount * 100),
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'UZS';
+
+ALTER TABLE orders
+    ALTER COLUMN subtotal TYPE BIGINT USING ROUND(subtotal * 100),
+    ALTER COLUMN delivery_fee TYPE BIGINT USING ROUND(delivery_fee * 100),
+    ALTER COLUMN discount_amount TYPE BIGINT USING ROUND(discount_amount * 100),
+    ALTER COLUMN tax_amount TYPE BIGINT USING ROUND(tax_amount * 100),
+    ALTER COLUMN total_price TYPE BIGINT USING ROUND(COALESCE(total_price, 0) * 100),
+    ALTER COLUMN total_price SET DEFAULT 0,
+    ALTER COLUMN paid_amount TYPE BIGINT USING ROUND(paid_amount * 100),
+    ALTER COLUMN refunded_amount TYPE BIGINT USING ROUND(refunded_amount * 100),
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'UZS';
+
+ALTER TABLE order_items
+    ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100),
+    ALTER COLUMN total_price TYPE BIGINT USING ROUND(total_price * 100),
+    ALTER COLUMN taxable_amount TYPE BIGINT USING ROUND(taxable_amount * 100),
+    ALTER COLUMN tax_amount TYPE BIGINT USING ROUND(tax_amount * 100);
+
+ALTER TABLE order_discounts
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100);
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100);
+
+ALTER TABLE refunds
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100);
+
+ALTER TABLE refund_items
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100);
+
+-- value keeps the percentage of percentage promotions; fixed ones move their
+-- amount to its own money column.
+ALTER TABLE promotions
+    DROP CONSTRAINT IF EXISTS promotions_value_check,
+    ADD COLUMN amount BIGINT NOT NULL DEFAULT 0,
+    ALTER COLUMN max_discount TYPE BIGINT USING ROUND(max_discount * 100),
+    ALTER COLUMN min_order_amount TYPE BIGINT USING ROUND(min_order_amount * 100),
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'UZS';
+
+UPDATE promotions
+SET amount = ROUND(value * 100), value = 0
+WHERE discount_type = 'fixed';
+
+ALTER TABLE promotions
+    ADD CONSTRAINT promotions_value_check CHECK (value >= 0 AND value <= 100);
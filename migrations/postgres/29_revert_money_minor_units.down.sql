@@ -0,0 +1,49 @@
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_value_check;
+
+UPDATE promotions
+SET value = amount / 100.0
+WHERE discount_type = 'fixed';
+
+ALTER TABLE promotions
+    DROP COLUMN currency,
+    ALTER COLUMN min_order_amount TYPE NUMERIC USING min_order_amount / 100.0,
+    ALTER COLUMN max_discount TYPE NUMERIC USING max_discount / 100.0,
+    DROP COLUMN amount,
+    ADD CONSTRAINT promotions_value_check CHECK (value > 0);
+
+ALTER TABLE refund_items
+    ALTER COLUMN amount TYPE NUMERIC USING amount / 100.0;
+
+ALTER TABLE refunds
+    ALTER COLUMN amount TYPE NUMERIC USING amount / 100.0;
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE NUMERIC USING amount / 100.0;
+
+ALTER TABLE order_discounts
+    ALTER COLUMN amount TYPE NUMERIC USING amount / 100.0;
+
+ALTER TABLE order_items
+    ALTER COLUMN tax_amount TYPE NUMERIC USING tax_amount / 100.0,
+    ALTER COLUMN taxable_amount TYPE NUMERIC USING taxable_amount / 100.0,
+    ALTER COLUMN total_price TYPE NUMERIC USING total_price / 100.0,
+    ALTER COLUMN price TYPE NUMERIC USING price / 100.0;
+
+ALTER TABLE orders
+    DROP COLUMN currency,
+    ALTER COLUMN refunded_amount TYPE NUMERIC USING refunded_amount / 100.0,
+    ALTER COLUMN paid_amount TYPE NUMERIC USING paid_amount / 100.0,
+    ALTER COLUMN total_price TYPE NUMERIC USING total_price / 100.0,
+    ALTER COLUMN tax_amount TYPE NUMERIC USING tax_amount / 100.0,
+    ALTER COLUMN discount_amount TYPE NUMERIC USING discount_amount / 100.0,
+    ALTER COLUMN delivery_fee TYPE NUMERIC USING delivery_fee / 100.0,
+    ALTER COLUMN subtotal TYPE NUMERIC USING subtotal / 100.0;
+
+ALTER TABLE delivery_zones
+    DROP COLUMN currency,
+    ALTER COLUMN min_order_amount TYPE NUMERIC USING min_order_amount / 100.0,
+    ALTER COLUMN delivery_fee TYPE NUMERIC USING delivery_fee / 100.0;
+
+ALTER TABLE products
+    DROP COLUMN currency,
+    ALTER COLUMN price TYPE NUMERIC USING price / 100.0;
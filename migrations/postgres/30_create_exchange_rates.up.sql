@@ -0,0 +1,21 @@
+CREATE TABLE exchange_rates (
+    id VARCHAR PRIMARY KEY,
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    CHECK (base_currency <> quote_currency),
+    UNIQUE (base_currency, quote_currency, effective_from)
+);
+
+CREATE TABLE order_exchange_rates (
+    order_id VARCHAR NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC NOT NULL,
+    effective_from TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (order_id, base_currency, quote_currency)
+);
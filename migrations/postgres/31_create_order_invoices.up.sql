@@ -0,0 +1,14 @@
+CREATE TABLE invoice_counter (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_number BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO invoice_counter (id, last_number) VALUES (TRUE, 0);
+
+-- Invoices outlive their order on purpose: deleting an order must not leave a
+-- hole in the numbering.
+CREATE TABLE order_invoices (
+    number BIGINT PRIMARY KEY,
+    order_id VARCHAR NOT NULL UNIQUE,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_invoices;
+DROP TABLE IF EXISTS invoice_counter;
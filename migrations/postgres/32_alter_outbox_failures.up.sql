@@ -0,0 +1,7 @@
+ALTER TABLE outbox
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_error TEXT,
+    ADD COLUMN failed_at TIMESTAMP;
+
+DROP INDEX IF EXISTS outbox_unpublished_idx;
+CREATE INDEX outbox_unpublished_idx ON outbox(id) WHERE published_at IS NULL AND failed_at IS NULL;
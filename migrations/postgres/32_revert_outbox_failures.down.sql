@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS outbox_unpublished_idx;
+CREATE INDEX outbox_unpublished_idx ON outbox(id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS failed_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS attempts;
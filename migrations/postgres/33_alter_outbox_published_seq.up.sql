@@ -0,0 +1,12 @@
+-- published_seq numbers events in the order the relay hands them out, which
+-- unlike id is never behind a late commit; it is the resume cursor of event streams.
+CREATE SEQUENCE outbox_published_seq;
+
+ALTER TABLE outbox ADD COLUMN published_seq BIGINT UNIQUE;
+
+-- events already published keep their id as cursor, so Last-Event-IDs handed
+-- out before stay valid
+UPDATE outbox SET published_seq = id WHERE published_at IS NOT NULL;
+SELECT setval('outbox_published_seq', COALESCE(MAX(id), 0) + 1, false) FROM outbox;
+
+CREATE INDEX outbox_aggregate_published_seq_idx ON outbox(aggregate_type, published_seq) WHERE published_seq IS NOT NULL;
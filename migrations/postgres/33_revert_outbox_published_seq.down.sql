@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_aggregate_published_seq_idx;
+ALTER TABLE outbox DROP COLUMN IF EXISTS published_seq;
+DROP SEQUENCE IF EXISTS outbox_published_seq;
@@ -0,0 +1,80 @@
+package courierhub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 4096
+	sendBuffer     = 32
+)
+
+// client is one courier connection. Writes go through send so that only the
+// write loop touches the socket.
+type client struct {
+	courierId string
+	conn      *websocket.Conn
+	send      chan interface{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(courierId string, conn *websocket.Conn) *client {
+	return &client{
+		courierId: courierId,
+		conn:      conn,
+		send:      make(chan interface{}, sendBuffer),
+		done:      make(chan struct{}),
+	}
+}
+
+// push queues message for writing; a client that cannot keep up is closed,
+// its pending messages stay queued for the next connection.
+func (c *client) push(message interface{}) bool {
+	select {
+	case c.send <- message:
+		return true
+	case <-c.done:
+		return false
+	default:
+		c.close()
+		return false
+	}
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *client) writeLoop() {
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	defer c.close()
+
+	for {
+		select {
+		case message := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
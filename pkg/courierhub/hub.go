@@ -0,0 +1,208 @@
+package courierhub
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v4"
+)
+
+// HandlerFunc processes a courier request; the returned error is reported back
+// to the courier in the result message.
+type HandlerFunc func(ctx context.Context, courierId string, message *Incoming) error
+
+// Hub tracks the connected couriers and routes messages to them. Every message
+// is stored before it is pushed and stays pending until the courier sends an
+// ack, so a courier that is offline, or drops before acknowledging, gets it
+// again on the next connection. Delivery is at-least-once: couriers should
+// ignore message ids they already saw, and assignments for orders they hold.
+type Hub struct {
+	store  storage.StorageI
+	logger logger.LoggerI
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+func NewHub(store storage.StorageI, log logger.LoggerI) *Hub {
+	return &Hub{
+		store:   store,
+		logger:  log,
+		clients: make(map[string]*client),
+	}
+}
+
+// Serve runs the connection of a courier until it closes. A newer connection of
+// the same courier replaces the older one.
+func (h *Hub) Serve(ctx context.Context, courierId string, conn *websocket.Conn, handle HandlerFunc) {
+
+	c := newClient(courierId, conn)
+
+	h.mu.Lock()
+	if old, ok := h.clients[courierId]; ok {
+		old.close()
+	}
+	h.clients[courierId] = c
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		if h.clients[courierId] == c {
+			delete(h.clients, courierId)
+		}
+		h.mu.Unlock()
+		c.close()
+	}()
+
+	go c.writeLoop()
+
+	messages, err := h.store.Courier().GetPendingMessages(ctx, &models.CourierPrimaryKey{Id: courierId})
+	if err != nil {
+		h.logger.Error("get pending courier messages", logger.String("courier_id", courierId), logger.Error(err))
+		return
+	}
+
+	for _, message := range messages {
+		if !c.push(outgoing(message)) {
+			return
+		}
+	}
+
+	h.readLoop(ctx, c, handle)
+}
+
+func (h *Hub) readLoop(ctx context.Context, c *client, handle HandlerFunc) {
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var message Incoming
+
+		err := c.conn.ReadJSON(&message)
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				h.logger.Warn("read courier socket", logger.String("courier_id", c.courierId), logger.Error(err))
+			}
+			return
+		}
+
+		if message.Type == MessageAck {
+			_, err = h.store.Courier().AckMessage(ctx, &models.CourierMessagePrimaryKey{Id: message.Id, CourierId: c.courierId})
+			if err != nil {
+				h.logger.Error("ack courier message", logger.String("id", message.Id), logger.Error(err))
+			}
+			continue
+		}
+
+		result := &Outgoing{Type: MessageResult, Ref: message.Ref, Ok: true}
+
+		err = handle(ctx, c.courierId, &message)
+		if err != nil {
+			result.Ok, result.Error = false, err.Error()
+		}
+
+		if !c.push(result) {
+			return
+		}
+	}
+}
+
+// Send stores a message for a courier and pushes it right away when the
+// courier is connected.
+func (h *Hub) Send(ctx context.Context, courierId, messageType string, data interface{}) error {
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	message, err := h.store.Courier().CreateMessage(ctx, &models.CreateCourierMessage{
+		CourierId: courierId,
+		Type:      messageType,
+		Payload:   payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	c, ok := h.clients[courierId]
+	h.mu.Unlock()
+
+	if ok {
+		c.push(outgoing(message))
+	}
+
+	return nil
+}
+
+// Online reports whether a courier currently has an open connection.
+func (h *Hub) Online(courierId string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, ok := h.clients[courierId]
+	return ok
+}
+
+// Handle is an outbox handler for order.courier_assigned; it sends the full
+// order to the assigned courier. When the order or the courier has been
+// deleted since, the event can never be delivered and is dropped instead of
+// being retried.
+func (h *Hub) Handle(ctx context.Context, event *models.OutboxEvent) error {
+
+	var row struct {
+		CourierId string `json:"courier_id"`
+	}
+
+	err := json.Unmarshal([]byte(event.Payload), &row)
+	if err != nil {
+		return err
+	}
+
+	if len(row.CourierId) <= 0 {
+		return nil
+	}
+
+	order, err := h.store.Order().GetByID(ctx, &models.OrderPrimaryKey{Id: event.AggregateId})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.logger.Warn("drop assignment of deleted order", logger.String("order_id", event.AggregateId))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// The courier may have changed again before the relay got here; only the
+	// courier the event was written for gets this assignment.
+	if order.Courier.Id != row.CourierId {
+		return nil
+	}
+
+	err = h.Send(ctx, row.CourierId, MessageAssignment, order)
+	if errors.Is(err, storage.ErrCourierNotFound) {
+		h.logger.Warn("drop assignment of deleted courier", logger.String("order_id", event.AggregateId), logger.String("courier_id", row.CourierId))
+		return nil
+	}
+
+	return err
+}
+
+func outgoing(message *models.CourierMessage) *Outgoing {
+	return &Outgoing{
+		Id:        message.Id,
+		Type:      message.Type,
+		Data:      json.RawMessage(message.Payload),
+		CreatedAt: message.CreatedAt,
+	}
+}
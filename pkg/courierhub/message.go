@@ -0,0 +1,41 @@
+package courierhub
+
+import "encoding/json"
+
+// Message types sent to couriers.
+const (
+	MessageAssignment = "assignment"
+	MessageResult     = "result"
+)
+
+// Message types couriers send.
+const (
+	MessageAck       = "ack"
+	MessagePickedUp  = "picked_up"
+	MessageDelivered = "delivered"
+	MessageLocation  = "location"
+)
+
+// Outgoing is a message to a courier. Queued messages carry the id the courier
+// acknowledges; results carry the Ref of the request they answer.
+type Outgoing struct {
+	Id        string          `json:"id,omitempty"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	CreatedAt string          `json:"created_at,omitempty"`
+	Ref       string          `json:"ref,omitempty"`
+	Ok        bool            `json:"ok,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// Incoming is a message from a courier. Ref is an optional client chosen id
+// echoed back in the result; Id is the message being acknowledged.
+type Incoming struct {
+	Type    string  `json:"type"`
+	Ref     string  `json:"ref"`
+	Id      string  `json:"id"`
+	OrderId string  `json:"order_id"`
+	Note    string  `json:"note"`
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+}
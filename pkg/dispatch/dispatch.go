@@ -0,0 +1,108 @@
+package dispatch
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/pkg/notification"
+	"app/storage"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNoCourierAvailable = errors.New("no courier available")
+
+type Dispatcher struct {
+	store    storage.StorageI
+	strategy Strategy
+	notifier *notification.Notifier
+	logger   logger.LoggerI
+}
+
+func NewDispatcher(store storage.StorageI, strategy Strategy, notifier *notification.Notifier, logger logger.LoggerI) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		strategy: strategy,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// Dispatch assigns a courier to the order unless it already has one and returns the courier id.
+func (d *Dispatcher) Dispatch(ctx context.Context, orderId string) (string, error) {
+
+	order, err := d.store.Order().GetByID(ctx, &models.OrderPrimaryKey{Id: orderId})
+	if err != nil {
+		return "", err
+	}
+
+	if len(order.Courier.Id) > 0 {
+		return order.Courier.Id, nil
+	}
+
+	candidates, err := d.store.Courier().GetAvailable(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(candidates) <= 0 {
+		return "", ErrNoCourierAvailable
+	}
+
+	courier, err := d.strategy.Pick(ctx, order, candidates)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.store.Order().AssignCourier(ctx, &models.AssignCourier{
+		OrderId:   orderId,
+		CourierId: courier.Id,
+		Note:      fmt.Sprintf("dispatched by %s strategy: courier %s", d.strategy.Name(), courier.Id),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	d.logger.Info("order dispatched",
+		logger.String("order_id", orderId),
+		logger.String("courier_id", courier.Id),
+		logger.String("strategy", d.strategy.Name()),
+	)
+
+	err = d.notifier.Notify(ctx, notification.EventCourierAssigned, orderId)
+	if err != nil {
+		d.logger.Warn("notify courier assigned", logger.String("order_id", orderId), logger.Error(err))
+	}
+
+	return courier.Id, nil
+}
+
+// Redispatch assigns couriers to ready orders that are still waiting for one,
+// oldest first, until no courier is available, and returns how many it
+// assigned. It is run whenever a courier may have become available, so orders
+// that became ready while every courier was offline or busy get picked up.
+func (d *Dispatcher) Redispatch(ctx context.Context) (int, error) {
+
+	ids, err := d.store.Order().GetUndispatched(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var dispatched int
+
+	for _, id := range ids {
+
+		_, err = d.Dispatch(ctx, id)
+		if errors.Is(err, ErrNoCourierAvailable) {
+			break
+		} else if errors.Is(err, storage.ErrOrderAlreadyAssigned) {
+			continue
+		} else if err != nil {
+			return dispatched, err
+		}
+
+		dispatched++
+	}
+
+	return dispatched, nil
+}
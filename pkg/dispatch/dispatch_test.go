@@ -0,0 +1,146 @@
+package dispatch
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/pkg/notification"
+	"app/storage"
+	"context"
+	"testing"
+)
+
+type fakeStore struct {
+	storage.StorageI
+	orders   *fakeOrderRepo
+	couriers *fakeCourierRepo
+}
+
+func (s *fakeStore) Order() storage.OrderRepoI {
+	return s.orders
+}
+
+func (s *fakeStore) Courier() storage.CourierRepoI {
+	return s.couriers
+}
+
+// fakeOrderRepo keeps the courier of every order; raced orders are taken by
+// someone else between being listed and being assigned.
+type fakeOrderRepo struct {
+	storage.OrderRepoI
+
+	ids      []string
+	courier  map[string]string
+	raced    map[string]bool
+	couriers *fakeCourierRepo
+}
+
+func (r *fakeOrderRepo) GetByID(ctx context.Context, req *models.OrderPrimaryKey) (*models.Order, error) {
+	order := &models.Order{Id: req.Id}
+	order.Courier.Id = r.courier[req.Id]
+	return order, nil
+}
+
+func (r *fakeOrderRepo) GetUndispatched(ctx context.Context) ([]string, error) {
+
+	var ids []string
+
+	for _, id := range r.ids {
+		if len(r.courier[id]) <= 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *fakeOrderRepo) AssignCourier(ctx context.Context, req *models.AssignCourier) error {
+
+	if r.raced[req.OrderId] {
+		return storage.ErrOrderAlreadyAssigned
+	}
+
+	r.courier[req.OrderId] = req.CourierId
+	r.couriers.take(req.CourierId)
+
+	return nil
+}
+
+// fakeCourierRepo offers every free courier; a courier is gone once given an order.
+type fakeCourierRepo struct {
+	storage.CourierRepoI
+
+	free []string
+}
+
+func (r *fakeCourierRepo) GetAvailable(ctx context.Context) ([]*models.CourierLoad, error) {
+
+	var couriers []*models.CourierLoad
+
+	for _, id := range r.free {
+		couriers = append(couriers, &models.CourierLoad{Id: id})
+	}
+
+	return couriers, nil
+}
+
+func (r *fakeCourierRepo) take(id string) {
+	for i, free := range r.free {
+		if free == id {
+			r.free = append(r.free[:i], r.free[i+1:]...)
+			return
+		}
+	}
+}
+
+func TestRedispatch(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		free       []string
+		raced      map[string]bool
+		want       int
+		unassigned []string
+	}{
+		{"no courier", nil, nil, 0, []string{"o1", "o2", "o3"}},
+		{"fewer couriers than orders", []string{"c1", "c2"}, nil, 2, []string{"o3"}},
+		{"enough couriers", []string{"c1", "c2", "c3", "c4"}, nil, 3, nil},
+		{"raced orders are skipped", []string{"c1", "c2"}, map[string]bool{"o1": true}, 2, []string{"o1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var (
+				couriers = &fakeCourierRepo{free: tt.free}
+				orders   = &fakeOrderRepo{
+					ids:      []string{"o1", "o2", "o3"},
+					courier:  make(map[string]string),
+					raced:    tt.raced,
+					couriers: couriers,
+				}
+				store      = &fakeStore{orders: orders, couriers: couriers}
+				dispatcher = NewDispatcher(store, LeastLoaded(), notification.NewNotifier(store), logger.NewLogger("test", logger.LevelFatal))
+			)
+
+			dispatched, err := dispatcher.Redispatch(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if dispatched != tt.want {
+				t.Fatalf("Redispatch() = %d, want %d", dispatched, tt.want)
+			}
+
+			unassigned, _ := orders.GetUndispatched(context.Background())
+			if len(unassigned) != len(tt.unassigned) {
+				t.Fatalf("unassigned orders %v, want %v", unassigned, tt.unassigned)
+			}
+
+			for i := range unassigned {
+				if unassigned[i] != tt.unassigned[i] {
+					t.Fatalf("unassigned orders %v, want %v", unassigned, tt.unassigned)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package dispatch
+
+import (
+	"app/api/models"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	StrategyLeastLoaded = "least_loaded"
+	StrategyRoundRobin  = "round_robin"
+)
+
+// Strategy picks the courier that should deliver an order out of the available candidates.
+// Candidates are never empty when Pick is called.
+type Strategy interface {
+	Name() string
+	Pick(ctx context.Context, order *models.Order, candidates []*models.CourierLoad) (*models.CourierLoad, error)
+}
+
+// NewStrategy returns the strategy registered under name.
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case StrategyLeastLoaded:
+		return LeastLoaded(), nil
+	case StrategyRoundRobin:
+		return RoundRobin(), nil
+	}
+
+	return nil, fmt.Errorf("unknown dispatch strategy: %s", name)
+}
+
+type leastLoaded struct{}
+
+// LeastLoaded picks the courier carrying the fewest active orders.
+func LeastLoaded() Strategy {
+	return &leastLoaded{}
+}
+
+func (s *leastLoaded) Name() string {
+	return StrategyLeastLoaded
+}
+
+func (s *leastLoaded) Pick(ctx context.Context, order *models.Order, candidates []*models.CourierLoad) (*models.CourierLoad, error) {
+	picked := candidates[0]
+
+	for _, candidate := range candidates[1:] {
+		if candidate.ActiveOrders < picked.ActiveOrders {
+			picked = candidate
+		}
+	}
+
+	return picked, nil
+}
+
+type roundRobin struct {
+	mu   sync.Mutex
+	last string
+}
+
+// RoundRobin cycles through the available couriers ordered by id.
+func RoundRobin() Strategy {
+	return &roundRobin{}
+}
+
+func (s *roundRobin) Name() string {
+	return StrategyRoundRobin
+}
+
+func (s *roundRobin) Pick(ctx context.Context, order *models.Order, candidates []*models.CourierLoad) (*models.CourierLoad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sorted := make([]*models.CourierLoad, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Id < sorted[j].Id })
+
+	picked := sorted[0]
+
+	for _, candidate := range sorted {
+		if candidate.Id > s.last {
+			picked = candidate
+			break
+		}
+	}
+
+	s.last = picked.Id
+
+	return picked, nil
+}
@@ -0,0 +1,18 @@
+package helper
+
+// PointInPolygon reports whether the point lies inside the polygon given as [lat, lng] vertices.
+// It uses ray casting, which is accurate enough for city-sized zones.
+func PointInPolygon(lat, lng float64, polygon [][2]float64) bool {
+	var inside bool
+
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		latI, lngI := polygon[i][0], polygon[i][1]
+		latJ, lngJ := polygon[j][0], polygon[j][1]
+
+		if (lngI > lng) != (lngJ > lng) && lat < (latJ-latI)*(lng-lngI)/(lngJ-lngI)+latI {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
@@ -113,3 +113,13 @@ func NewNullBool(s bool) sql.NullBool {
 		Valid: true,
 	}
 }
+
+func NewNullInt(n int) sql.NullInt64 {
+	if n == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{
+		Int64: int64(n),
+		Valid: true,
+	}
+}
@@ -0,0 +1,38 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidRate = errors.New("exchange rate must be a positive decimal")
+
+// ParseRate reads an exchange rate written as a decimal, e.g. "12650.75". Rates
+// stay exact rationals so conversions never pick up float error.
+func ParseRate(value string) (*big.Rat, error) {
+
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+
+	return rate, nil
+}
+
+// Convert turns m into currency, where rate is the price of one major unit of
+// m's currency in major units of currency. The result is rounded half away
+// from zero to the minor unit of currency.
+func (m Money) Convert(currency string, rate *big.Rat) Money {
+
+	currency = strings.ToUpper(currency)
+	if currency == m.Currency {
+		return m
+	}
+
+	r := new(big.Rat).SetInt64(m.Amount)
+	r.Mul(r, rate)
+	r.Mul(r, new(big.Rat).SetFrac(pow10(Exponent(currency)), pow10(Exponent(m.Currency))))
+
+	return Money{Amount: roundRat(r), Currency: currency}
+}
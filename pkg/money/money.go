@@ -0,0 +1,280 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency is what amounts are in when no currency is given.
+const DefaultCurrency = "UZS"
+
+var (
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+	ErrInvalidAmount    = errors.New("invalid money amount")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// exponents lists the currencies whose minor unit is not a hundredth.
+var exponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+// Money is an amount in the minor units of its currency, so 12.50 USD is
+// {1250, "USD"}. Arithmetic on two values of different currencies panics, as
+// that is a bug in the caller: amounts that do not all come from the same
+// currency are converted or checked with CheckCurrency first. The zero Money
+// has no currency and takes on the currency of whatever it is combined with.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns amount minor units of currency; an empty currency means
+// DefaultCurrency.
+func New(amount int64, currency string) Money {
+	if len(currency) <= 0 {
+		currency = DefaultCurrency
+	}
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Zero is nothing in currency.
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Parse reads a decimal such as "12.5" or "-3" in major units. More fraction
+// digits than the currency has are an error rather than silently rounded.
+func Parse(value, currency string) (Money, error) {
+
+	m := Zero(currency)
+	exp := Exponent(m.Currency)
+
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return m, ErrInvalidAmount
+	}
+
+	r.Mul(r, new(big.Rat).SetInt(pow10(exp)))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return m, ErrInvalidAmount
+	}
+
+	m.Amount = r.Num().Int64()
+
+	return m, nil
+}
+
+// CheckCurrency returns ErrCurrencyMismatch unless amounts can be combined,
+// that is every amount with a currency has the same one.
+func CheckCurrency(amounts ...Money) error {
+
+	var currency string
+
+	for _, amount := range amounts {
+		switch {
+		case len(amount.Currency) <= 0:
+		case len(currency) <= 0:
+			currency = amount.Currency
+		case amount.Currency != currency:
+			return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, currency, amount.Currency)
+		}
+	}
+
+	return nil
+}
+
+// Exponent is the number of minor-unit digits of currency.
+func Exponent(currency string) int {
+	if exp, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// IsValidCurrency reports whether code looks like an ISO 4217 code.
+func IsValidCurrency(code string) bool {
+
+	if len(code) != 3 {
+		return false
+	}
+
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currency(other)}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currency(other)}
+}
+
+// Mul multiplies by a whole quantity.
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) int {
+	m.currency(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Min returns the smaller of m and other.
+func (m Money) Min(other Money) Money {
+	if m.Cmp(other) > 0 {
+		return other
+	}
+	return m
+}
+
+// MulRat multiplies by num/den, rounding half away from zero. The product is
+// computed exactly, so the result never depends on float precision.
+func (m Money) MulRat(num, den int64) Money {
+
+	if den == 0 {
+		panic("money: division by zero")
+	}
+
+	r := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num)),
+		big.NewInt(den),
+	)
+
+	return Money{Amount: roundRat(r), Currency: m.Currency}
+}
+
+// Percent is percent of m, rounded half away from zero. The rate is taken to
+// hundredths of a percent, which is the precision rates are stored with.
+func (m Money) Percent(percent float64) Money {
+	return m.MulRat(BasisPoints(percent), 10000)
+}
+
+// BasisPoints turns a percentage into hundredths of a percent.
+func BasisPoints(percent float64) int64 {
+	return int64(math.Round(percent * 100))
+}
+
+// Allocate splits m in proportion to weights. Every part is rounded down and
+// the remainder goes to the last part, so the parts always add up to m.
+func (m Money) Allocate(weights []int64) []Money {
+
+	var (
+		parts     = make([]Money, len(weights))
+		total     int64
+		allocated int64
+	)
+
+	for _, weight := range weights {
+		total += weight
+	}
+
+	for i, weight := range weights {
+
+		var share int64
+		switch {
+		case total <= 0:
+		case i == len(weights)-1:
+			share = m.Amount - allocated
+		default:
+			r := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(weight))
+			share = r.Quo(r, big.NewInt(total)).Int64()
+		}
+		allocated += share
+
+		parts[i] = Money{Amount: share, Currency: m.Currency}
+	}
+
+	return parts
+}
+
+// Major is the amount in major units as a decimal string, e.g. "12.50".
+func (m Money) Major() string {
+
+	exp := Exponent(m.Currency)
+	if exp == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+
+	var (
+		sign   = ""
+		amount = m.Amount
+		unit   = pow10(exp).Int64()
+	)
+
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, exp, amount%unit)
+}
+
+func (m Money) String() string {
+	return m.Major() + " " + m.Currency
+}
+
+// currency is the currency of an operation on m and other.
+func (m Money) currency(other Money) string {
+	switch {
+	case len(m.Currency) <= 0:
+		return other.Currency
+	case len(other.Currency) <= 0 || m.Currency == other.Currency:
+		return m.Currency
+	}
+	panic(fmt.Sprintf("money: currency mismatch %s and %s", m.Currency, other.Currency))
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+// roundRat rounds r half away from zero.
+func roundRat(r *big.Rat) int64 {
+
+	var (
+		num  = new(big.Int).Abs(r.Num())
+		den  = r.Denom()
+		quo  = new(big.Int)
+		rem  = new(big.Int)
+		half = new(big.Int)
+	)
+
+	quo.QuoRem(num, den, rem)
+	if half.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+
+	return quo.Int64()
+}
@@ -0,0 +1,222 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		value    string
+		currency string
+		want     Money
+		err      error
+	}{
+		{"12.5", "usd", Money{1250, "USD"}, nil},
+		{"-3", "USD", Money{-300, "USD"}, nil},
+		{" 7 ", "USD", Money{700, "USD"}, nil},
+		{"12.5", "", Money{1250, DefaultCurrency}, nil},
+		{"1500", "JPY", Money{1500, "JPY"}, nil},
+		{"1.234", "KWD", Money{1234, "KWD"}, nil},
+		{"12.345", "USD", Money{}, ErrInvalidAmount},
+		{"1.5", "JPY", Money{}, ErrInvalidAmount},
+		{"abc", "USD", Money{}, ErrInvalidAmount},
+		{"", "USD", Money{}, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+
+			got, err := Parse(tt.value, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.err)
+			}
+
+			if err == nil && got != tt.want {
+				t.Fatalf("Parse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{Money{1250, "USD"}, "12.50 USD"},
+		{Money{-5, "USD"}, "-0.05 USD"},
+		{Money{-1250, "USD"}, "-12.50 USD"},
+		{Money{0, "UZS"}, "0.00 UZS"},
+		{Money{1500, "JPY"}, "1500 JPY"},
+		{Money{1234, "KWD"}, "1.234 KWD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.money.String(); got != tt.want {
+				t.Fatalf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRat(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		want     int64
+	}{
+		{"exact", 300, 1, 3, 100},
+		{"rounds down", 10, 1, 3, 3},
+		{"rounds up", 2, 1, 3, 1},
+		{"half rounds away from zero", 10, 1, 4, 3},
+		{"negative half rounds away from zero", -10, 1, 4, -3},
+		{"half of one", 1, 1, 2, 1},
+		{"negative rounds", -2, 1, 3, -1},
+		{"scales up", 7, 3, 2, 11},
+		{"does not overflow in between", 9000000000000000000, 3, 9, 3000000000000000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.amount, "USD").MulRat(tt.num, tt.den)
+			if got != New(tt.want, "USD") {
+				t.Fatalf("MulRat() = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		amount  int64
+		percent float64
+		want    int64
+	}{
+		{"whole", 10000, 12, 1200},
+		{"half rounds away from zero", 10, 5, 1},
+		{"fractional rate", 1999, 12.5, 250},
+		{"rate to hundredths of a percent", 100000, 12.345, 12350},
+		{"negative", -1999, 12.5, -250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.amount, "USD").Percent(tt.percent)
+			if got != New(tt.want, "USD") {
+				t.Fatalf("Percent() = %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{"even", 90, []int64{1, 1, 1}, []int64{30, 30, 30}},
+		{"remainder to the last part", 100, []int64{1, 1, 1}, []int64{33, 33, 34}},
+		{"proportional", 10, []int64{3, 7}, []int64{3, 7}},
+		{"too little to split", 1, []int64{1, 1}, []int64{0, 1}},
+		{"uneven weights", 1000, []int64{3333, 3333, 3334}, []int64{333, 333, 334}},
+		{"zero weight", 5, []int64{0, 1}, []int64{0, 5}},
+		{"no weight at all", 100, []int64{0, 0}, []int64{0, 0}},
+		{"negative", -100, []int64{1, 1, 1}, []int64{-33, -33, -34}},
+		{"no parts", 7, nil, []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			parts := New(tt.amount, "USD").Allocate(tt.weights)
+			if len(parts) != len(tt.want) {
+				t.Fatalf("Allocate() gave %d parts, want %d", len(parts), len(tt.want))
+			}
+
+			for i, part := range parts {
+				if part != New(tt.want[i], "USD") {
+					t.Errorf("part %d = %d, want %d", i, part.Amount, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		money    Money
+		currency string
+		rate     string
+		want     Money
+	}{
+		{"to a two-digit currency", Money{100, "USD"}, "UZS", "12650.75", Money{1265075, "UZS"}},
+		{"to a zero-digit currency", Money{1234, "USD"}, "jpy", "150.5", Money{1857, "JPY"}},
+		{"from a zero-digit currency", Money{1000, "JPY"}, "USD", "0.0067", Money{670, "USD"}},
+		{"to a three-digit currency", Money{100, "USD"}, "KWD", "0.3075", Money{308, "KWD"}},
+		{"same currency", Money{100, "USD"}, "USD", "2", Money{100, "USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			rate, err := ParseRate(tt.rate)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := tt.money.Convert(tt.currency, rate); got != tt.want {
+				t.Fatalf("Convert() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCurrency(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		amounts []Money
+		err     error
+	}{
+		{"nothing", nil, nil},
+		{"same", []Money{New(1, "USD"), New(2, "USD")}, nil},
+		{"without currency", []Money{{}, New(1, "USD"), {}}, nil},
+		{"mismatch", []Money{New(1, "USD"), {}, New(2, "UZS")}, ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckCurrency(tt.amounts...); !errors.Is(err, tt.err) {
+				t.Fatalf("CheckCurrency() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+
+	if got := (Money{}).Add(New(150, "USD")).Sub(New(50, "USD")); got != New(100, "USD") {
+		t.Fatalf("Add() = %s, want 1.00 USD", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Add() of different currencies did not panic")
+		}
+	}()
+
+	New(1, "USD").Add(New(1, "UZS"))
+}
@@ -0,0 +1,81 @@
+package notification
+
+import (
+	"app/api/models"
+	"app/storage"
+	"context"
+)
+
+// Notifier turns order events into messages and queues them in the outbox; the
+// Worker delivers them.
+type Notifier struct {
+	store storage.StorageI
+}
+
+func NewNotifier(store storage.StorageI) *Notifier {
+	return &Notifier{
+		store: store,
+	}
+}
+
+// Notify queues the messages of event for the order's customer, and for the
+// courier when one is assigned.
+func (n *Notifier) Notify(ctx context.Context, event, orderId string) error {
+
+	order, err := n.store.Order().GetByID(ctx, &models.OrderPrimaryKey{Id: orderId})
+	if err != nil {
+		return err
+	}
+
+	data := &TemplateData{
+		OrderId:       order.Id,
+		OrderName:     order.Name,
+		TotalPrice:    order.TotalPrice,
+		CustomerName:  order.Customer.Name,
+		CustomerPhone: order.Customer.Phone,
+		CourierName:   order.Courier.Name,
+		CourierPhone:  order.Courier.Phone,
+		Street:        order.DeliveryAddress.Street,
+		Apartment:     order.DeliveryAddress.Apartment,
+	}
+
+	if len(order.Customer.Id) > 0 && len(order.Customer.Phone) > 0 {
+		customer, err := n.store.Customer().GetByID(ctx, &models.CustomerPrimaryKey{Id: order.Customer.Id})
+		if err != nil {
+			return err
+		}
+
+		err = n.enqueue(ctx, event, customer.Language, customer.Phone, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if event == EventCourierAssigned && len(order.Courier.Phone) > 0 {
+		err = n.enqueue(ctx, EventCourierNewOrder, DefaultLanguage, order.Courier.Phone, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *Notifier) enqueue(ctx context.Context, event, language, phone string, data *TemplateData) error {
+
+	subject, body, err := Render(event, language, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.store.Notification().Create(ctx, &models.CreateNotification{
+		Channel:   ChannelSMS,
+		Recipient: phone,
+		Event:     event,
+		Language:  language,
+		Subject:   subject,
+		Body:      body,
+	})
+
+	return err
+}
@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"app/pkg/logger"
+	"context"
+)
+
+const (
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Message is a rendered notification ready to be delivered over Channel.
+type Message struct {
+	Channel   string
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Sender delivers messages through an SMS, email or push gateway.
+type Sender interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+type logSender struct {
+	logger logger.LoggerI
+}
+
+// NewLogSender returns a Sender that writes every message to the log instead
+// of delivering it, for local runs.
+func NewLogSender(log logger.LoggerI) Sender {
+	return &logSender{
+		logger: log,
+	}
+}
+
+func (s *logSender) Send(ctx context.Context, message *Message) error {
+	s.logger.Info("notification",
+		logger.String("channel", message.Channel),
+		logger.String("recipient", message.Recipient),
+		logger.String("subject", message.Subject),
+		logger.String("body", message.Body),
+	)
+	return nil
+}
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"app/pkg/money"
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+const (
+	EventOrderAccepted   = "order_accepted"
+	EventCourierAssigned = "courier_assigned"
+	EventOrderDelivered  = "order_delivered"
+
+	// EventCourierNewOrder is what the courier gets when an order is assigned to them.
+	EventCourierNewOrder = "courier_new_order"
+)
+
+const DefaultLanguage = "en"
+
+// TemplateData is what the templates can refer to.
+type TemplateData struct {
+	OrderId       string
+	OrderName     string
+	TotalPrice    money.Money
+	CustomerName  string
+	CustomerPhone string
+	CourierName   string
+	CourierPhone  string
+	Street        string
+	Apartment     string
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func newTemplate(subject, body string) *messageTemplate {
+	return &messageTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+// templates holds the text of every event per language.
+var templates = map[string]map[string]*messageTemplate{
+	EventOrderAccepted: {
+		"en": newTemplate("Order accepted", "Your order {{.OrderName}} has been accepted and is being prepared."),
+		"ru": newTemplate("Заказ принят", "Ваш заказ {{.OrderName}} принят и готовится."),
+		"uz": newTemplate("Buyurtma qabul qilindi", "{{.OrderName}} buyurtmangiz qabul qilindi va tayyorlanmoqda."),
+	},
+	EventCourierAssigned: {
+		"en": newTemplate("Courier assigned", "{{.CourierName}} ({{.CourierPhone}}) will deliver your order {{.OrderName}}."),
+		"ru": newTemplate("Курьер назначен", "{{.CourierName}} ({{.CourierPhone}}) доставит ваш заказ {{.OrderName}}."),
+		"uz": newTemplate("Kuryer tayinlandi", "{{.OrderName}} buyurtmangizni {{.CourierName}} ({{.CourierPhone}}) yetkazib beradi."),
+	},
+	EventOrderDelivered: {
+		"en": newTemplate("Order delivered", "Your order {{.OrderName}} has been delivered. Enjoy!"),
+		"ru": newTemplate("Заказ доставлен", "Ваш заказ {{.OrderName}} доставлен. Приятного аппетита!"),
+		"uz": newTemplate("Buyurtma yetkazildi", "{{.OrderName}} buyurtmangiz yetkazildi. Yoqimli ishtaha!"),
+	},
+	EventCourierNewOrder: {
+		"en": newTemplate("New order", "New order {{.OrderName}} for {{.CustomerName}} {{.CustomerPhone}}, {{.Street}} {{.Apartment}}."),
+	},
+}
+
+// IsSupportedLanguage reports whether every customer event has a template in language.
+func IsSupportedLanguage(language string) bool {
+	for event, byLanguage := range templates {
+		if event == EventCourierNewOrder {
+			continue
+		}
+		if _, ok := byLanguage[language]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Render fills the template of event in language, falling back to DefaultLanguage.
+func Render(event, language string, data *TemplateData) (subject, body string, err error) {
+
+	byLanguage, ok := templates[event]
+	if !ok {
+		return "", "", fmt.Errorf("no template for event %q", event)
+	}
+
+	tmpl, ok := byLanguage[language]
+	if !ok {
+		tmpl = byLanguage[DefaultLanguage]
+	}
+
+	var buf bytes.Buffer
+
+	err = tmpl.subject.Execute(&buf, data)
+	if err != nil {
+		return "", "", err
+	}
+	subject = buf.String()
+
+	buf.Reset()
+
+	err = tmpl.body.Execute(&buf, data)
+	if err != nil {
+		return "", "", err
+	}
+	body = buf.String()
+
+	return subject, body, nil
+}
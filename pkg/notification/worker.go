@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/storage"
+	"context"
+	"time"
+)
+
+const (
+	workerBatchSize = 20
+	workerLease     = 5 * time.Minute
+	maxBackoff      = time.Hour
+)
+
+// Worker delivers queued notifications, retrying failed sends with exponential
+// backoff until maxAttempts is reached.
+type Worker struct {
+	store       storage.StorageI
+	sender      Sender
+	logger      logger.LoggerI
+	interval    time.Duration
+	backoff     time.Duration
+	maxAttempts int
+}
+
+func NewWorker(store storage.StorageI, sender Sender, log logger.LoggerI, interval, backoff time.Duration, maxAttempts int) *Worker {
+	return &Worker{
+		store:       store,
+		sender:      sender,
+		logger:      log,
+		interval:    interval,
+		backoff:     backoff,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run polls the outbox every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context) {
+
+	notifications, err := w.store.Notification().ClaimPending(ctx, &models.ClaimNotifications{
+		Limit: workerBatchSize,
+		Lease: int64(workerLease.Seconds()),
+	})
+	if err != nil {
+		w.logger.Error("claim notifications", logger.Error(err))
+		return
+	}
+
+	for _, notification := range notifications {
+
+		err = w.sender.Send(ctx, &Message{
+			Channel:   notification.Channel,
+			Recipient: notification.Recipient,
+			Subject:   notification.Subject,
+			Body:      notification.Body,
+		})
+		if err == nil {
+			err = w.store.Notification().MarkSent(ctx, &models.NotificationPrimaryKey{Id: notification.Id})
+			if err != nil {
+				w.logger.Error("mark notification sent", logger.String("id", notification.Id), logger.Error(err))
+			}
+			continue
+		}
+
+		attempts := notification.Attempts + 1
+
+		w.logger.Warn("send notification",
+			logger.String("id", notification.Id),
+			logger.Int("attempt", attempts),
+			logger.Error(err),
+		)
+
+		err = w.store.Notification().MarkFailed(ctx, &models.FailNotification{
+			Id:         notification.Id,
+			Error:      err.Error(),
+			RetryAfter: int64(w.retryAfter(attempts).Seconds()),
+			Dead:       attempts >= w.maxAttempts,
+		})
+		if err != nil {
+			w.logger.Error("mark notification failed", logger.String("id", notification.Id), logger.Error(err))
+		}
+	}
+}
+
+// retryAfter doubles the backoff with every attempt, up to maxBackoff.
+func (w *Worker) retryAfter(attempts int) time.Duration {
+
+	delay := w.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"app/api/models"
+	"context"
+	"sync"
+)
+
+const subscriberBuffer = 64
+
+// Broker fans relayed events out to short-lived listeners such as open event
+// streams. Register Broker.Handle with the relay and call Listen per client.
+type Broker struct {
+	mu        sync.Mutex
+	listeners map[chan *models.OutboxEvent]string
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		listeners: make(map[chan *models.OutboxEvent]string),
+	}
+}
+
+// Listen returns a channel of events matching pattern and a function that
+// stops listening. A listener that falls behind by more than its buffer has its
+// channel closed instead of slowing down the relay; clients are expected to
+// reconnect and resume from the last event they saw.
+func (b *Broker) Listen(pattern string) (<-chan *models.OutboxEvent, func()) {
+
+	ch := make(chan *models.OutboxEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	b.listeners[ch] = pattern
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.listeners[ch]; ok {
+			delete(b.listeners, ch)
+			close(ch)
+		}
+	}
+}
+
+// Handle is a relay handler; it never fails, so slow listeners cannot hold up
+// other subscribers.
+func (b *Broker) Handle(ctx context.Context, event *models.OutboxEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch, pattern := range b.listeners {
+
+		if !Matches(pattern, event.EventType) {
+			continue
+		}
+
+		select {
+		case ch <- event:
+		default:
+			delete(b.listeners, ch)
+			close(ch)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package outbox
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/storage"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const relayBatchSize = 100
+
+// Handler consumes one outbox event. Returning an error makes the relay retry
+// the same event on the next tick, so handlers must be idempotent; an event
+// that keeps failing is parked after the relay's maximum attempts.
+type Handler func(ctx context.Context, event *models.OutboxEvent) error
+
+type subscription struct {
+	pattern string
+	handler Handler
+}
+
+// Relay publishes the events written by the storage repos to in-process
+// subscribers, one event at a time in outbox order. An event is marked
+// published only after every matching subscriber accepted it, which gives
+// at-least-once delivery: after a failure or a restart, subscribers that
+// already handled the event see it again. An event that fails maxAttempts
+// times is parked with its last error and the relay continues with the next
+// one, so a single bad event cannot hold up the outbox; subscribers that
+// accepted it keep it, the others never get it.
+//
+// "Outbox order" is the order of event ids; see GetPending of the outbox repo for how
+// it can differ from commit order across aggregates. Run a single relay per
+// database, otherwise ordering between instances is not guaranteed.
+type Relay struct {
+	store       storage.StorageI
+	logger      logger.LoggerI
+	interval    time.Duration
+	maxAttempts int
+
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+func NewRelay(store storage.StorageI, log logger.LoggerI, interval time.Duration, maxAttempts int) *Relay {
+	return &Relay{
+		store:       store,
+		logger:      log,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Subscribe registers handler for events matching pattern: an exact event type
+// ("order.created"), every event of an aggregate ("order.*") or everything ("*").
+func (r *Relay) Subscribe(pattern string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions = append(r.subscriptions, subscription{
+		pattern: pattern,
+		handler: handler,
+	})
+}
+
+// Run polls the outbox every interval until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process drains the outbox; it stops at the first event a subscriber rejects
+// so that later events are not delivered ahead of it, unless that event has
+// used up its attempts and gets parked.
+func (r *Relay) process(ctx context.Context) {
+
+	for {
+		events, err := r.store.Outbox().GetPending(ctx, &models.GetPendingOutboxEvents{Limit: relayBatchSize})
+		if err != nil {
+			r.logger.Error("get pending outbox events", logger.Error(err))
+			return
+		}
+
+		for _, event := range events {
+
+			if event.Seq <= 0 {
+				event.Seq, err = r.store.Outbox().AssignSeq(ctx, &models.OutboxEventPrimaryKey{Id: event.Id})
+				if err != nil {
+					r.logger.Error("assign outbox event seq", logger.Any("id", event.Id), logger.Error(err))
+					return
+				}
+			}
+
+			err = r.dispatch(ctx, event)
+			if err != nil {
+				if !r.fail(ctx, event, err) {
+					return
+				}
+				continue
+			}
+
+			err = r.store.Outbox().MarkPublished(ctx, &models.OutboxEventPrimaryKey{Id: event.Id})
+			if err != nil {
+				r.logger.Error("mark outbox event published", logger.Any("id", event.Id), logger.Error(err))
+				return
+			}
+		}
+
+		if len(events) < relayBatchSize {
+			return
+		}
+	}
+}
+
+// fail records a failed attempt and reports whether the event was parked.
+func (r *Relay) fail(ctx context.Context, event *models.OutboxEvent, cause error) bool {
+
+	attempts := event.Attempts + 1
+	dead := attempts >= r.maxAttempts
+
+	if dead {
+		r.logger.Error("park outbox event",
+			logger.Any("id", event.Id),
+			logger.String("event", event.EventType),
+			logger.Int("attempt", attempts),
+			logger.Error(cause),
+		)
+	} else {
+		r.logger.Warn("relay outbox event",
+			logger.Any("id", event.Id),
+			logger.String("event", event.EventType),
+			logger.Int("attempt", attempts),
+			logger.Error(cause),
+		)
+	}
+
+	err := r.store.Outbox().MarkFailed(ctx, &models.FailOutboxEvent{
+		Id:    event.Id,
+		Error: cause.Error(),
+		Dead:  dead,
+	})
+	if err != nil {
+		r.logger.Error("mark outbox event failed", logger.Any("id", event.Id), logger.Error(err))
+		return false
+	}
+
+	return dead
+}
+
+func (r *Relay) dispatch(ctx context.Context, event *models.OutboxEvent) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, subscription := range r.subscriptions {
+
+		if !Matches(subscription.pattern, event.EventType) {
+			continue
+		}
+
+		err := subscription.handler(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether eventType is covered by pattern.
+func Matches(pattern, eventType string) bool {
+
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+
+	return strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*"))
+}
@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/storage"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeStore struct {
+	storage.StorageI
+	outbox *fakeOutboxRepo
+}
+
+func (s *fakeStore) Outbox() storage.OutboxRepoI {
+	return s.outbox
+}
+
+// fakeOutboxRepo keeps events in memory in id order.
+type fakeOutboxRepo struct {
+	storage.OutboxRepoI
+
+	seq       int64
+	events    []*models.OutboxEvent
+	published map[int64]bool
+	parked    map[int64]bool
+}
+
+func (r *fakeOutboxRepo) GetPending(ctx context.Context, req *models.GetPendingOutboxEvents) ([]*models.OutboxEvent, error) {
+
+	var pending []*models.OutboxEvent
+
+	for _, event := range r.events {
+		if !r.published[event.Id] && !r.parked[event.Id] && len(pending) < req.Limit {
+			copied := *event
+			pending = append(pending, &copied)
+		}
+	}
+
+	return pending, nil
+}
+
+func (r *fakeOutboxRepo) AssignSeq(ctx context.Context, req *models.OutboxEventPrimaryKey) (int64, error) {
+
+	for _, event := range r.events {
+		if event.Id == req.Id && event.Seq <= 0 {
+			r.seq++
+			event.Seq = r.seq
+		}
+		if event.Id == req.Id {
+			return event.Seq, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (r *fakeOutboxRepo) MarkPublished(ctx context.Context, req *models.OutboxEventPrimaryKey) error {
+	r.published[req.Id] = true
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(ctx context.Context, req *models.FailOutboxEvent) error {
+
+	for _, event := range r.events {
+		if event.Id == req.Id {
+			event.Attempts++
+		}
+	}
+
+	if req.Dead {
+		r.parked[req.Id] = true
+	}
+
+	return nil
+}
+
+func TestRelayParksPoisonEvent(t *testing.T) {
+
+	repo := &fakeOutboxRepo{
+		events: []*models.OutboxEvent{
+			{Id: 1, EventType: "order.created"},
+			{Id: 2, EventType: "order.updated"},
+		},
+		published: make(map[int64]bool),
+		parked:    make(map[int64]bool),
+	}
+
+	relay := NewRelay(&fakeStore{outbox: repo}, logger.NewLogger("test", logger.LevelFatal), time.Second, 3)
+
+	var handled []int64
+
+	relay.Subscribe("order.*", func(ctx context.Context, event *models.OutboxEvent) error {
+		if event.Id == 1 {
+			return errors.New("cannot handle")
+		}
+		handled = append(handled, event.Id)
+		return nil
+	})
+
+	// the first two rounds retry event 1 and must not deliver event 2 ahead of it
+	for round := 1; round <= 2; round++ {
+		relay.process(context.Background())
+
+		if len(handled) != 0 || repo.parked[1] {
+			t.Fatalf("round %d: handled %v, parked %v", round, handled, repo.parked)
+		}
+	}
+
+	relay.process(context.Background())
+
+	if !repo.parked[1] || repo.published[1] {
+		t.Fatalf("event 1: parked %v, published %v, want parked only", repo.parked[1], repo.published[1])
+	}
+
+	if len(handled) != 1 || handled[0] != 2 || !repo.published[2] {
+		t.Fatalf("handled %v, want event 2 published after event 1 was parked", handled)
+	}
+
+	// event 1 keeps the seq of its first attempt
+	if repo.events[0].Seq != 1 || repo.events[1].Seq != 2 {
+		t.Fatalf("seqs %d, %d, want 1, 2", repo.events[0].Seq, repo.events[1].Seq)
+	}
+}
+
+func TestMatches(t *testing.T) {
+
+	tests := []struct {
+		pattern, eventType string
+		want               bool
+	}{
+		{"*", "order.created", true},
+		{"order.created", "order.created", true},
+		{"order.*", "order.status_changed", true},
+		{"order.*", "orders.created", false},
+		{"product.*", "order.created", false},
+		{"order.created", "order.updated", false},
+	}
+
+	for _, tt := range tests {
+		if got := Matches(tt.pattern, tt.eventType); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.pattern, tt.eventType, got, tt.want)
+		}
+	}
+}
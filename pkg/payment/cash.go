@@ -0,0 +1,26 @@
+package payment
+
+import (
+	"app/api/models"
+	"context"
+)
+
+// CashProvider is cash on delivery: the payment stays pending until whoever
+// collected the money confirms it.
+type CashProvider struct{}
+
+func NewCashProvider() *CashProvider {
+	return &CashProvider{}
+}
+
+func (p *CashProvider) Initiate(ctx context.Context, charge *Charge) (*Result, error) {
+	return &Result{Status: models.PaymentStatusPending}, nil
+}
+
+func (p *CashProvider) Confirm(ctx context.Context, externalId string) (*Result, error) {
+	return &Result{ExternalId: externalId, Status: models.PaymentStatusSucceeded}, nil
+}
+
+func (p *CashProvider) Cancel(ctx context.Context, externalId string) error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"app/api/models"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Tokens the fake card provider understands; any other token is authorized.
+const (
+	FakeTokenDecline          = "tok_decline"
+	FakeTokenInsufficientFund = "tok_insufficient_funds"
+)
+
+// FakeCardProvider stands in for a card gateway in development and tests. It
+// authorizes on Initiate, captures on Confirm and keeps everything in memory.
+type FakeCardProvider struct {
+	mu       sync.Mutex
+	payments map[string]string
+}
+
+func NewFakeCardProvider() *FakeCardProvider {
+	return &FakeCardProvider{
+		payments: make(map[string]string),
+	}
+}
+
+func (p *FakeCardProvider) Initiate(ctx context.Context, charge *Charge) (*Result, error) {
+
+	externalId := "fake_" + uuid.New().String()
+
+	switch charge.Token {
+	case FakeTokenDecline:
+		return &Result{ExternalId: externalId, Status: models.PaymentStatusFailed, FailureReason: "card declined"}, nil
+	case FakeTokenInsufficientFund:
+		return &Result{ExternalId: externalId, Status: models.PaymentStatusFailed, FailureReason: "insufficient funds"}, nil
+	}
+
+	p.mu.Lock()
+	p.payments[externalId] = models.PaymentStatusPending
+	p.mu.Unlock()
+
+	return &Result{ExternalId: externalId, Status: models.PaymentStatusPending}, nil
+}
+
+func (p *FakeCardProvider) Confirm(ctx context.Context, externalId string) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.payments[externalId] != models.PaymentStatusPending {
+		return nil, errors.New("fake card: no authorized payment " + externalId)
+	}
+
+	p.payments[externalId] = models.PaymentStatusSucceeded
+
+	return &Result{ExternalId: externalId, Status: models.PaymentStatusSucceeded}, nil
+}
+
+func (p *FakeCardProvider) Cancel(ctx context.Context, externalId string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.payments, externalId)
+
+	return nil
+}
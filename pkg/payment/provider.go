@@ -0,0 +1,88 @@
+package payment
+
+import (
+	"app/pkg/money"
+	"context"
+	"errors"
+	"sort"
+)
+
+const (
+	ProviderCash = "cash"
+	ProviderCard = "card"
+)
+
+var ErrUnknownProvider = errors.New("unknown payment provider")
+
+// Charge is what a provider is asked to collect.
+type Charge struct {
+	PaymentId string
+	OrderId   string
+	Amount    money.Money
+	Token     string
+}
+
+// Result is a provider's answer. Status is one of the models.PaymentStatus
+// values; a pending result is settled later through Confirm or Cancel.
+type Result struct {
+	ExternalId    string
+	Status        string
+	FailureReason string
+}
+
+// Provider collects money for a payment. Initiate starts it; Confirm captures
+// a pending payment; Cancel gives up on one that will not be completed.
+type Provider interface {
+	Initiate(ctx context.Context, charge *Charge) (*Result, error)
+	Confirm(ctx context.Context, externalId string) (*Result, error)
+	Cancel(ctx context.Context, externalId string) error
+}
+
+// Registry looks providers up by the name stored on the payment.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+	}
+}
+
+func (r *Registry) Register(name string, provider Provider) {
+	r.providers[name] = provider
+}
+
+func (r *Registry) Get(name string) (Provider, error) {
+
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return provider, nil
+}
+
+// Names returns the registered provider names, sorted.
+func (r *Registry) Names() []string {
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// DefaultRegistry registers cash on delivery, the only provider that is safe
+// everywhere. The fake card provider authorizes without moving money, so it is
+// registered on top of this only where that is wanted.
+func DefaultRegistry() *Registry {
+
+	registry := NewRegistry()
+	registry.Register(ProviderCash, NewCashProvider())
+
+	return registry
+}
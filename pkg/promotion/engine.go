@@ -0,0 +1,213 @@
+package promotion
+
+import (
+	"app/api/models"
+	"app/pkg/money"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrNotApplicable = errors.New("promo code does not apply to this order")
+
+// Line is an order line as far as discounts are concerned.
+type Line struct {
+	ProductId  string
+	CategoryId string
+	Amount     money.Money
+}
+
+type Cart struct {
+	Subtotal money.Money
+	Lines    []Line
+}
+
+// Discount is what promotion takes off cart, rounded half away from zero to
+// the minor unit. Scoped promotions only discount the lines of their products
+// and categories. A promotion with amounts in another currency than the cart
+// does not apply.
+func Discount(promotion *models.Promotion, cart *Cart) money.Money {
+
+	none := money.Zero(cart.Subtotal.Currency)
+
+	if !sameCurrency(promotion, cart.Subtotal.Currency) {
+		return none
+	}
+
+	if promotion.MinOrderAmount.IsPositive() && cart.Subtotal.Cmp(promotion.MinOrderAmount) < 0 {
+		return none
+	}
+
+	base := none
+	for _, line := range cart.Lines {
+		if inScope(promotion, line) {
+			base = base.Add(line.Amount)
+		}
+	}
+
+	if !base.IsPositive() {
+		return none
+	}
+
+	discount := none
+	switch promotion.DiscountType {
+	case models.DiscountTypePercentage:
+		discount = base.Percent(promotion.Value)
+	case models.DiscountTypeFixed:
+		discount = promotion.Amount
+	}
+
+	if promotion.MaxDiscount.IsPositive() {
+		discount = discount.Min(promotion.MaxDiscount)
+	}
+
+	return discount.Min(base)
+}
+
+// Apply picks the promotions an order gets. Stackable promotions combine with
+// each other; a non-stackable one only applies alone. Of the allowed
+// combinations the one with the largest discount wins, but when code is set
+// the combination must include that promotion or ErrNotApplicable is returned.
+// Cart lines in another currency than the subtotal are an
+// money.ErrCurrencyMismatch.
+func Apply(cart *Cart, promotions []*models.Promotion, code string) ([]*models.OrderDiscount, error) {
+
+	amounts := []money.Money{cart.Subtotal}
+	for _, line := range cart.Lines {
+		amounts = append(amounts, line.Amount)
+	}
+
+	err := money.CheckCurrency(amounts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stacked []*models.OrderDiscount
+		options [][]*models.OrderDiscount
+		best    []*models.OrderDiscount
+		found   = len(code) <= 0
+	)
+
+	for _, promotion := range promotions {
+
+		amount := Discount(promotion, cart)
+		if !amount.IsPositive() {
+			continue
+		}
+
+		discount := &models.OrderDiscount{
+			PromotionId: promotion.Id,
+			Code:        promotion.Code,
+			Name:        promotion.Name,
+			Amount:      amount,
+		}
+
+		if promotion.Stackable {
+			stacked = append(stacked, discount)
+		} else {
+			options = append(options, []*models.OrderDiscount{discount})
+		}
+	}
+
+	if len(stacked) > 0 {
+		options = append(options, stacked)
+	}
+
+	var bestTotal money.Money
+	for _, option := range options {
+
+		if len(code) > 0 && !hasCode(option, code) {
+			continue
+		}
+		found = true
+
+		if total := sum(option); total.Cmp(bestTotal) > 0 {
+			best, bestTotal = option, total
+		}
+	}
+
+	if !found {
+		return nil, ErrNotApplicable
+	}
+
+	return capDiscounts(best, cart.Subtotal), nil
+}
+
+func inScope(promotion *models.Promotion, line Line) bool {
+
+	if len(promotion.ProductIds) <= 0 && len(promotion.CategoryIds) <= 0 {
+		return true
+	}
+
+	for _, id := range promotion.ProductIds {
+		if id == line.ProductId {
+			return true
+		}
+	}
+
+	for _, id := range promotion.CategoryIds {
+		if id == line.CategoryId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameCurrency reports whether the amounts of promotion, if it has any, are in
+// currency. Percentage promotions without limits apply in every currency.
+func sameCurrency(promotion *models.Promotion, currency string) bool {
+
+	for _, amount := range []money.Money{promotion.Amount, promotion.MaxDiscount, promotion.MinOrderAmount} {
+		if amount.IsPositive() && amount.Currency != currency {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasCode(discounts []*models.OrderDiscount, code string) bool {
+	for _, discount := range discounts {
+		if strings.EqualFold(discount.Code, code) {
+			return true
+		}
+	}
+	return false
+}
+
+func sum(discounts []*models.OrderDiscount) money.Money {
+	var total money.Money
+	for _, discount := range discounts {
+		total = total.Add(discount.Amount)
+	}
+	return total
+}
+
+// capDiscounts keeps stacked discounts from exceeding the subtotal, trimming
+// the smallest ones first.
+func capDiscounts(discounts []*models.OrderDiscount, subtotal money.Money) []*models.OrderDiscount {
+
+	sort.SliceStable(discounts, func(i, j int) bool {
+		return discounts[i].Amount.Cmp(discounts[j].Amount) > 0
+	})
+
+	var (
+		capped    []*models.OrderDiscount
+		remaining = subtotal
+	)
+
+	for _, discount := range discounts {
+
+		if !remaining.IsPositive() {
+			break
+		}
+
+		discount.Amount = discount.Amount.Min(remaining)
+		remaining = remaining.Sub(discount.Amount)
+		capped = append(capped, discount)
+	}
+
+	return capped
+}
@@ -0,0 +1,262 @@
+package promotion
+
+import (
+	"app/api/models"
+	"app/pkg/money"
+	"errors"
+	"testing"
+)
+
+func uzs(amount int64) money.Money {
+	return money.New(amount, "UZS")
+}
+
+// testCart is 1000.00 UZS: 600.00 of p1 in c1 and 400.00 of p2 in c2.
+func testCart() *Cart {
+	return &Cart{
+		Subtotal: uzs(100000),
+		Lines: []Line{
+			{ProductId: "p1", CategoryId: "c1", Amount: uzs(60000)},
+			{ProductId: "p2", CategoryId: "c2", Amount: uzs(40000)},
+		},
+	}
+}
+
+func percentage(id string, value float64) *models.Promotion {
+	return &models.Promotion{Id: id, Name: id, DiscountType: models.DiscountTypePercentage, Value: value}
+}
+
+func fixed(id string, amount int64) *models.Promotion {
+	return &models.Promotion{Id: id, Name: id, DiscountType: models.DiscountTypeFixed, Amount: uzs(amount)}
+}
+
+func TestDiscount(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		promotion func() *models.Promotion
+		want      int64
+	}{
+		{"percentage", func() *models.Promotion { return percentage("a", 10) }, 10000},
+		{"rate is taken to hundredths of a percent", func() *models.Promotion { return percentage("a", 12.345) }, 12350},
+		{"percentage capped by max discount", func() *models.Promotion {
+			p := percentage("a", 10)
+			p.MaxDiscount = uzs(5000)
+			return p
+		}, 5000},
+		{"fixed", func() *models.Promotion { return fixed("a", 3000) }, 3000},
+		{"fixed capped by the cart", func() *models.Promotion { return fixed("a", 200000) }, 100000},
+		{"minimum order not met", func() *models.Promotion {
+			p := percentage("a", 10)
+			p.MinOrderAmount = uzs(100001)
+			return p
+		}, 0},
+		{"minimum order met exactly", func() *models.Promotion {
+			p := percentage("a", 10)
+			p.MinOrderAmount = uzs(100000)
+			return p
+		}, 10000},
+		{"scoped to a product", func() *models.Promotion {
+			p := percentage("a", 25)
+			p.ProductIds = []string{"p2"}
+			return p
+		}, 10000},
+		{"scoped to a category", func() *models.Promotion {
+			p := percentage("a", 10)
+			p.CategoryIds = []string{"c1"}
+			return p
+		}, 6000},
+		{"fixed scoped below its amount", func() *models.Promotion {
+			p := fixed("a", 50000)
+			p.ProductIds = []string{"p2"}
+			return p
+		}, 40000},
+		{"scope matches no line", func() *models.Promotion {
+			p := percentage("a", 10)
+			p.ProductIds = []string{"p3"}
+			return p
+		}, 0},
+		{"amounts in another currency", func() *models.Promotion {
+			p := fixed("a", 3000)
+			p.Amount = money.New(3000, "USD")
+			return p
+		}, 0},
+		{"minimum in another currency", func() *models.Promotion {
+			p := percentage("a", 10)
+			p.MinOrderAmount = money.New(100, "USD")
+			return p
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Discount(tt.promotion(), testCart())
+			if got != uzs(tt.want) {
+				t.Fatalf("Discount() = %s, want %s", got, uzs(tt.want))
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+
+	type applied struct {
+		id     string
+		amount int64
+	}
+
+	withCode := func(p *models.Promotion, code string) *models.Promotion {
+		p.Code = code
+		return p
+	}
+
+	stackable := func(p *models.Promotion) *models.Promotion {
+		p.Stackable = true
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		promotions func() []*models.Promotion
+		code       string
+		want       []applied
+		err        error
+	}{
+		{
+			name:       "nothing applies",
+			promotions: func() []*models.Promotion { return nil },
+		},
+		{
+			name: "stackable promotions combine",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{stackable(fixed("a", 5000)), stackable(percentage("b", 10))}
+			},
+			want: []applied{{"b", 10000}, {"a", 5000}},
+		},
+		{
+			name: "stack beats a smaller exclusive",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{percentage("x", 12), stackable(fixed("a", 5000)), stackable(percentage("b", 10))}
+			},
+			want: []applied{{"b", 10000}, {"a", 5000}},
+		},
+		{
+			name: "larger exclusive beats the stack",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{stackable(fixed("a", 5000)), percentage("x", 20), stackable(percentage("b", 10))}
+			},
+			want: []applied{{"x", 20000}},
+		},
+		{
+			name: "exclusives never combine",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{percentage("x", 20), percentage("y", 30)}
+			},
+			want: []applied{{"y", 30000}},
+		},
+		{
+			name: "earlier promotion wins a tie",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{fixed("x", 20000), percentage("y", 20)}
+			},
+			want: []applied{{"x", 20000}},
+		},
+		{
+			name: "promotions that discount nothing are skipped",
+			promotions: func() []*models.Promotion {
+				scoped := percentage("x", 50)
+				scoped.ProductIds = []string{"p3"}
+				return []*models.Promotion{scoped, percentage("y", 5)}
+			},
+			want: []applied{{"y", 5000}},
+		},
+		{
+			name: "code forces a smaller exclusive",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{stackable(fixed("a", 5000)), withCode(percentage("x", 1), "SAVE1"), stackable(percentage("b", 10))}
+			},
+			code: "SAVE1",
+			want: []applied{{"x", 1000}},
+		},
+		{
+			name: "code is matched case-insensitively",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{withCode(percentage("x", 1), "SAVE1"), percentage("y", 20)}
+			},
+			code: "save1",
+			want: []applied{{"x", 1000}},
+		},
+		{
+			name: "stackable code brings its stack over a larger exclusive",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{percentage("x", 50), stackable(withCode(fixed("a", 5000), "FIVE")), stackable(percentage("b", 10))}
+			},
+			code: "FIVE",
+			want: []applied{{"b", 10000}, {"a", 5000}},
+		},
+		{
+			name: "unknown code",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{percentage("x", 10)}
+			},
+			code: "NOPE",
+			err:  ErrNotApplicable,
+		},
+		{
+			name: "code below its minimum order",
+			promotions: func() []*models.Promotion {
+				p := withCode(percentage("x", 10), "BIG")
+				p.MinOrderAmount = uzs(500000)
+				return []*models.Promotion{p, percentage("y", 5)}
+			},
+			code: "BIG",
+			err:  ErrNotApplicable,
+		},
+		{
+			name: "stack is capped at the subtotal, trimming the smallest",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{stackable(fixed("a", 50000)), stackable(fixed("b", 70000))}
+			},
+			want: []applied{{"b", 70000}, {"a", 30000}},
+		},
+		{
+			name: "discounts past the subtotal are dropped",
+			promotions: func() []*models.Promotion {
+				return []*models.Promotion{stackable(fixed("a", 5000)), stackable(percentage("b", 100)), stackable(fixed("c", 1000))}
+			},
+			want: []applied{{"b", 100000}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			discounts, err := Apply(testCart(), tt.promotions(), tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.err)
+			}
+
+			if len(discounts) != len(tt.want) {
+				t.Fatalf("Apply() gave %d discounts, want %d", len(discounts), len(tt.want))
+			}
+
+			for i, discount := range discounts {
+				if discount.PromotionId != tt.want[i].id || discount.Amount != uzs(tt.want[i].amount) {
+					t.Errorf("discount %d = %s %s, want %s %s", i,
+						discount.PromotionId, discount.Amount, tt.want[i].id, uzs(tt.want[i].amount))
+				}
+			}
+		})
+	}
+}
+
+func TestApplyCurrencyMismatch(t *testing.T) {
+
+	cart := testCart()
+	cart.Lines[1].Amount = money.New(40000, "USD")
+
+	_, err := Apply(cart, []*models.Promotion{percentage("a", 10)}, "")
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("Apply() error = %v, want %v", err, money.ErrCurrencyMismatch)
+	}
+}
@@ -0,0 +1,190 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testLines(n int) []string {
+
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+
+	return lines
+}
+
+// xrefOffsets reads the cross-reference table the trailer points at and
+// returns the offset of every object, indexed by object number.
+func xrefOffsets(t *testing.T, pdf []byte) []int {
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatal("no startxref at the end of the file")
+	}
+
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if xref >= len(pdf) || !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	rows := strings.Split(string(pdf[xref:]), "\n")
+
+	var first, size int
+	if _, err := fmt.Sscanf(rows[1], "%d %d", &first, &size); err != nil || first != 0 {
+		t.Fatalf("bad xref subsection %q", rows[1])
+	}
+
+	offsets := make([]int, size)
+	for i := 1; i < size; i++ {
+		row := rows[2+i]
+		if len(row) != 19 || !strings.HasSuffix(row, " 00000 n ") {
+			t.Fatalf("bad xref entry %q", row)
+		}
+
+		offsets[i], _ = strconv.Atoi(row[:10])
+	}
+
+	return offsets
+}
+
+func TestWritePDFXref(t *testing.T) {
+
+	for _, n := range []int{0, 1, linesPerPage, linesPerPage + 1, 3 * linesPerPage} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+
+			var buf bytes.Buffer
+
+			err := writePDF(&buf, testLines(n))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pdf := buf.Bytes()
+
+			offsets := xrefOffsets(t, pdf)
+			if len(offsets) < 6 {
+				t.Fatalf("xref lists %d objects, want at least 5", len(offsets)-1)
+			}
+
+			for i := 1; i < len(offsets); i++ {
+				header := fmt.Sprintf("%d 0 obj\n", i)
+				if offsets[i] >= len(pdf) || !bytes.HasPrefix(pdf[offsets[i]:], []byte(header)) {
+					t.Fatalf("xref offset %d of object %d does not point at %q", offsets[i], i, header)
+				}
+			}
+
+			if !bytes.Contains(pdf, []byte(fmt.Sprintf("/Size %d ", len(offsets)))) {
+				t.Fatalf("trailer /Size does not match the %d xref entries", len(offsets))
+			}
+		})
+	}
+}
+
+func TestWritePDFPages(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		lines int
+		pages int
+	}{
+		{"empty", 0, 1},
+		{"one line", 1, 1},
+		{"a full page", linesPerPage, 1},
+		{"one line over", linesPerPage + 1, 2},
+		{"long", 2*linesPerPage + 5, 3},
+	}
+
+	var (
+		count  = regexp.MustCompile(`/Count (\d+)`)
+		page   = regexp.MustCompile(`/Type /Page /Parent`)
+		stream = regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`)
+		shown  = regexp.MustCompile(`\(line (\d+)\) Tj`)
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var buf bytes.Buffer
+
+			err := writePDF(&buf, testLines(tt.lines))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pdf := buf.Bytes()
+
+			match := count.FindSubmatch(pdf)
+			if match == nil || string(match[1]) != strconv.Itoa(tt.pages) {
+				t.Fatalf("page tree /Count = %s, want %d", match, tt.pages)
+			}
+
+			if got := len(page.FindAll(pdf, -1)); got != tt.pages {
+				t.Fatalf("%d page objects, want %d", got, tt.pages)
+			}
+
+			streams := stream.FindAllSubmatch(pdf, -1)
+			if len(streams) != tt.pages {
+				t.Fatalf("%d content streams, want %d", len(streams), tt.pages)
+			}
+
+			// every line is shown once, in order, and no page holds more than fits
+			next := 1
+			for _, s := range streams {
+				if length, _ := strconv.Atoi(string(s[1])); length != len(s[2]) {
+					t.Fatalf("stream /Length %d, content is %d bytes", length, len(s[2]))
+				}
+
+				lines := shown.FindAllSubmatch(s[2], -1)
+				if len(lines) > linesPerPage {
+					t.Fatalf("page shows %d lines, at most %d fit", len(lines), linesPerPage)
+				}
+
+				for _, line := range lines {
+					if string(line[1]) != strconv.Itoa(next) {
+						t.Fatalf("shows line %s, want line %d", line[1], next)
+					}
+					next++
+				}
+			}
+
+			if next-1 != tt.lines {
+				t.Fatalf("shows %d lines, want %d", next-1, tt.lines)
+			}
+		})
+	}
+}
+
+func TestPDFString(t *testing.T) {
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Total 12.50 USD", "Total 12.50 USD"},
+		{"parentheses", "Tea (green)", `Tea \(green\)`},
+		{"unbalanced parenthesis", "x)", `x\)`},
+		{"backslash", `C:\receipts`, `C:\\receipts`},
+		{"escape sequences stay literal", `\n(`, `\\n\(`},
+		{"latin-1", "Café", "Caf\xe9"},
+		{"winansi", "€5 — “ok”", "\x805 \x97 \x93ok\x94"},
+		{"uzbek modifier letters", "Oʻzbekiston", "O\x91zbekiston"},
+		{"tab", "a\tb", "a b"},
+		{"outside winansi", "Привет", "??????"},
+		{"control characters", "a\nb", "a?b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(pdfString(tt.in)); got != tt.want {
+				t.Fatalf("pdfString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package receipt
+
+import (
+	"app/api/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+	FormatText = "txt"
+)
+
+var ErrUnknownFormat = errors.New("receipt format must be html, pdf or txt")
+
+// Receipt is what the templates render: an order with the invoice it was
+// numbered under.
+type Receipt struct {
+	Invoice *models.OrderInvoice
+	Order   *models.Order
+}
+
+// Number is the printed invoice number, e.g. INV-000042.
+func (r *Receipt) Number() string {
+	return fmt.Sprintf("INV-%06d", r.Invoice.Number)
+}
+
+func IsValidFormat(format string) bool {
+	return format == FormatHTML || format == FormatPDF || format == FormatText
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Render writes the receipt in format. The PDF is the text receipt laid out in
+// a monospaced font, so both always show the same figures.
+func Render(w io.Writer, format string, r *Receipt) error {
+
+	switch format {
+	case FormatHTML:
+		return htmlTemplate.Execute(w, r)
+	case FormatText:
+		return textTemplate.Execute(w, r)
+	case FormatPDF:
+		var text bytes.Buffer
+
+		err := textTemplate.Execute(&text, r)
+		if err != nil {
+			return err
+		}
+
+		return writePDF(w, strings.Split(strings.TrimRight(text.String(), "\n"), "\n"))
+	}
+
+	return ErrUnknownFormat
+}
@@ -0,0 +1,105 @@
+package receipt
+
+import (
+	htmltemplate "html/template"
+	"text/template"
+)
+
+// textTemplate is 68 columns wide; the PDF prints it as is.
+var textTemplate = template.Must(template.New("receipt.txt").Parse(`INVOICE {{.Number}}
+Issued:   {{.Invoice.IssuedAt}}
+Order:    {{.Order.Name}} ({{.Order.Id}})
+Placed:   {{.Order.CreatedAt}}
+Status:   {{.Order.Status}}
+{{with .Order.Customer.Name}}Customer: {{.}}{{with $.Order.Customer.Phone}}, {{.}}{{end}}
+{{end -}}
+Deliver:  {{.Order.DeliveryAddress.Street}}{{with .Order.DeliveryAddress.Apartment}}, {{.}}{{end}}
+{{with .Order.Courier.Name}}Courier:  {{.}}{{with $.Order.Courier.Phone}}, {{.}}{{end}}
+{{end -}}
+--------------------------------------------------------------------
+{{printf "%-29s %4s %16s %16s" "Item" "Qty" "Price" "Total"}}
+--------------------------------------------------------------------
+{{range .Order.Items -}}
+{{printf "%-29.29s %4d %16s %16s" .ProductName .Quantity .Price .TotalPrice}}
+{{end -}}
+--------------------------------------------------------------------
+{{printf "%-51s %16s" "Subtotal" .Order.Subtotal}}
+{{range .Order.Discounts -}}
+{{printf "%-51.51s %16s" (printf "Discount %s" .Name) .Amount.Neg}}
+{{end -}}
+{{printf "%-51s %16s" "Delivery" .Order.DeliveryFee}}
+{{range .Order.Taxes -}}
+{{printf "%-51.51s %16s" (printf "Tax %.2f%% on %s" .Rate .TaxableAmount) .Amount}}
+{{end -}}
+{{if .Order.TaxInclusive -}}
+{{printf "%-51s %16s" "Tax total (included)" .Order.TaxAmount}}
+{{else -}}
+{{printf "%-51s %16s" "Tax total (added)" .Order.TaxAmount}}
+{{end -}}
+====================================================================
+{{printf "%-51s %16s" "TOTAL" .Order.TotalPrice}}
+{{printf "%-51s %16s" "Paid" .Order.PaidAmount}}
+{{if .Order.RefundedAmount.IsPositive -}}
+{{printf "%-51s %16s" "Refunded" .Order.RefundedAmount}}
+{{end -}}
+{{printf "%-51s %16s" "Outstanding" .Order.Outstanding}}
+{{range .Order.ExchangeRates -}}
+Converted at 1 {{.BaseCurrency}} = {{.Rate}} {{.QuoteCurrency}} ({{.EffectiveFrom}})
+{{end -}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("receipt.html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; max-width: 720px; margin: 2em auto; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 4px 6px; border-bottom: 1px solid #ddd; text-align: left; }
+.num { text-align: right; white-space: nowrap; }
+.total td { font-weight: bold; border-top: 2px solid #000; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>
+Issued: {{.Invoice.IssuedAt}}<br>
+Order: {{.Order.Name}} ({{.Order.Id}})<br>
+Placed: {{.Order.CreatedAt}}<br>
+Status: {{.Order.Status}}
+</p>
+<p>
+{{with .Order.Customer.Name}}Customer: {{.}}{{with $.Order.Customer.Phone}}, {{.}}{{end}}<br>{{end}}
+Deliver to: {{.Order.DeliveryAddress.Street}}{{with .Order.DeliveryAddress.Apartment}}, {{.}}{{end}}<br>
+{{with .Order.Courier.Name}}Courier: {{.}}{{with $.Order.Courier.Phone}}, {{.}}{{end}}{{end}}
+</p>
+<table>
+<tr><th>Item</th><th class="num">Qty</th><th class="num">Price</th><th class="num">Tax</th><th class="num">Total</th></tr>
+{{range .Order.Items -}}
+<tr><td>{{.ProductName}}</td><td class="num">{{.Quantity}}</td><td class="num">{{.Price}}</td><td class="num">{{printf "%.2f%%" .TaxRate}}</td><td class="num">{{.TotalPrice}}</td></tr>
+{{end -}}
+</table>
+<table>
+<tr><td>Subtotal</td><td class="num">{{.Order.Subtotal}}</td></tr>
+{{range .Order.Discounts -}}
+<tr><td>Discount {{.Name}}</td><td class="num">{{.Amount.Neg}}</td></tr>
+{{end -}}
+<tr><td>Delivery</td><td class="num">{{.Order.DeliveryFee}}</td></tr>
+{{range .Order.Taxes -}}
+<tr><td>Tax {{printf "%.2f%%" .Rate}} on {{.TaxableAmount}}</td><td class="num">{{.Amount}}</td></tr>
+{{end -}}
+<tr><td>Tax total {{if .Order.TaxInclusive}}(included){{else}}(added){{end}}</td><td class="num">{{.Order.TaxAmount}}</td></tr>
+<tr class="total"><td>Total</td><td class="num">{{.Order.TotalPrice}}</td></tr>
+<tr><td>Paid</td><td class="num">{{.Order.PaidAmount}}</td></tr>
+{{if .Order.RefundedAmount.IsPositive -}}
+<tr><td>Refunded</td><td class="num">{{.Order.RefundedAmount}}</td></tr>
+{{end -}}
+<tr><td>Outstanding</td><td class="num">{{.Order.Outstanding}}</td></tr>
+</table>
+{{range .Order.ExchangeRates -}}
+<p>Converted at 1 {{.BaseCurrency}} = {{.Rate}} {{.QuoteCurrency}} ({{.EffectiveFrom}})</p>
+{{end -}}
+</body>
+</html>
+`))
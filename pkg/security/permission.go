@@ -0,0 +1,155 @@
+package security
+
+// Permission names an action on a resource, written as "resource:action".
+type Permission string
+
+const (
+	RoleAdmin      = "admin"
+	RoleOperator   = "operator"
+	RoleDispatcher = "dispatcher"
+	RoleCourier    = "courier"
+	RoleAnalyst    = "analyst"
+)
+
+const (
+	PermCustomerRead   Permission = "customer:read"
+	PermCustomerWrite  Permission = "customer:write"
+	PermCustomerDelete Permission = "customer:delete"
+
+	PermUserRead   Permission = "user:read"
+	PermUserWrite  Permission = "user:write"
+	PermUserDelete Permission = "user:delete"
+
+	PermCourierRead     Permission = "courier:read"
+	PermCourierWrite    Permission = "courier:write"
+	PermCourierDelete   Permission = "courier:delete"
+	PermCourierStatus   Permission = "courier:status"
+	PermCourierLocation Permission = "courier:location"
+
+	PermCategoryRead   Permission = "category:read"
+	PermCategoryWrite  Permission = "category:write"
+	PermCategoryDelete Permission = "category:delete"
+
+	PermProductRead   Permission = "product:read"
+	PermProductWrite  Permission = "product:write"
+	PermProductDelete Permission = "product:delete"
+
+	PermStockRead  Permission = "stock:read"
+	PermStockWrite Permission = "stock:write"
+
+	PermWarehouseRead   Permission = "warehouse:read"
+	PermWarehouseWrite  Permission = "warehouse:write"
+	PermWarehouseDelete Permission = "warehouse:delete"
+
+	PermDeliveryZoneRead   Permission = "delivery_zone:read"
+	PermDeliveryZoneWrite  Permission = "delivery_zone:write"
+	PermDeliveryZoneDelete Permission = "delivery_zone:delete"
+
+	PermTaxClassRead   Permission = "tax_class:read"
+	PermTaxClassWrite  Permission = "tax_class:write"
+	PermTaxClassDelete Permission = "tax_class:delete"
+
+	PermExchangeRateRead   Permission = "exchange_rate:read"
+	PermExchangeRateWrite  Permission = "exchange_rate:write"
+	PermExchangeRateDelete Permission = "exchange_rate:delete"
+
+	PermPromotionRead   Permission = "promotion:read"
+	PermPromotionWrite  Permission = "promotion:write"
+	PermPromotionDelete Permission = "promotion:delete"
+
+	PermOrderRead       Permission = "order:read"
+	PermOrderWrite      Permission = "order:write"
+	PermOrderDelete     Permission = "order:delete"
+	PermOrderTransition Permission = "order:transition"
+	PermOrderRefund     Permission = "order:refund"
+
+	PermPaymentRead  Permission = "payment:read"
+	PermPaymentWrite Permission = "payment:write"
+
+	PermWebhookRead   Permission = "webhook:read"
+	PermWebhookWrite  Permission = "webhook:write"
+	PermWebhookDelete Permission = "webhook:delete"
+)
+
+var readPermissions = []Permission{
+	PermCustomerRead,
+	PermCourierRead,
+	PermCategoryRead,
+	PermProductRead,
+	PermStockRead,
+	PermWarehouseRead,
+	PermDeliveryZoneRead,
+	PermTaxClassRead,
+	PermExchangeRateRead,
+	PermPromotionRead,
+	PermOrderRead,
+	PermPaymentRead,
+}
+
+// rolePermissions is the permission matrix. Admins are not listed, they may do
+// everything; couriers are further limited to their own orders by the handlers.
+var rolePermissions = map[string][]Permission{
+	RoleOperator: append([]Permission{
+		PermCustomerWrite,
+		PermCourierWrite,
+		PermCourierStatus,
+		PermCategoryWrite,
+		PermProductWrite,
+		PermStockWrite,
+		PermWarehouseWrite,
+		PermDeliveryZoneWrite,
+		PermTaxClassWrite,
+		PermExchangeRateWrite,
+		PermPromotionWrite,
+		PermOrderWrite,
+		PermOrderTransition,
+		PermPaymentWrite,
+		PermOrderRefund,
+	}, readPermissions...),
+	RoleDispatcher: {
+		PermCustomerRead,
+		PermCourierRead,
+		PermCourierStatus,
+		PermWarehouseRead,
+		PermDeliveryZoneRead,
+		PermOrderRead,
+		PermOrderWrite,
+		PermOrderTransition,
+		PermPaymentRead,
+		PermPaymentWrite,
+	},
+	RoleCourier: {
+		PermCourierStatus,
+		PermCourierLocation,
+		PermOrderRead,
+		PermOrderTransition,
+		PermPaymentRead,
+		PermPaymentWrite,
+	},
+	RoleAnalyst: readPermissions,
+}
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission reports whether role grants permission.
+func HasPermission(role string, permission Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
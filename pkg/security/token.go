@@ -0,0 +1,69 @@
+package security
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	TokenTypeAccess   = "access"
+	TokenTypeRefresh  = "refresh"
+	TokenTypeCustomer = "customer"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims is the payload of every token; Type tells them apart so a refresh or
+// customer token cannot be used to call the staff API.
+type Claims struct {
+	UserId     string `json:"user_id,omitempty"`
+	Role       string `json:"role,omitempty"`
+	CourierId  string `json:"courier_id,omitempty"`
+	CustomerId string `json:"customer_id,omitempty"`
+	Type       string `json:"type"`
+	jwt.RegisteredClaims
+}
+
+// GenerateToken signs claims as a token that expires after ttl.
+func GenerateToken(secret string, claims Claims, ttl time.Duration) (string, error) {
+
+	now := time.Now()
+
+	subject := claims.UserId
+	if claims.Type == TokenTypeCustomer {
+		subject = claims.CustomerId
+	}
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, &claims).SignedString([]byte(secret))
+}
+
+// ParseToken verifies the signature and expiry of tokenString and checks that it
+// is of the expected type.
+func ParseToken(secret, tokenString, tokenType string) (*Claims, error) {
+
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Type != tokenType || len(claims.Subject) <= 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
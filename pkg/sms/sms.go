@@ -0,0 +1,27 @@
+package sms
+
+import (
+	"app/pkg/logger"
+	"context"
+)
+
+// Sender delivers a text message to a phone number.
+type Sender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+type logSender struct {
+	logger logger.LoggerI
+}
+
+// NewLogSender returns a Sender that only writes messages to the log, for local runs.
+func NewLogSender(log logger.LoggerI) Sender {
+	return &logSender{
+		logger: log,
+	}
+}
+
+func (s *logSender) Send(ctx context.Context, phone, message string) error {
+	s.logger.Info("sms", logger.String("phone", phone), logger.String("message", message))
+	return nil
+}
@@ -0,0 +1,84 @@
+package tax
+
+import "app/pkg/money"
+
+// Line is an order line to tax: what it costs before discounts and its rate in
+// percent.
+type Line struct {
+	Amount money.Money
+	Rate   float64
+}
+
+// LineTax is the outcome for one line. Taxable is the line after its share of
+// the order discount; for tax-inclusive prices Tax is already part of it.
+type LineTax struct {
+	Taxable money.Money
+	Tax     money.Money
+}
+
+// Calculate spreads discount over lines in proportion to their amounts and
+// taxes each line. The discount split leaves its rounding remainder on the
+// last line and every tax is rounded per line, so that the order tax is
+// always the sum of its line taxes. Lines and discount in different
+// currencies are an ErrCurrencyMismatch.
+func Calculate(lines []Line, discount money.Money, inclusive bool) ([]LineTax, error) {
+
+	amounts := []money.Money{discount}
+	for _, line := range lines {
+		amounts = append(amounts, line.Amount)
+	}
+
+	err := money.CheckCurrency(amounts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result   = make([]LineTax, len(lines))
+		weights  = make([]int64, len(lines))
+		subtotal money.Money
+	)
+
+	for i, line := range lines {
+		weights[i] = line.Amount.Amount
+		subtotal = subtotal.Add(line.Amount)
+	}
+
+	if discount.Cmp(subtotal) > 0 {
+		discount = subtotal
+	}
+
+	shares := discount.Allocate(weights)
+
+	for i, line := range lines {
+
+		taxable := line.Amount.Sub(shares[i])
+		if taxable.IsNegative() {
+			taxable = money.Zero(taxable.Currency)
+		}
+
+		result[i] = LineTax{
+			Taxable: taxable,
+			Tax:     Tax(taxable, line.Rate, inclusive),
+		}
+	}
+
+	return result, nil
+}
+
+// Tax is the tax on amount at rate percent, rounded half away from zero to the
+// minor unit. Inclusive amounts already contain the tax, exclusive ones get it
+// added on top.
+func Tax(amount money.Money, rate float64, inclusive bool) money.Money {
+
+	bp := money.BasisPoints(rate)
+	if bp <= 0 || !amount.IsPositive() {
+		return money.Zero(amount.Currency)
+	}
+
+	if inclusive {
+		return amount.MulRat(bp, 10000+bp)
+	}
+
+	return amount.MulRat(bp, 10000)
+}
@@ -0,0 +1,138 @@
+package tax
+
+import (
+	"app/pkg/money"
+	"errors"
+	"testing"
+)
+
+func uzs(amount int64) money.Money {
+	return money.New(amount, "UZS")
+}
+
+func TestTax(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		amount    money.Money
+		rate      float64
+		inclusive bool
+		want      money.Money
+	}{
+		{"exclusive", uzs(10000), 12, false, uzs(1200)},
+		{"inclusive", uzs(11200), 12, true, uzs(1200)},
+		{"exclusive rounds down", uzs(1004), 12, false, uzs(120)},
+		{"exclusive rounds up", uzs(1005), 12, false, uzs(121)},
+		{"exclusive half rounds away from zero", uzs(10), 5, false, uzs(1)},
+		{"inclusive rounds", uzs(1000), 12, true, uzs(107)},
+		{"inclusive half rounds away from zero", uzs(3), 100, true, uzs(2)},
+		{"fractional rate", uzs(10000), 12.5, false, uzs(1250)},
+		{"fractional rate inclusive", uzs(11250), 12.5, true, uzs(1250)},
+		{"zero-exponent currency", money.New(155, "JPY"), 10, false, money.New(16, "JPY")},
+		{"zero rate", uzs(10000), 0, false, uzs(0)},
+		{"negative rate", uzs(10000), -5, false, uzs(0)},
+		{"nothing to tax", uzs(0), 12, false, uzs(0)},
+		{"negative amount", uzs(-500), 12, true, uzs(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tax(tt.amount, tt.rate, tt.inclusive); got != tt.want {
+				t.Fatalf("Tax() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		lines     []Line
+		discount  money.Money
+		inclusive bool
+		want      []LineTax
+	}{
+		{
+			name:  "several rates exclusive",
+			lines: []Line{{uzs(10000), 12}, {uzs(5000), 0}, {uzs(2000), 20}},
+			want:  []LineTax{{uzs(10000), uzs(1200)}, {uzs(5000), uzs(0)}, {uzs(2000), uzs(400)}},
+		},
+		{
+			name:      "several rates inclusive",
+			lines:     []Line{{uzs(10000), 12}, {uzs(5000), 0}, {uzs(2000), 20}},
+			inclusive: true,
+			want:      []LineTax{{uzs(10000), uzs(1071)}, {uzs(5000), uzs(0)}, {uzs(2000), uzs(333)}},
+		},
+		{
+			name:     "discount remainder goes to the last line",
+			lines:    []Line{{uzs(3333), 12}, {uzs(3333), 12}, {uzs(3334), 0}},
+			discount: uzs(1000),
+			want:     []LineTax{{uzs(3000), uzs(360)}, {uzs(3000), uzs(360)}, {uzs(3000), uzs(0)}},
+		},
+		{
+			name:     "discount too small to split exclusive",
+			lines:    []Line{{uzs(100), 12}, {uzs(200), 12}},
+			discount: uzs(1),
+			want:     []LineTax{{uzs(100), uzs(12)}, {uzs(199), uzs(24)}},
+		},
+		{
+			name:      "discount too small to split inclusive",
+			lines:     []Line{{uzs(100), 12}, {uzs(200), 12}},
+			discount:  uzs(1),
+			inclusive: true,
+			want:      []LineTax{{uzs(100), uzs(11)}, {uzs(199), uzs(21)}},
+		},
+		{
+			name:     "discount is capped at the subtotal",
+			lines:    []Line{{uzs(500), 12}, {uzs(500), 20}},
+			discount: uzs(2000),
+			want:     []LineTax{{uzs(0), uzs(0)}, {uzs(0), uzs(0)}},
+		},
+		{
+			name: "no lines",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := Calculate(tt.lines, tt.discount, tt.inclusive)
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Calculate() gave %d lines, want %d", len(got), len(tt.want))
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d = %s tax %s, want %s tax %s", i,
+						got[i].Taxable, got[i].Tax, tt.want[i].Taxable, tt.want[i].Tax)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateCurrencyMismatch(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		lines    []Line
+		discount money.Money
+	}{
+		{"lines", []Line{{uzs(1000), 12}, {money.New(1000, "USD"), 12}}, uzs(0)},
+		{"discount", []Line{{uzs(1000), 12}}, money.New(100, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Calculate(tt.lines, tt.discount, false)
+			if !errors.Is(err, money.ErrCurrencyMismatch) {
+				t.Fatalf("Calculate() error = %v, want %v", err, money.ErrCurrencyMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import "strings"
+
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderUpdated       = "order.updated"
+	EventOrderDeleted       = "order.deleted"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderRefunded      = "order.refunded"
+	EventProductCreated     = "product.created"
+	EventProductUpdated     = "product.updated"
+	EventProductDeleted     = "product.deleted"
+	EventCustomerCreated    = "customer.created"
+	EventCustomerUpdated    = "customer.updated"
+	EventCustomerDeleted    = "customer.deleted"
+
+	// Wildcard subscribes to every event.
+	Wildcard = "*"
+)
+
+var events = map[string]bool{
+	EventOrderCreated:       true,
+	EventOrderUpdated:       true,
+	EventOrderDeleted:       true,
+	EventOrderStatusChanged: true,
+	EventOrderRefunded:      true,
+	EventProductCreated:     true,
+	EventProductUpdated:     true,
+	EventProductDeleted:     true,
+	EventCustomerCreated:    true,
+	EventCustomerUpdated:    true,
+	EventCustomerDeleted:    true,
+}
+
+// IsValidFilter accepts a known event, "*" or a resource wildcard like "order.*".
+func IsValidFilter(filter string) bool {
+
+	if filter == Wildcard || events[filter] {
+		return true
+	}
+
+	if !strings.HasSuffix(filter, ".*") {
+		return false
+	}
+
+	for event := range events {
+		if matchesResource(filter, event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matches reports whether a subscription with filters receives event; an empty
+// filter list receives everything.
+func Matches(filters []string, event string) bool {
+
+	if len(filters) <= 0 {
+		return true
+	}
+
+	for _, filter := range filters {
+		if filter == Wildcard || filter == event {
+			return true
+		}
+
+		if strings.HasSuffix(filter, ".*") && matchesResource(filter, event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesResource compares the "order." prefix of a "order.*" filter with event.
+func matchesResource(filter, event string) bool {
+	return strings.HasPrefix(event, strings.TrimSuffix(filter, "*"))
+}
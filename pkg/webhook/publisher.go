@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"app/api/models"
+	"app/storage"
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+// Payload is the JSON body of every delivery. Id is the outbox event id, so
+// receivers can drop the duplicates that at-least-once delivery produces.
+type Payload struct {
+	Id        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Publisher turns outbox events into one queued delivery per subscribed
+// webhook; the Worker sends them.
+type Publisher struct {
+	store storage.StorageI
+}
+
+func NewPublisher(store storage.StorageI) *Publisher {
+	return &Publisher{
+		store: store,
+	}
+}
+
+// Handle is an outbox handler. Events webhooks cannot subscribe to are ignored.
+func (p *Publisher) Handle(ctx context.Context, event *models.OutboxEvent) error {
+
+	if !events[event.EventType] {
+		return nil
+	}
+
+	webhooks, err := p.store.Webhook().GetActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+
+	for _, webhook := range webhooks {
+
+		if !Matches(webhook.Events, event.EventType) {
+			continue
+		}
+
+		if body == nil {
+			body, err = json.Marshal(&Payload{
+				Id:        strconv.FormatInt(event.Id, 10),
+				Event:     event.EventType,
+				CreatedAt: event.CreatedAt,
+				Data:      json.RawMessage(event.Payload),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = p.store.Webhook().CreateDelivery(ctx, &models.CreateWebhookDelivery{
+			WebhookId: webhook.Id,
+			Event:     event.EventType,
+			Payload:   body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
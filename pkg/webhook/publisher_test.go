@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"app/api/models"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestPublisherQueuesMatchingWebhooks(t *testing.T) {
+
+	repo := &fakeWebhookRepo{active: []*models.Webhook{
+		{Id: "all"},
+		{Id: "orders", Events: []string{"order.*"}},
+		{Id: "created", Events: []string{EventOrderCreated}},
+		{Id: "products", Events: []string{"product.*"}},
+	}}
+
+	publisher := NewPublisher(&fakeStore{webhooks: repo})
+
+	err := publisher.Handle(context.Background(), &models.OutboxEvent{
+		Id:        42,
+		EventType: EventOrderCreated,
+		Payload:   `{"id":"o1"}`,
+		CreatedAt: "2026-10-18 10:00:00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, delivery := range repo.created {
+		got = append(got, delivery.WebhookId)
+	}
+
+	if want := []string{"all", "orders", "created"}; !equalStrings(got, want) {
+		t.Fatalf("queued for %v, want %v", got, want)
+	}
+
+	var payload Payload
+
+	err = json.Unmarshal(repo.created[0].Payload, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.Id != "42" || payload.Event != EventOrderCreated || string(payload.Data) != `{"id":"o1"}` {
+		t.Fatalf("payload = %+v", payload)
+	}
+}
+
+func TestPublisherIgnoresUnknownEvents(t *testing.T) {
+
+	repo := &fakeWebhookRepo{active: []*models.Webhook{{Id: "all"}}}
+
+	err := NewPublisher(&fakeStore{webhooks: repo}).Handle(context.Background(), &models.OutboxEvent{
+		Id:        1,
+		EventType: "order.assigned",
+		Payload:   `{}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.created) != 0 {
+		t.Fatalf("queued %d deliveries, want none", len(repo.created))
+	}
+}
+
+func equalStrings(a, b []string) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
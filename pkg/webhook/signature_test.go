@@ -0,0 +1,40 @@
+package webhook
+
+import "testing"
+
+func TestSign(t *testing.T) {
+
+	var (
+		secret    = "whsec_test"
+		timestamp = int64(1700000000)
+		body      = []byte(`{"id":"1","event":"order.created"}`)
+		want      = "sha256=4dc3188eba2e667bbbcd5e3f242ff77f3627308eb6a66d56e51d3e779ab82d8c"
+	)
+
+	if got := Sign(secret, timestamp, body); got != want {
+		t.Fatalf("Sign() = %s, want %s", got, want)
+	}
+
+	if !Verify(secret, timestamp, body, want) {
+		t.Fatal("Verify() rejected a valid signature")
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+	}{
+		{"other secret", "whsec_other", timestamp, body},
+		{"other timestamp", secret, timestamp + 1, body},
+		{"tampered body", secret, timestamp, []byte(`{"id":"2","event":"order.created"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if Verify(tt.secret, tt.timestamp, tt.body, want) {
+				t.Fatal("Verify() accepted a signature it should reject")
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	workerBatchSize = 20
+	workerLease     = 5 * time.Minute
+	requestTimeout  = 10 * time.Second
+	maxBackoff      = 6 * time.Hour
+	maxErrorLength  = 512
+)
+
+// Worker posts queued deliveries to their webhook, retrying failures with
+// exponential backoff and moving a delivery to the dead-letter list once
+// maxAttempts is reached.
+type Worker struct {
+	store       storage.StorageI
+	client      *http.Client
+	logger      logger.LoggerI
+	interval    time.Duration
+	backoff     time.Duration
+	maxAttempts int
+}
+
+func NewWorker(store storage.StorageI, log logger.LoggerI, interval, backoff time.Duration, maxAttempts int) *Worker {
+	return &Worker{
+		store:       store,
+		client:      &http.Client{Timeout: requestTimeout},
+		logger:      log,
+		interval:    interval,
+		backoff:     backoff,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run polls the delivery queue every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context) {
+
+	deliveries, err := w.store.Webhook().ClaimDeliveries(ctx, &models.ClaimWebhookDeliveries{
+		Limit: workerBatchSize,
+		Lease: int64(workerLease.Seconds()),
+	})
+	if err != nil {
+		w.logger.Error("claim webhook deliveries", logger.Error(err))
+		return
+	}
+
+	for _, delivery := range deliveries {
+
+		status, err := w.send(ctx, delivery)
+		if err == nil {
+			err = w.store.Webhook().MarkDelivered(ctx, &models.DeliveredWebhook{
+				Id:             delivery.Id,
+				ResponseStatus: status,
+			})
+			if err != nil {
+				w.logger.Error("mark webhook delivered", logger.String("id", delivery.Id), logger.Error(err))
+			}
+			continue
+		}
+
+		attempts := delivery.Attempts + 1
+
+		w.logger.Warn("send webhook",
+			logger.String("id", delivery.Id),
+			logger.String("url", delivery.Url),
+			logger.Int("attempt", attempts),
+			logger.Error(err),
+		)
+
+		err = w.store.Webhook().MarkDeliveryFailed(ctx, &models.FailWebhookDelivery{
+			Id:             delivery.Id,
+			ResponseStatus: status,
+			Error:          err.Error(),
+			RetryAfter:     int64(w.retryAfter(attempts).Seconds()),
+			Dead:           attempts >= w.maxAttempts,
+		})
+		if err != nil {
+			w.logger.Error("mark webhook failed", logger.String("id", delivery.Id), logger.Error(err))
+		}
+	}
+}
+
+// send posts the signed payload and returns the response status; anything
+// outside 2xx counts as a failure.
+func (w *Worker) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.Id)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, message)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// retryAfter doubles the backoff with every attempt, up to maxBackoff.
+func (w *Worker) retryAfter(attempts int) time.Duration {
+
+	delay := w.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,240 @@
+package webhook
+
+import (
+	"app/api/models"
+	"app/pkg/logger"
+	"app/storage"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStore serves Webhook() from memory; every other repo is left nil.
+type fakeStore struct {
+	storage.StorageI
+	webhooks *fakeWebhookRepo
+}
+
+func (s *fakeStore) Webhook() storage.WebhookRepoI {
+	return s.webhooks
+}
+
+// fakeWebhookRepo keeps deliveries in memory. Claims ignore the retry delay so
+// a test can drive one attempt per process call.
+type fakeWebhookRepo struct {
+	storage.WebhookRepoI
+
+	mu         sync.Mutex
+	active     []*models.Webhook
+	created    []*models.CreateWebhookDelivery
+	deliveries []*models.WebhookDelivery
+	delivered  []*models.DeliveredWebhook
+	failed     []*models.FailWebhookDelivery
+}
+
+func (r *fakeWebhookRepo) GetActive(ctx context.Context) ([]*models.Webhook, error) {
+	return r.active, nil
+}
+
+func (r *fakeWebhookRepo) CreateDelivery(ctx context.Context, req *models.CreateWebhookDelivery) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.created = append(r.created, req)
+
+	return strconv.Itoa(len(r.created)), nil
+}
+
+func (r *fakeWebhookRepo) ClaimDeliveries(ctx context.Context, req *models.ClaimWebhookDeliveries) ([]*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []*models.WebhookDelivery
+
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.WebhookDeliveryPending && len(claimed) < req.Limit {
+			copied := *delivery
+			claimed = append(claimed, &copied)
+		}
+	}
+
+	return claimed, nil
+}
+
+func (r *fakeWebhookRepo) MarkDelivered(ctx context.Context, req *models.DeliveredWebhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delivered = append(r.delivered, req)
+	r.find(req.Id).Status = models.WebhookDeliveryDelivered
+
+	return nil
+}
+
+func (r *fakeWebhookRepo) MarkDeliveryFailed(ctx context.Context, req *models.FailWebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failed = append(r.failed, req)
+
+	delivery := r.find(req.Id)
+	delivery.Attempts++
+	if req.Dead {
+		delivery.Status = models.WebhookDeliveryDead
+	}
+
+	return nil
+}
+
+func (r *fakeWebhookRepo) find(id string) *models.WebhookDelivery {
+	for _, delivery := range r.deliveries {
+		if delivery.Id == id {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func newTestWorker(repo *fakeWebhookRepo, backoff time.Duration, maxAttempts int) *Worker {
+	return NewWorker(&fakeStore{webhooks: repo}, logger.NewLogger("test", logger.LevelFatal), time.Second, backoff, maxAttempts)
+}
+
+func TestWorkerDeliversSignedRequest(t *testing.T) {
+
+	var (
+		secret  = "whsec_test"
+		payload = `{"id":"7","event":"order.created","data":{}}`
+		checked = make(chan error, 1)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		switch {
+		case err != nil:
+			checked <- err
+		case !Verify(secret, timestamp, body, r.Header.Get(HeaderSignature)):
+			checked <- errors.New("signature does not verify")
+		case r.Header.Get(HeaderEvent) != EventOrderCreated || r.Header.Get(HeaderDelivery) != "d1":
+			checked <- errors.New("missing event or delivery header")
+		default:
+			checked <- nil
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := &fakeWebhookRepo{deliveries: []*models.WebhookDelivery{{
+		Id:      "d1",
+		Url:     server.URL,
+		Secret:  secret,
+		Event:   EventOrderCreated,
+		Payload: payload,
+		Status:  models.WebhookDeliveryPending,
+	}}}
+
+	newTestWorker(repo, time.Minute, 3).process(context.Background())
+
+	if err := <-checked; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.delivered) != 1 || repo.delivered[0].ResponseStatus != http.StatusNoContent {
+		t.Fatalf("delivered = %+v, want one delivery with status 204", repo.delivered)
+	}
+
+	if len(repo.failed) != 0 {
+		t.Fatalf("failed = %+v, want none", repo.failed)
+	}
+}
+
+func TestWorkerRetriesWithBackoffAndDeadLetters(t *testing.T) {
+
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	const maxAttempts = 4
+
+	repo := &fakeWebhookRepo{deliveries: []*models.WebhookDelivery{{
+		Id:      "d1",
+		Url:     server.URL,
+		Secret:  "whsec_test",
+		Event:   EventOrderCreated,
+		Payload: `{}`,
+		Status:  models.WebhookDeliveryPending,
+	}}}
+
+	worker := newTestWorker(repo, time.Minute, maxAttempts)
+
+	// one more round than allowed: a dead delivery must not be sent again
+	for i := 0; i <= maxAttempts; i++ {
+		worker.process(context.Background())
+	}
+
+	if requests != maxAttempts {
+		t.Fatalf("server got %d requests, want %d", requests, maxAttempts)
+	}
+
+	if len(repo.failed) != maxAttempts {
+		t.Fatalf("got %d failures, want %d", len(repo.failed), maxAttempts)
+	}
+
+	wantRetry := []int64{60, 120, 240, 480}
+
+	for i, failure := range repo.failed {
+		if failure.ResponseStatus != http.StatusInternalServerError {
+			t.Errorf("attempt %d: status %d, want 500", i+1, failure.ResponseStatus)
+		}
+		if failure.RetryAfter != wantRetry[i] {
+			t.Errorf("attempt %d: retry after %ds, want %ds", i+1, failure.RetryAfter, wantRetry[i])
+		}
+		if dead := i == maxAttempts-1; failure.Dead != dead {
+			t.Errorf("attempt %d: dead = %v, want %v", i+1, failure.Dead, dead)
+		}
+	}
+
+	if len(repo.delivered) != 0 {
+		t.Fatalf("delivered = %+v, want none", repo.delivered)
+	}
+}
+
+func TestWorkerRetryAfterIsCapped(t *testing.T) {
+
+	worker := newTestWorker(&fakeWebhookRepo{}, time.Hour, 50)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Hour},
+		{2, 2 * time.Hour},
+		{3, 4 * time.Hour},
+		{4, maxBackoff},
+		{40, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := worker.retryAfter(tt.attempts); got != tt.want {
+			t.Errorf("retryAfter(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
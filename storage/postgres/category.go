@@ -32,19 +32,37 @@ func (c *categoryRepo) Create(ctx context.Context, req *models.CreateCategory) (
 		INSERT INTO categories(
 			id, 
 			name,
+			tax_class_id,
 			updated_at
 		)
-		VALUES (:id, :name, NOW())
+		VALUES (:id, :name, :tax_class_id, NOW())
 	`
 
 	params := map[string]interface{}{
 		"id":    id,
 		"name":  req.Name,
+		"tax_class_id": helper.NewNullString(req.TaxClassId),
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	_, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "categories", models.OutboxAggregateCategory, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -54,17 +72,19 @@ func (c *categoryRepo) Create(ctx context.Context, req *models.CreateCategory) (
 
 func (c *categoryRepo) GetByID(ctx context.Context, req *models.CategoryPrimaryKey) (*models.Category, error) {
 	var (
-		query      string
-		id         sql.NullString
-		name       sql.NullString
-		created_at sql.NullString
-		updated_at sql.NullString
+		query        string
+		id           sql.NullString
+		name         sql.NullString
+		tax_class_id sql.NullString
+		created_at   sql.NullString
+		updated_at   sql.NullString
 	)
 
 	query = `
 		SELECT 
 			id,
 			name,
+			tax_class_id,
 			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
 			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
 		FROM categories
@@ -74,6 +94,7 @@ func (c *categoryRepo) GetByID(ctx context.Context, req *models.CategoryPrimaryK
 	err := c.db.QueryRow(ctx, query, req.Id).Scan(
 		&id,
 		&name,
+		&tax_class_id,
 		&created_at,
 		&updated_at,
 	)
@@ -83,10 +104,11 @@ func (c *categoryRepo) GetByID(ctx context.Context, req *models.CategoryPrimaryK
 	}
 
 	return &models.Category{
-		Id:        id.String,
-		Name:      name.String,
-		CreatedAt: created_at.String,
-		UpdatedAt: updated_at.String,
+		Id:         id.String,
+		Name:       name.String,
+		TaxClassId: tax_class_id.String,
+		CreatedAt:  created_at.String,
+		UpdatedAt:  updated_at.String,
 	}, nil
 }
 
@@ -104,6 +126,7 @@ func (c *categoryRepo) GetList(ctx context.Context, req *models.GetListCategoryR
 		SELECT
 			id, 
 			name,
+			tax_class_id,
 			created_at,
 			updated_at
 		FROM categories	
@@ -133,17 +156,19 @@ func (c *categoryRepo) GetList(ctx context.Context, req *models.GetListCategoryR
 
 		var courier models.Category
 
-		var id, name, created_at, updated_at sql.NullString
+		var id, name, tax_class_id, created_at, updated_at sql.NullString
 
 		err = rows.Scan(
 			&id,
 			&name,
+			&tax_class_id,
 			&created_at,
 			&updated_at,
 		)
 
 		courier.Id = id.String
 		courier.Name = name.String
+		courier.TaxClassId = tax_class_id.String
 		courier.CreatedAt = created_at.String
 		courier.UpdatedAt = updated_at.String
 
@@ -170,6 +195,7 @@ func (c *categoryRepo) Update(ctx context.Context, req *models.UpdateCategory) (
 			categories
 		SET 
 			name = :name,
+			tax_class_id = :tax_class_id,
 			updated_at = now()
 		WHERE id = :id
 	`
@@ -177,11 +203,28 @@ func (c *categoryRepo) Update(ctx context.Context, req *models.UpdateCategory) (
 	params = map[string]interface{}{
 		"id":    req.Id,
 		"name":  req.Name,
+		"tax_class_id": helper.NewNullString(req.TaxClassId),
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "categories", models.OutboxAggregateCategory, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -216,7 +259,23 @@ func (c *categoryRepo) Patch(ctx context.Context, req *models.PatchRequest) (int
 
 	query, args := helper.ReplaceQueryParams(query, req.Fields)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "categories", models.OutboxAggregateCategory, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -226,7 +285,18 @@ func (c *categoryRepo) Patch(ctx context.Context, req *models.PatchRequest) (int
 
 func (c *categoryRepo) Delete(ctx context.Context, req *models.CategoryPrimaryKey) error {
 
-	_, err := c.db.Exec(ctx,
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "categories", models.OutboxAggregateCategory, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
 		"DELETE FROM categories WHERE id = $1", req.Id,
 	)
 
@@ -234,5 +304,5 @@ func (c *categoryRepo) Delete(ctx context.Context, req *models.CategoryPrimaryKe
 		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
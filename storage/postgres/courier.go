@@ -3,6 +3,7 @@ package postgres
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/storage"
 	"context"
 	"database/sql"
 	"errors"
@@ -12,6 +13,9 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// courierLocationHistory is how many pings are kept per courier; older ones are pruned on insert.
+const courierLocationHistory = 100
+
 type courierRepo struct {
 	db *pgxpool.Pool
 }
@@ -46,7 +50,23 @@ func (c *courierRepo) Create(ctx context.Context, req *models.CreateCourier) (st
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	_, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -60,6 +80,10 @@ func (c *courierRepo) GetByID(ctx context.Context, req *models.CourierPrimaryKey
 		id         sql.NullString
 		name       sql.NullString
 		phone      sql.NullString
+		status     sql.NullString
+		last_lat   sql.NullFloat64
+		last_lng   sql.NullFloat64
+		located_at sql.NullString
 		created_at sql.NullString
 		updated_at sql.NullString
 	)
@@ -69,6 +93,10 @@ func (c *courierRepo) GetByID(ctx context.Context, req *models.CourierPrimaryKey
 			id,
 			name,
 			phone,
+			status,
+			last_lat,
+			last_lng,
+			TO_CHAR(last_location_at, 'YYYY-MM-DD HH24:MI:SS'),
 			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
 			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
 		FROM couriers
@@ -79,6 +107,10 @@ func (c *courierRepo) GetByID(ctx context.Context, req *models.CourierPrimaryKey
 		&id,
 		&name,
 		&phone,
+		&status,
+		&last_lat,
+		&last_lng,
+		&located_at,
 		&created_at,
 		&updated_at,
 	)
@@ -88,11 +120,15 @@ func (c *courierRepo) GetByID(ctx context.Context, req *models.CourierPrimaryKey
 	}
 
 	return &models.Courier{
-		Id:        id.String,
-		Name:      name.String,
-		Phone:     phone.String,
-		CreatedAt: created_at.String,
-		UpdatedAt: updated_at.String,
+		Id:             id.String,
+		Name:           name.String,
+		Phone:          phone.String,
+		Status:         status.String,
+		LastLat:        last_lat.Float64,
+		LastLng:        last_lng.Float64,
+		LastLocationAt: located_at.String,
+		CreatedAt:      created_at.String,
+		UpdatedAt:      updated_at.String,
 	}, nil
 }
 
@@ -111,6 +147,7 @@ func (c *courierRepo) GetList(ctx context.Context, req *models.GetListCourierReq
 			id, 
 			name,
 			phone,
+			status,
 			created_at,
 			updated_at
 		FROM couriers	
@@ -140,12 +177,13 @@ func (c *courierRepo) GetList(ctx context.Context, req *models.GetListCourierReq
 
 		var courier models.Courier
 
-		var id, name, phone, created_at, updated_at sql.NullString
+		var id, name, phone, status, created_at, updated_at sql.NullString
 
 		err = rows.Scan(
 			&id,
 			&name,
 			&phone,
+			&status,
 			&created_at,
 			&updated_at,
 		)
@@ -153,6 +191,7 @@ func (c *courierRepo) GetList(ctx context.Context, req *models.GetListCourierReq
 		courier.Id = id.String
 		courier.Name = name.String
 		courier.Phone = phone.String
+		courier.Status = status.String
 		courier.CreatedAt = created_at.String
 		courier.UpdatedAt = updated_at.String
 
@@ -192,7 +231,23 @@ func (c *courierRepo) Update(ctx context.Context, req *models.UpdateCourier) (in
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -227,7 +282,23 @@ func (c *courierRepo) Patch(ctx context.Context, req *models.PatchRequest) (int6
 
 	query, args := helper.ReplaceQueryParams(query, req.Fields)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -237,7 +308,18 @@ func (c *courierRepo) Patch(ctx context.Context, req *models.PatchRequest) (int6
 
 func (c *courierRepo) Delete(ctx context.Context, req *models.CourierPrimaryKey) error {
 
-	_, err := c.db.Exec(ctx,
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
 		"DELETE FROM couriers WHERE id = $1", req.Id,
 	)
 
@@ -245,5 +327,392 @@ func (c *courierRepo) Delete(ctx context.Context, req *models.CourierPrimaryKey)
 		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
+}
+
+func (c *courierRepo) UpdateStatus(ctx context.Context, req *models.UpdateCourierStatus) (int64, error) {
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		"UPDATE couriers SET status = $2, updated_at = NOW() WHERE id = $1", req.Id, req.Status,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() <= 0 {
+		return 0, nil
+	}
+
+	err = writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionStatusChanged, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// GetAvailable returns online couriers with the number of orders they are still carrying.
+func (c *courierRepo) GetAvailable(ctx context.Context) ([]*models.CourierLoad, error) {
+	var couriers []*models.CourierLoad
+
+	query := `
+		SELECT
+			co.id,
+			co.name,
+			co.phone,
+			COUNT(o.id)
+		FROM couriers AS co
+		LEFT JOIN orders AS o ON o.courier_id = co.id AND o.status IN ($2, $3, $4, $5)
+		WHERE co.status = $1
+		GROUP BY co.id, co.name, co.phone
+		ORDER BY COUNT(o.id), co.id
+	`
+
+	rows, err := c.db.Query(ctx, query,
+		models.CourierStatusOnline,
+		models.OrderStatusAccepted,
+		models.OrderStatusPreparing,
+		models.OrderStatusReady,
+		models.OrderStatusPickedUp,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id            sql.NullString
+			name          sql.NullString
+			phone         sql.NullString
+			active_orders sql.NullInt64
+		)
+
+		err = rows.Scan(
+			&id,
+			&name,
+			&phone,
+			&active_orders,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		couriers = append(couriers, &models.CourierLoad{
+			Id:           id.String,
+			Name:         name.String,
+			Phone:        phone.String,
+			ActiveOrders: int(active_orders.Int64),
+		})
+	}
+
+	return couriers, nil
+}
+
+// AddLocation stores a GPS ping as the courier's latest position and keeps a bounded history.
+func (c *courierRepo) AddLocation(ctx context.Context, req *models.CreateCourierLocation) (int64, error) {
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		"UPDATE couriers SET last_lat = $2, last_lng = $3, last_location_at = NOW() WHERE id = $1",
+		req.CourierId, req.Lat, req.Lng,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() <= 0 {
+		return 0, nil
+	}
+
+	_, err = tx.Exec(ctx,
+		"INSERT INTO courier_locations(id, courier_id, lat, lng) VALUES ($1, $2, $3, $4)",
+		uuid.New().String(), req.CourierId, req.Lat, req.Lng,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		DELETE FROM courier_locations
+		WHERE courier_id = $1 AND id NOT IN (
+			SELECT id
+			FROM courier_locations
+			WHERE courier_id = $1
+			ORDER BY recorded_at DESC
+			LIMIT $2
+		)
+	`
+
+	_, err = tx.Exec(ctx, query, req.CourierId, courierLocationHistory)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionLocationChanged, req.CourierId)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (c *courierRepo) GetLocations(ctx context.Context, req *models.GetListCourierLocationRequest) (resp *models.GetListCourierLocationResponse, err error) {
+	resp = &models.GetListCourierLocationResponse{}
+
+	var limit = courierLocationHistory
+
+	if req.Limit > 0 && req.Limit < limit {
+		limit = req.Limit
+	}
+
+	query := `
+		SELECT
+			courier_id,
+			lat,
+			lng,
+			TO_CHAR(recorded_at, 'YYYY-MM-DD HH24:MI:SS')
+		FROM courier_locations
+		WHERE courier_id = $1
+		ORDER BY recorded_at DESC
+		LIMIT $2
+	`
+
+	rows, err := c.db.Query(ctx, query, req.CourierId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			courier_id  sql.NullString
+			lat         sql.NullFloat64
+			lng         sql.NullFloat64
+			recorded_at sql.NullString
+		)
+
+		err = rows.Scan(
+			&courier_id,
+			&lat,
+			&lng,
+			&recorded_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Locations = append(resp.Locations, &models.CourierLocation{
+			CourierId:  courier_id.String,
+			Lat:        lat.Float64,
+			Lng:        lng.Float64,
+			RecordedAt: recorded_at.String,
+		})
+	}
+
+	resp.Count = len(resp.Locations)
+
+	return resp, nil
+}
+
+// GetNearby returns online couriers within req.Radius meters ordered by haversine distance.
+func (c *courierRepo) GetNearby(ctx context.Context, req *models.GetNearbyCourierRequest) (resp *models.GetNearbyCourierResponse, err error) {
+	resp = &models.GetNearbyCourierResponse{}
+
+	var (
+		query string
+		limit = " LIMIT 10"
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			phone,
+			last_lat,
+			last_lng,
+			distance
+		FROM (
+			SELECT
+				id,
+				name,
+				phone,
+				last_lat,
+				last_lng,
+				6371000 * 2 * ASIN(SQRT(
+					POWER(SIN(RADIANS(last_lat - $1) / 2), 2) +
+					COS(RADIANS($1)) * COS(RADIANS(last_lat)) * POWER(SIN(RADIANS(last_lng - $2) / 2), 2)
+				)) AS distance
+			FROM couriers
+			WHERE status = $4 AND last_lat IS NOT NULL AND last_lng IS NOT NULL
+		) AS nearby
+		WHERE distance <= $3
+		ORDER BY distance
+	`
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += limit
+
+	rows, err := c.db.Query(ctx, query, req.Lat, req.Lng, req.Radius, models.CourierStatusOnline)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id       sql.NullString
+			name     sql.NullString
+			phone    sql.NullString
+			lat      sql.NullFloat64
+			lng      sql.NullFloat64
+			distance sql.NullFloat64
+		)
+
+		err = rows.Scan(
+			&id,
+			&name,
+			&phone,
+			&lat,
+			&lng,
+			&distance,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Couriers = append(resp.Couriers, &models.NearbyCourier{
+			Id:       id.String,
+			Name:     name.String,
+			Phone:    phone.String,
+			Lat:      lat.Float64,
+			Lng:      lng.Float64,
+			Distance: distance.Float64,
+		})
+	}
+
+	resp.Count = len(resp.Couriers)
+
+	return resp, nil
+}
+
+func (c *courierRepo) CreateMessage(ctx context.Context, req *models.CreateCourierMessage) (*models.CourierMessage, error) {
+	var (
+		message    = models.CourierMessage{Id: uuid.New().String()}
+		created_at sql.NullString
+	)
+
+	query := `
+		INSERT INTO courier_messages(
+			id,
+			courier_id,
+			type,
+			payload
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING courier_id, type, payload::TEXT, TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS')
+	`
+
+	err := c.db.QueryRow(ctx, query, message.Id, req.CourierId, req.Type, req.Payload).Scan(
+		&message.CourierId,
+		&message.Type,
+		&message.Payload,
+		&created_at,
+	)
+	if isForeignKeyViolation(err) {
+		return nil, storage.ErrCourierNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	message.CreatedAt = created_at.String
+
+	return &message, nil
+}
+
+// GetPendingMessages returns the unacknowledged messages of a courier, oldest first.
+func (c *courierRepo) GetPendingMessages(ctx context.Context, req *models.CourierPrimaryKey) ([]*models.CourierMessage, error) {
+
+	query := `
+		SELECT
+			id,
+			courier_id,
+			type,
+			payload::TEXT,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM courier_messages
+		WHERE courier_id = $1 AND delivered_at IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := c.db.Query(ctx, query, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*models.CourierMessage
+
+	for rows.Next() {
+
+		var (
+			message    models.CourierMessage
+			created_at sql.NullString
+		)
+
+		err = rows.Scan(
+			&message.Id,
+			&message.CourierId,
+			&message.Type,
+			&message.Payload,
+			&created_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		message.CreatedAt = created_at.String
+
+		messages = append(messages, &message)
+	}
+
+	return messages, rows.Err()
+}
+
+// AckMessage marks a message as delivered; couriers can only acknowledge their own.
+func (c *courierRepo) AckMessage(ctx context.Context, req *models.CourierMessagePrimaryKey) (int64, error) {
+
+	result, err := c.db.Exec(ctx,
+		"UPDATE courier_messages SET delivered_at = NOW() WHERE id = $1 AND courier_id = $2 AND delivered_at IS NULL",
+		req.Id, req.CourierId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
 }
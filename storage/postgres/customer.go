@@ -3,12 +3,15 @@ package postgres
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/security"
+	"app/storage"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -33,20 +36,38 @@ func (c *customerRepo) Create(ctx context.Context, req *models.CreateCustomer) (
 			id, 
 			name,
 			phone,
+			language,
 			updated_at
 		)
-		VALUES (:id, :name, :phone, NOW())
+		VALUES (:id, :name, :phone, :language, NOW())
 	`
 
 	params := map[string]interface{}{
-		"id":    id,
-		"name":  req.Name,
-		"phone": req.Phone,
+		"id":       id,
+		"name":     req.Name,
+		"phone":    req.Phone,
+		"language": req.Language,
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	_, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "customers", models.OutboxAggregateCustomer, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -60,6 +81,7 @@ func (c *customerRepo) GetByID(ctx context.Context, req *models.CustomerPrimaryK
 		id         sql.NullString
 		name       sql.NullString
 		phone      sql.NullString
+		language   sql.NullString
 		created_at sql.NullString
 		updated_at sql.NullString
 	)
@@ -69,6 +91,7 @@ func (c *customerRepo) GetByID(ctx context.Context, req *models.CustomerPrimaryK
 			id,
 			name,
 			phone,
+			language,
 			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
 			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
 		FROM customers
@@ -79,6 +102,7 @@ func (c *customerRepo) GetByID(ctx context.Context, req *models.CustomerPrimaryK
 		&id,
 		&name,
 		&phone,
+		&language,
 		&created_at,
 		&updated_at,
 	)
@@ -91,6 +115,7 @@ func (c *customerRepo) GetByID(ctx context.Context, req *models.CustomerPrimaryK
 		Id:        id.String,
 		Name:      name.String,
 		Phone:     phone.String,
+		Language:  language.String,
 		CreatedAt: created_at.String,
 		UpdatedAt: updated_at.String,
 	}, nil
@@ -111,6 +136,7 @@ func (c *customerRepo) GetList(ctx context.Context, req *models.GetListCustomerR
 			id, 
 			name,
 			phone,
+			language,
 			created_at,
 			updated_at
 		FROM customers	
@@ -140,12 +166,13 @@ func (c *customerRepo) GetList(ctx context.Context, req *models.GetListCustomerR
 
 		var customer models.Customer
 
-		var id, name, phone, created_at, updated_at sql.NullString
+		var id, name, phone, language, created_at, updated_at sql.NullString
 
 		err = rows.Scan(
 			&id,
 			&name,
 			&phone,
+			&language,
 			&created_at,
 			&updated_at,
 		)
@@ -153,6 +180,7 @@ func (c *customerRepo) GetList(ctx context.Context, req *models.GetListCustomerR
 		customer.Id = id.String
 		customer.Name = name.String
 		customer.Phone = phone.String
+		customer.Language = language.String
 		customer.CreatedAt = created_at.String
 		customer.UpdatedAt = updated_at.String
 
@@ -180,6 +208,7 @@ func (c *customerRepo) Update(ctx context.Context, req *models.UpdateCustomer) (
 		SET 
 			name = :name,
 			phone = :phone,
+			language = :language,
 			updated_at = now()
 		WHERE id = :id
 	`
@@ -188,11 +217,28 @@ func (c *customerRepo) Update(ctx context.Context, req *models.UpdateCustomer) (
 		"id":            req.Id,
 		"name":          req.Name,
 		"phone":         req.Phone,
+		"language":      req.Language,
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "customers", models.OutboxAggregateCustomer, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -228,7 +274,23 @@ func (c *customerRepo) Patch(ctx context.Context, req *models.PatchRequest) (int
 
 	query, args := helper.ReplaceQueryParams(query, req.Fields)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "customers", models.OutboxAggregateCustomer, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -238,7 +300,18 @@ func (c *customerRepo) Patch(ctx context.Context, req *models.PatchRequest) (int
 
 func (c *customerRepo) Delete(ctx context.Context, req *models.CustomerPrimaryKey) error {
 
-	_, err := c.db.Exec(ctx,
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "customers", models.OutboxAggregateCustomer, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
 		"DELETE FROM customers WHERE id = $1", req.Id,
 	)
 
@@ -246,5 +319,488 @@ func (c *customerRepo) Delete(ctx context.Context, req *models.CustomerPrimaryKe
 		return err
 	}
 
+	return tx.Commit(ctx)
+}
+
+func (c *customerRepo) CreateAddress(ctx context.Context, req *models.CreateCustomerAddress) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+		count int
+	)
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM customer_addresses WHERE customer_id = $1", req.CustomerId).Scan(&count)
+	if err != nil {
+		return "", err
+	}
+
+	// the first address a customer saves becomes their default
+	isDefault := req.IsDefault || count == 0
+
+	if isDefault {
+		err = c.clearDefaultAddress(ctx, tx, req.CustomerId)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	query = `
+		INSERT INTO customer_addresses(
+			id,
+			customer_id,
+			label,
+			street,
+			apartment,
+			lat,
+			lng,
+			notes,
+			is_default,
+			updated_at
+		)
+		VALUES (:id, :customer_id, :label, :street, :apartment, :lat, :lng, :notes, :is_default, NOW())
+	`
+
+	params := map[string]interface{}{
+		"id":          id,
+		"customer_id": req.CustomerId,
+		"label":       req.Label,
+		"street":      req.Street,
+		"apartment":   req.Apartment,
+		"lat":         req.Lat,
+		"lng":         req.Lng,
+		"notes":       req.Notes,
+		"is_default":  isDefault,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "customer_addresses", models.OutboxAggregateAddress, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (c *customerRepo) GetAddress(ctx context.Context, req *models.CustomerAddressPrimaryKey) (*models.CustomerAddress, error) {
+	var (
+		query       string
+		id          sql.NullString
+		customer_id sql.NullString
+		label       sql.NullString
+		street      sql.NullString
+		apartment   sql.NullString
+		lat         sql.NullFloat64
+		lng         sql.NullFloat64
+		notes       sql.NullString
+		is_default  sql.NullBool
+		created_at  sql.NullString
+		updated_at  sql.NullString
+	)
+
+	query = `
+		SELECT
+			id,
+			customer_id,
+			label,
+			street,
+			apartment,
+			lat,
+			lng,
+			notes,
+			is_default,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM customer_addresses
+		WHERE id = $1 AND customer_id = $2
+	`
+
+	err := c.db.QueryRow(ctx, query, req.Id, req.CustomerId).Scan(
+		&id,
+		&customer_id,
+		&label,
+		&street,
+		&apartment,
+		&lat,
+		&lng,
+		&notes,
+		&is_default,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CustomerAddress{
+		Id:         id.String,
+		CustomerId: customer_id.String,
+		Label:      label.String,
+		Street:     street.String,
+		Apartment:  apartment.String,
+		Lat:        lat.Float64,
+		Lng:        lng.Float64,
+		Notes:      notes.String,
+		IsDefault:  is_default.Bool,
+		CreatedAt:  created_at.String,
+		UpdatedAt:  updated_at.String,
+	}, nil
+}
+
+func (c *customerRepo) GetAddresses(ctx context.Context, req *models.GetListCustomerAddressRequest) (resp *models.GetListCustomerAddressResponse, err error) {
+	resp = &models.GetListCustomerAddressResponse{}
+
+	query := `
+		SELECT
+			id,
+			customer_id,
+			label,
+			street,
+			apartment,
+			lat,
+			lng,
+			notes,
+			is_default,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM customer_addresses
+		WHERE customer_id = $1
+		ORDER BY is_default DESC, created_at DESC
+	`
+
+	rows, err := c.db.Query(ctx, query, req.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var (
+			id          sql.NullString
+			customer_id sql.NullString
+			label       sql.NullString
+			street      sql.NullString
+			apartment   sql.NullString
+			lat         sql.NullFloat64
+			lng         sql.NullFloat64
+			notes       sql.NullString
+			is_default  sql.NullBool
+			created_at  sql.NullString
+			updated_at  sql.NullString
+		)
+
+		err = rows.Scan(
+			&id,
+			&customer_id,
+			&label,
+			&street,
+			&apartment,
+			&lat,
+			&lng,
+			&notes,
+			&is_default,
+			&created_at,
+			&updated_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Addresses = append(resp.Addresses, &models.CustomerAddress{
+			Id:         id.String,
+			CustomerId: customer_id.String,
+			Label:      label.String,
+			Street:     street.String,
+			Apartment:  apartment.String,
+			Lat:        lat.Float64,
+			Lng:        lng.Float64,
+			Notes:      notes.String,
+			IsDefault:  is_default.Bool,
+			CreatedAt:  created_at.String,
+			UpdatedAt:  updated_at.String,
+		})
+	}
+
+	resp.Count = len(resp.Addresses)
+
+	return resp, nil
+}
+
+func (c *customerRepo) UpdateAddress(ctx context.Context, req *models.UpdateCustomerAddress) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if req.IsDefault {
+		err = c.clearDefaultAddress(ctx, tx, req.CustomerId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	query = `
+		UPDATE
+			customer_addresses
+		SET
+			label = :label,
+			street = :street,
+			apartment = :apartment,
+			lat = :lat,
+			lng = :lng,
+			notes = :notes,
+			is_default = :is_default,
+			updated_at = now()
+		WHERE id = :id AND customer_id = :customer_id
+	`
+
+	params = map[string]interface{}{
+		"id":          req.Id,
+		"customer_id": req.CustomerId,
+		"label":       req.Label,
+		"street":      req.Street,
+		"apartment":   req.Apartment,
+		"lat":         req.Lat,
+		"lng":         req.Lng,
+		"notes":       req.Notes,
+		"is_default":  req.IsDefault,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() <= 0 {
+		return 0, nil
+	}
+
+	err = writeOutbox(ctx, tx, "customer_addresses", models.OutboxAggregateAddress, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (c *customerRepo) DeleteAddress(ctx context.Context, req *models.CustomerAddressPrimaryKey) (int64, error) {
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// the event carries the row as it was, so it is written before the delete;
+	// the rollback drops it again if the address is not the customer's
+	err = writeOutbox(ctx, tx, "customer_addresses", models.OutboxAggregateAddress, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(ctx,
+		"DELETE FROM customer_addresses WHERE id = $1 AND customer_id = $2", req.Id, req.CustomerId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() <= 0 {
+		return 0, nil
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// clearDefaultAddress drops the default flag from the customer's current default
+// address so a new one can take its place.
+func (c *customerRepo) clearDefaultAddress(ctx context.Context, tx pgx.Tx, customerId string) error {
+
+	_, err := tx.Exec(ctx,
+		"UPDATE customer_addresses SET is_default = FALSE, updated_at = now() WHERE customer_id = $1 AND is_default", customerId,
+	)
+
+	return err
+}
+
+// CreateOTP replaces the pending code of a phone. Wrong guesses carry over to
+// the new code, so resending does not buy more attempts; they start over only
+// once a lockout has run out.
+func (c *customerRepo) CreateOTP(ctx context.Context, req *models.CreateCustomerOTP) error {
+
+	query := `
+		INSERT INTO customer_otps(
+			phone,
+			code_hash,
+			attempts,
+			expires_at,
+			created_at
+		)
+		VALUES (:phone, :code_hash, 0, NOW() + make_interval(secs => :expires_in), NOW())
+		ON CONFLICT (phone) DO UPDATE SET
+			code_hash = EXCLUDED.code_hash,
+			attempts = CASE WHEN customer_otps.locked_until IS NULL THEN customer_otps.attempts ELSE 0 END,
+			locked_until = NULL,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		WHERE customer_otps.created_at < NOW() - make_interval(secs => :resend_after)
+			AND (customer_otps.locked_until IS NULL OR customer_otps.locked_until <= NOW())
+	`
+
+	params := map[string]interface{}{
+		"phone":        req.Phone,
+		"code_hash":    req.CodeHash,
+		"expires_in":   req.ExpiresIn,
+		"resend_after": req.ResendAfter,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	result, err := c.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() <= 0 {
+		var locked bool
+
+		err = c.db.QueryRow(ctx,
+			"SELECT COALESCE(locked_until > NOW(), FALSE) FROM customer_otps WHERE phone = $1", req.Phone,
+		).Scan(&locked)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if locked {
+			return storage.ErrOTPAttemptsExceeded
+		}
+
+		return storage.ErrOTPTooSoon
+	}
+
 	return nil
 }
+
+// CheckOTP consumes the pending code of a phone and returns the id of the
+// customer with that phone, creating the customer on their first login.
+func (c *customerRepo) CheckOTP(ctx context.Context, req *models.CheckCustomerOTP) (string, error) {
+	var (
+		codeHash string
+		attempts int
+		expired  bool
+		locked   bool
+		id       string
+	)
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		"SELECT code_hash, attempts, expires_at < NOW(), locked_until IS NOT NULL FROM customer_otps WHERE phone = $1 FOR UPDATE",
+		req.Phone,
+	).Scan(&codeHash, &attempts, &expired, &locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrOTPInvalid
+	} else if err != nil {
+		return "", err
+	}
+
+	if locked || attempts >= req.MaxAttempts {
+		return "", storage.ErrOTPAttemptsExceeded
+	}
+
+	if expired {
+		return "", storage.ErrOTPInvalid
+	}
+
+	if !security.ComparePassword(codeHash, req.Code) {
+		_, err = tx.Exec(ctx, `
+			UPDATE customer_otps SET
+				attempts = attempts + 1,
+				locked_until = CASE WHEN attempts + 1 >= $2 THEN NOW() + make_interval(secs => $3) END
+			WHERE phone = $1`,
+			req.Phone, req.MaxAttempts, req.LockoutFor,
+		)
+		if err != nil {
+			return "", err
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return "", storage.ErrOTPInvalid
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM customer_otps WHERE phone = $1", req.Phone)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.QueryRow(ctx,
+		"SELECT id FROM customers WHERE phone = $1 ORDER BY created_at LIMIT 1", req.Phone,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		id = uuid.New().String()
+
+		_, err = tx.Exec(ctx,
+			"INSERT INTO customers(id, name, phone, updated_at) VALUES ($1, '', $2, NOW())", id, req.Phone,
+		)
+		if err != nil {
+			return "", err
+		}
+
+		err = writeOutbox(ctx, tx, "customers", models.OutboxAggregateCustomer, models.OutboxActionCreated, id)
+		if err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
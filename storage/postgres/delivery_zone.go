@@ -0,0 +1,392 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/money"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type deliveryZoneRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDeliveryZoneRepo(db *pgxpool.Pool) *deliveryZoneRepo {
+	return &deliveryZoneRepo{
+		db: db,
+	}
+}
+
+func (d *deliveryZoneRepo) Create(ctx context.Context, req *models.CreateDeliveryZone) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	polygon, err := json.Marshal(req.Polygon)
+	if err != nil {
+		return "", err
+	}
+
+	query = `
+		INSERT INTO delivery_zones(
+			id,
+			name,
+			polygon,
+			delivery_fee,
+			min_order_amount,
+			currency,
+			active,
+			updated_at
+		)
+		VALUES (:id, :name, :polygon, :delivery_fee, :min_order_amount, :currency, :active, NOW())
+	`
+
+	params := map[string]interface{}{
+		"id":               id,
+		"name":             req.Name,
+		"polygon":          polygon,
+		"delivery_fee":     req.DeliveryFee.Amount,
+		"min_order_amount": req.MinOrderAmount.Amount,
+		"currency":         req.DeliveryFee.Currency,
+		"active":           req.Active,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "delivery_zones", models.OutboxAggregateDeliveryZone, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (d *deliveryZoneRepo) GetByID(ctx context.Context, req *models.DeliveryZonePrimaryKey) (*models.DeliveryZone, error) {
+	var (
+		query            string
+		id               sql.NullString
+		name             sql.NullString
+		polygon          []byte
+		delivery_fee     sql.NullInt64
+		min_order_amount sql.NullInt64
+		currency         sql.NullString
+		active           sql.NullBool
+		created_at       sql.NullString
+		updated_at       sql.NullString
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			polygon,
+			delivery_fee,
+			min_order_amount,
+			currency,
+			active,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM delivery_zones
+		WHERE id = $1
+	`
+
+	err := d.db.QueryRow(ctx, query, req.Id).Scan(
+		&id,
+		&name,
+		&polygon,
+		&delivery_fee,
+		&min_order_amount,
+		&currency,
+		&active,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := models.DeliveryZone{
+		Id:             id.String,
+		Name:           name.String,
+		DeliveryFee:    money.New(delivery_fee.Int64, currency.String),
+		MinOrderAmount: money.New(min_order_amount.Int64, currency.String),
+		Active:         active.Bool,
+		CreatedAt:      created_at.String,
+		UpdatedAt:      updated_at.String,
+	}
+
+	err = json.Unmarshal(polygon, &zone.Polygon)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zone, nil
+}
+
+func (d *deliveryZoneRepo) GetList(ctx context.Context, req *models.GetListDeliveryZoneRequest) (resp *models.GetListDeliveryZoneResponse, err error) {
+	resp = &models.GetListDeliveryZoneResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			polygon,
+			delivery_fee,
+			min_order_amount,
+			currency,
+			active,
+			created_at,
+			updated_at
+		FROM delivery_zones
+	`
+
+	if len(req.Search) > 0 {
+		filter += " AND name ILIKE '%' || :search || '%' "
+		params["search"] = req.Search
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := d.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var zone models.DeliveryZone
+
+		var (
+			id, name, currency, created_at, updated_at sql.NullString
+			delivery_fee, min_order_amount             sql.NullInt64
+			active                                     sql.NullBool
+			polygon                                    []byte
+		)
+
+		err = rows.Scan(
+			&id,
+			&name,
+			&polygon,
+			&delivery_fee,
+			&min_order_amount,
+			&currency,
+			&active,
+			&created_at,
+			&updated_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		zone.Id = id.String
+		zone.Name = name.String
+		zone.DeliveryFee = money.New(delivery_fee.Int64, currency.String)
+		zone.MinOrderAmount = money.New(min_order_amount.Int64, currency.String)
+		zone.Active = active.Bool
+		zone.CreatedAt = created_at.String
+		zone.UpdatedAt = updated_at.String
+
+		err = json.Unmarshal(polygon, &zone.Polygon)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.DeliveryZones = append(resp.DeliveryZones, &zone)
+	}
+
+	resp.Count = len(resp.DeliveryZones)
+
+	return resp, nil
+}
+
+func (d *deliveryZoneRepo) Update(ctx context.Context, req *models.UpdateDeliveryZone) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	polygon, err := json.Marshal(req.Polygon)
+	if err != nil {
+		return 0, err
+	}
+
+	query = `
+		UPDATE
+			delivery_zones
+		SET
+			name = :name,
+			polygon = :polygon,
+			delivery_fee = :delivery_fee,
+			min_order_amount = :min_order_amount,
+			currency = :currency,
+			active = :active,
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":               req.Id,
+		"name":             req.Name,
+		"polygon":          polygon,
+		"delivery_fee":     req.DeliveryFee.Amount,
+		"min_order_amount": req.MinOrderAmount.Amount,
+		"currency":         req.DeliveryFee.Currency,
+		"active":           req.Active,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "delivery_zones", models.OutboxAggregateDeliveryZone, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (d *deliveryZoneRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64, error) {
+
+	var (
+		query string
+		set   string
+	)
+
+	if len(req.Fields) <= 0 {
+		return 0, errors.New("no fields")
+	}
+
+	err := flattenMoneyFields(req.Fields, "delivery_fee", "min_order_amount")
+	if err != nil {
+		return 0, err
+	}
+
+	for key := range req.Fields {
+		set += fmt.Sprintf(" %s = :%s, ", key, key)
+	}
+
+	if polygon, ok := req.Fields["polygon"]; ok {
+		body, err := json.Marshal(polygon)
+		if err != nil {
+			return 0, err
+		}
+		req.Fields["polygon"] = body
+	}
+
+	query = `
+		UPDATE
+			delivery_zones
+		SET
+	` + set + ` updated_at = now()
+		WHERE id = :id
+	`
+
+	req.Fields["id"] = req.ID
+
+	query, args := helper.ReplaceQueryParams(query, req.Fields)
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "delivery_zones", models.OutboxAggregateDeliveryZone, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (d *deliveryZoneRepo) Delete(ctx context.Context, req *models.DeliveryZonePrimaryKey) error {
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "delivery_zones", models.OutboxAggregateDeliveryZone, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM delivery_zones WHERE id = $1", req.Id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
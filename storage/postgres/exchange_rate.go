@@ -0,0 +1,337 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/money"
+	"app/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type exchangeRateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepo(db *pgxpool.Pool) *exchangeRateRepo {
+	return &exchangeRateRepo{
+		db: db,
+	}
+}
+
+func (e *exchangeRateRepo) Create(ctx context.Context, req *models.CreateExchangeRate) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	query = `
+		INSERT INTO exchange_rates(
+			id,
+			base_currency,
+			quote_currency,
+			rate,
+			effective_from,
+			updated_at
+		)
+		VALUES (:id, :base_currency, :quote_currency, :rate, COALESCE(:effective_from::TIMESTAMP, NOW()), NOW())
+	`
+
+	params := map[string]interface{}{
+		"id":             id,
+		"base_currency":  req.BaseCurrency,
+		"quote_currency": req.QuoteCurrency,
+		"rate":           req.Rate,
+		"effective_from": helper.NewNullString(req.EffectiveFrom),
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return "", storage.ErrExchangeRateExists
+	} else if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "exchange_rates", models.OutboxAggregateExchangeRate, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (e *exchangeRateRepo) GetByID(ctx context.Context, req *models.ExchangeRatePrimaryKey) (*models.ExchangeRate, error) {
+
+	query := `
+		SELECT
+			id,
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			TO_CHAR(effective_from, 'YYYY-MM-DD HH24:MI:SS'),
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM exchange_rates
+		WHERE id = $1
+	`
+
+	return scanExchangeRate(e.db.QueryRow(ctx, query, req.Id))
+}
+
+func (e *exchangeRateRepo) GetList(ctx context.Context, req *models.GetListExchangeRateRequest) (resp *models.GetListExchangeRateResponse, err error) {
+	resp = &models.GetListExchangeRateResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = make(map[string]interface{})
+	)
+
+	query = `
+		SELECT
+			id,
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			TO_CHAR(effective_from, 'YYYY-MM-DD HH24:MI:SS'),
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM exchange_rates
+	`
+
+	if len(req.Currency) > 0 {
+		filter += " AND (base_currency = :currency OR quote_currency = :currency) "
+		params["currency"] = req.Currency
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + " ORDER BY base_currency, quote_currency, effective_from DESC " + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := e.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.ExchangeRates = append(resp.ExchangeRates, rate)
+	}
+
+	resp.Count = len(resp.ExchangeRates)
+
+	return resp, rows.Err()
+}
+
+// Update changes a rate. Orders already placed keep the rate they
+// snapshotted.
+func (e *exchangeRateRepo) Update(ctx context.Context, req *models.UpdateExchangeRate) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	query = `
+		UPDATE
+			exchange_rates
+		SET
+			base_currency = :base_currency,
+			quote_currency = :quote_currency,
+			rate = :rate,
+			effective_from = COALESCE(:effective_from::TIMESTAMP, effective_from),
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":             req.Id,
+		"base_currency":  req.BaseCurrency,
+		"quote_currency": req.QuoteCurrency,
+		"rate":           req.Rate,
+		"effective_from": helper.NewNullString(req.EffectiveFrom),
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return 0, storage.ErrExchangeRateExists
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "exchange_rates", models.OutboxAggregateExchangeRate, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (e *exchangeRateRepo) Delete(ctx context.Context, req *models.ExchangeRatePrimaryKey) error {
+
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "exchange_rates", models.OutboxAggregateExchangeRate, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM exchange_rates WHERE id = $1", req.Id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type exchangeRateScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExchangeRate(row exchangeRateScanner) (*models.ExchangeRate, error) {
+
+	var (
+		rate                   models.ExchangeRate
+		created_at, updated_at sql.NullString
+	)
+
+	err := row.Scan(
+		&rate.Id,
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.EffectiveFrom,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rate.CreatedAt = created_at.String
+	rate.UpdatedAt = updated_at.String
+
+	return &rate, nil
+}
+
+// orderExchangeRate is the rate an order converts from base into its own
+// currency with. The first lookup takes the rate in effect now, directly or
+// as the inverse of the opposite pair rounded to 12 decimals, and snapshots it
+// on the order; later lookups for the same order reuse the snapshot.
+func orderExchangeRate(ctx context.Context, tx pgx.Tx, orderId, base, quote string) (*big.Rat, error) {
+
+	if base == quote {
+		return big.NewRat(1, 1), nil
+	}
+
+	var rate string
+
+	err := tx.QueryRow(ctx,
+		"SELECT rate::TEXT FROM order_exchange_rates WHERE order_id = $1 AND base_currency = $2 AND quote_currency = $3",
+		orderId, base, quote,
+	).Scan(&rate)
+	if err == nil {
+		return money.ParseRate(rate)
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	var (
+		rateBase      string
+		effectiveFrom string
+	)
+
+	err = tx.QueryRow(ctx, `
+		SELECT
+			rate::TEXT,
+			base_currency,
+			TO_CHAR(effective_from, 'YYYY-MM-DD HH24:MI:SS')
+		FROM exchange_rates
+		WHERE effective_from <= NOW()
+			AND ((base_currency = $1 AND quote_currency = $2) OR (base_currency = $2 AND quote_currency = $1))
+		ORDER BY effective_from DESC, base_currency = $1 DESC
+		LIMIT 1
+	`, base, quote).Scan(&rate, &rateBase, &effectiveFrom)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s to %s", storage.ErrExchangeRateNotFound, base, quote)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if rateBase != base {
+		inverse, err := money.ParseRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		rate = inverse.Inv(inverse).FloatString(12)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_exchange_rates(
+			order_id,
+			base_currency,
+			quote_currency,
+			rate,
+			effective_from
+		) VALUES ($1, $2, $3, $4, $5)
+	`, orderId, base, quote, rate, effectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return money.ParseRate(rate)
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"app/pkg/money"
+	"errors"
+	"fmt"
+)
+
+// flattenMoneyFields rewrites money values of a patch, sent as
+// {"amount": ..., "currency": ...}, into their minor-unit column and the
+// currency column of the table. All of them must be in the same currency.
+func flattenMoneyFields(fields map[string]interface{}, columns ...string) error {
+
+	var currency string
+
+	for _, column := range columns {
+
+		value, ok := fields[column]
+		if !ok {
+			continue
+		}
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an object with amount and currency", column)
+		}
+
+		amount, ok := object["amount"].(float64)
+		if !ok || amount != float64(int64(amount)) {
+			return fmt.Errorf("%s amount must be a whole number of minor units", column)
+		}
+
+		code, _ := object["currency"].(string)
+		m := money.New(int64(amount), code)
+
+		if !money.IsValidCurrency(m.Currency) {
+			return money.ErrInvalidCurrency
+		}
+
+		if len(currency) > 0 && currency != m.Currency {
+			return errors.New("all amounts must be in the same currency")
+		}
+		currency = m.Currency
+
+		fields[column] = m.Amount
+	}
+
+	if len(currency) > 0 {
+		fields["currency"] = currency
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type notificationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepo(db *pgxpool.Pool) *notificationRepo {
+	return &notificationRepo{
+		db: db,
+	}
+}
+
+func (n *notificationRepo) Create(ctx context.Context, req *models.CreateNotification) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	query = `
+		INSERT INTO notifications(
+			id,
+			channel,
+			recipient,
+			event,
+			language,
+			subject,
+			body
+		)
+		VALUES (:id, :channel, :recipient, :event, :language, :subject, :body)
+	`
+
+	params := map[string]interface{}{
+		"id":        id,
+		"channel":   req.Channel,
+		"recipient": req.Recipient,
+		"event":     req.Event,
+		"language":  req.Language,
+		"subject":   helper.NewNullString(req.Subject),
+		"body":      req.Body,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	_, err := n.db.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// ClaimPending leases due notifications so that concurrent workers never pick
+// the same one; a notification whose worker died becomes due again when the
+// lease runs out.
+func (n *notificationRepo) ClaimPending(ctx context.Context, req *models.ClaimNotifications) ([]*models.Notification, error) {
+
+	query := `
+		UPDATE notifications
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM notifications
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING
+			id,
+			channel,
+			recipient,
+			event,
+			language,
+			subject,
+			body,
+			status,
+			attempts,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS')
+	`
+
+	rows, err := n.db.Query(ctx, query, req.Limit, req.Lease)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+
+	for rows.Next() {
+
+		var (
+			notification models.Notification
+			subject      sql.NullString
+			created_at   sql.NullString
+		)
+
+		err = rows.Scan(
+			&notification.Id,
+			&notification.Channel,
+			&notification.Recipient,
+			&notification.Event,
+			&notification.Language,
+			&subject,
+			&notification.Body,
+			&notification.Status,
+			&notification.Attempts,
+			&created_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		notification.Subject = subject.String
+		notification.CreatedAt = created_at.String
+
+		notifications = append(notifications, &notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (n *notificationRepo) MarkSent(ctx context.Context, req *models.NotificationPrimaryKey) error {
+
+	_, err := n.db.Exec(ctx,
+		"UPDATE notifications SET status = $2, attempts = attempts + 1, last_error = NULL, sent_at = NOW() WHERE id = $1",
+		req.Id, models.NotificationStatusSent,
+	)
+
+	return err
+}
+
+func (n *notificationRepo) MarkFailed(ctx context.Context, req *models.FailNotification) error {
+
+	status := models.NotificationStatusPending
+	if req.Dead {
+		status = models.NotificationStatusFailed
+	}
+
+	_, err := n.db.Exec(ctx, `
+		UPDATE notifications
+		SET
+			status = $2,
+			attempts = attempts + 1,
+			last_error = $3,
+			next_attempt_at = NOW() + make_interval(secs => $4)
+		WHERE id = $1
+	`, req.Id, status, req.Error, req.RetryAfter)
+
+	return err
+}
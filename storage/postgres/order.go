@@ -3,22 +3,57 @@ package postgres
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/money"
+	"app/pkg/promotion"
+	"app/pkg/tax"
+	"app/storage"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// orderStatusTransitions lists the statuses an order may move to from each status.
+var orderStatusTransitions = map[string][]string{
+	models.OrderStatusNew:       {models.OrderStatusAccepted, models.OrderStatusCancelled},
+	models.OrderStatusAccepted:  {models.OrderStatusPreparing, models.OrderStatusCancelled},
+	models.OrderStatusPreparing: {models.OrderStatusReady, models.OrderStatusCancelled},
+	models.OrderStatusReady:     {models.OrderStatusPickedUp, models.OrderStatusCancelled},
+	models.OrderStatusPickedUp:  {models.OrderStatusDelivered, models.OrderStatusReturned},
+	models.OrderStatusDelivered: {models.OrderStatusReturned},
+	models.OrderStatusCancelled: {},
+	models.OrderStatusReturned:  {},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range orderStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
 type orderRepo struct {
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	taxInclusive bool
 }
 
-func NewOrderRepo(db *pgxpool.Pool) *orderRepo {
+// NewOrderRepo creates the order repo; taxInclusive is the pricing mode given to
+// new orders, existing orders keep the mode they were placed with.
+func NewOrderRepo(db *pgxpool.Pool, taxInclusive bool) *orderRepo {
 	return &orderRepo{
-		db: db,
+		db:           db,
+		taxInclusive: taxInclusive,
 	}
 }
 
@@ -28,334 +63,2421 @@ func (o *orderRepo) Create(ctx context.Context, req *models.CreateOrder) (string
 		id    = uuid.New().String()
 	)
 
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	address, err := o.resolveAddress(ctx, tx, req)
+	if err != nil {
+		return "", err
+	}
+
+	zone, err := o.findDeliveryZone(ctx, tx, models.Point{Lat: address.Lat, Lng: address.Lng})
+	if err != nil {
+		return "", err
+	}
+
+	warehouseId, err := o.pickWarehouse(ctx, tx, req.Items)
+	if err != nil {
+		return "", err
+	}
+
+	currency := req.Currency
+	if len(currency) <= 0 {
+		currency = money.DefaultCurrency
+	}
+
 	query = `
 		INSERT INTO orders(
 			id,
 			name,
-			quantity,
 			user_id,
 			customer_id,
-			product_id,
-			courier_id,
+			warehouse_id,
+			delivery_address_id,
+			delivery_label,
+			delivery_street,
+			delivery_apartment,
+			delivery_notes,
+			delivery_lat,
+			delivery_lng,
+			delivery_zone_id,
+			delivery_fee,
+			currency,
+			promo_code,
+			tax_inclusive,
 			updated_at
 		) VALUES
-		(:id, :name, :quantity, :user_id, :customer_id, :product_id, :courier_id, NOW())
+		(
+			:id, :name, :user_id, :customer_id, :warehouse_id,
+			:delivery_address_id, :delivery_label, :delivery_street, :delivery_apartment, :delivery_notes,
+			:delivery_lat, :delivery_lng, :delivery_zone_id, :delivery_fee, :currency, :promo_code, :tax_inclusive, NOW()
+		)
 	`
 
 	params := map[string]interface{}{
-		"id":          id,
-		"name":        req.Name,
-		"quantity":    req.Quantity,
-		"user_id":     helper.NewNullString(req.UserId),
-		"customer_id": helper.NewNullString(req.CustomerId),
-		"product_id":  helper.NewNullString(req.ProductId),
-		"courier_id":  helper.NewNullString(req.CourierId),
+		"id":                  id,
+		"name":                req.Name,
+		"user_id":             helper.NewNullString(req.UserId),
+		"customer_id":         helper.NewNullString(req.CustomerId),
+		"warehouse_id":        warehouseId,
+		"delivery_address_id": helper.NewNullString(address.AddressId),
+		"delivery_label":      address.Label,
+		"delivery_street":     address.Street,
+		"delivery_apartment":  address.Apartment,
+		"delivery_notes":      address.Notes,
+		"delivery_lat":        address.Lat,
+		"delivery_lng":        address.Lng,
+		"delivery_zone_id":    helper.NewNullString(zone.Id),
+		"delivery_fee":        0,
+		"currency":            currency,
+		"promo_code":          helper.NewNullString(req.PromoCode),
+		"tax_inclusive":       o.taxInclusive,
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	_, err := o.db.Exec(ctx, query, args...)
+	_, err = tx.Exec(ctx, query, args...)
 	if err != nil {
 		return "", err
 	}
 
-	return id, nil
-}
-
-func (o *orderRepo) GetByID(ctx context.Context, req *models.OrderPrimaryKey) (*models.Order, error) {
-	var (
-		query          string
-		id             sql.NullString
-		name           sql.NullString
-		product_price  sql.NullFloat64
-		total_price    sql.NullFloat64
-		quantity       sql.NullInt32
-		user_name      sql.NullString
-		user_phone     sql.NullString
-		product_name   sql.NullString
-		customer_name  sql.NullString
-		customer_phone sql.NullString
-		courier_name   sql.NullString
-		courier_phone  sql.NullString
-		created_at     sql.NullString
-		updated_at     sql.NullString
-	)
+	if len(zone.Id) > 0 {
+		rate, err := orderExchangeRate(ctx, tx, id, zone.DeliveryFee.Currency, currency)
+		if err != nil {
+			return "", err
+		}
 
-	query = `
-		SELECT 
-			o.id,
-			o.name,
-			o.price,
-			total_price,
-			quantity,
-			u.name,
-			u.phone,
-			p.name,
-			c.name,
-			c.phone,
-			co.name,
-			co.phone,
-			o.created_at,
-			o.updated_at
-		FROM orders AS o
-		LEFT JOIN users AS u ON o.user_id = u.id
-		LEFT JOIN customers AS c ON o.customer_id = c.id
-		LEFT JOIN products AS p ON o.product_id = p.id
-		LEFT JOIN couriers AS co ON o.courier_id = co.id
-		WHERE o.id = $1
-	`
+		_, err = tx.Exec(ctx, "UPDATE orders SET delivery_fee = $2 WHERE id = $1", id, zone.DeliveryFee.Convert(currency, rate).Amount)
+		if err != nil {
+			return "", err
+		}
+	}
 
-	err := o.db.QueryRow(ctx, query, req.Id).Scan(
-		&id,
-		&name,
-		&product_price,
-		&total_price,
-		&quantity,
-		&user_name,
-		&user_phone,
-		&product_name,
-		&customer_name,
-		&customer_phone,
-		&courier_name,
-		&courier_phone,
-		&created_at,
-		&updated_at,
-	)
+	err = o.insertItems(ctx, tx, id, warehouseId, req.Items)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	var user models.ReturnUser
-	user.Name = user_name.String
-	user.Phone = user_phone.String
-
-	var product models.ReturnProduct
-	product.Name = product_name.String
-	product.Price = product_price.Float64
-
-	var customer models.ReturnCustomer
-	customer.Name = customer_name.String
-	customer.Phone = customer_phone.String
+	err = o.applyPromotions(ctx, tx, id)
+	if err != nil {
+		return "", err
+	}
 
-	return &models.Order{
-		Id:         id.String,
-		Name:       name.String,
-		Price:      product_price.Float64,
-		TotalPrice: total_price.Float64,
-		Quantity:   quantity.Int32,
-		User:       user,
-		Customer:   customer,
-		Product:    product,
-		CreatedAt:  created_at.String,
-		UpdatedAt:  updated_at.String,
-	}, nil
-}
+	err = o.calculateTotal(ctx, tx, id)
+	if err != nil {
+		return "", err
+	}
 
-func (o *orderRepo) GetList(ctx context.Context, req *models.GetListOrderRequest) (resp *models.GetListOrderResponse, err error) {
-	resp = &models.GetListOrderResponse{}
+	err = o.checkMinimumOrder(ctx, tx, id)
+	if err != nil {
+		return "", err
+	}
 
-	var (
-		query  string
-		filter = " WHERE TRUE "
-		offset = " OFFSET 0"
-		limit  = " LIMIT 10"
-	)
+	event := &models.OrderEvent{
+		OrderId:  id,
+		Event:    models.OrderEventCreated,
+		ToStatus: models.OrderStatusNew,
+	}
 
-	query = `
-		SELECT 
-			o.id,
-			o.name,
-			o.price,
-			total_price,
-			quantity,
-			u.name,
-			u.phone,
-			p.name,
-			c.name,
-			c.phone,
-			co.name,
-			co.phone,
-			o.created_at,
-			o.updated_at
-		FROM orders AS o
-		LEFT JOIN users AS u ON o.user_id = u.id
-		LEFT JOIN customers AS c ON o.customer_id = c.id
-		LEFT JOIN products AS p ON o.product_id = p.id
-		LEFT JOIN couriers AS co ON o.courier_id = co.id
-	`
+	if len(req.UserId) > 0 {
+		event.ActorType, event.ActorId = models.ActorTypeUser, req.UserId
+	} else if len(req.CustomerId) > 0 {
+		event.ActorType, event.ActorId = models.ActorTypeCustomer, req.CustomerId
+	}
 
-	if len(req.Search) > 0 {
-		filter += " AND o.name ILIKE '%' || '" + req.Search + "' || '%' "
+	err = o.insertEvent(ctx, tx, event)
+	if err != nil {
+		return "", err
 	}
 
-	if req.Offset > 0 {
-		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
 	}
 
-	if req.Limit > 0 {
-		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	query += filter + offset + limit
+	return id, nil
+}
+
+func (o *orderRepo) insertEvent(ctx context.Context, tx pgx.Tx, req *models.OrderEvent) error {
+
+	query := `
+		INSERT INTO order_events(
+			id,
+			order_id,
+			actor_type,
+			actor_id,
+			event,
+			from_status,
+			to_status,
+			note
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
 
-	rows, err := o.db.Query(ctx, query)
+	_, err := tx.Exec(ctx, query,
+		uuid.New().String(),
+		req.OrderId,
+		helper.NewNullString(req.ActorType),
+		helper.NewNullString(req.ActorId),
+		req.Event,
+		helper.NewNullString(req.FromStatus),
+		helper.NewNullString(req.ToStatus),
+		helper.NewNullString(req.Note),
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
+	return nil
+}
 
-		var order models.Order
-		var user models.ReturnUser
-		var customer models.ReturnCustomer
-		var courier models.ReturnCourier
-		var product models.ReturnProduct
+// insertItems snapshots the current product price, name and category onto every line
+// so that later catalog changes never alter a placed order. Prices in another currency
+// are converted at the rate snapshotted on the order.
+func (o *orderRepo) insertItems(ctx context.Context, tx pgx.Tx, orderId, warehouseId string, items []*models.CreateOrderItem) error {
 
-		var (
-			id             sql.NullString
-			name           sql.NullString
-			user_name      sql.NullString
-			user_phone     sql.NullString
-			courier_name   sql.NullString
-			courier_phone  sql.NullString
-			customer_name  sql.NullString
-			customer_phone sql.NullString
-			product_name   sql.NullString
-			created_at     sql.NullString
-			updated_at     sql.NullString
+	var currency string
 
-			price       sql.NullFloat64
-			total_price sql.NullFloat64
+	err := tx.QueryRow(ctx, "SELECT currency FROM orders WHERE id = $1", orderId).Scan(&currency)
+	if err != nil {
+		return err
+	}
 
-			quantity sql.NullInt32
+	for _, item := range items {
+		var (
+			price          int64
+			price_currency string
+			product_name   sql.NullString
+			category_id    sql.NullString
+			category_name  sql.NullString
+			tax_rate       sql.NullFloat64
 		)
 
-		err = rows.Scan(
-			&id,
-			&name,
+		query := `
+			SELECT
+				p.price,
+				p.currency,
+				p.name,
+				p.category_id,
+				c.name,
+				COALESCE(ptc.rate, ctc.rate, 0)
+			FROM products AS p
+			LEFT JOIN categories AS c ON p.category_id = c.id
+			LEFT JOIN tax_classes AS ptc ON p.tax_class_id = ptc.id
+			LEFT JOIN tax_classes AS ctc ON c.tax_class_id = ctc.id
+			WHERE p.id = $1
+		`
+
+		err = tx.QueryRow(ctx, query, item.ProductId).Scan(
 			&price,
-			&total_price,
-			&quantity,
-			&user_name,
-			&user_phone,
+			&price_currency,
 			&product_name,
-			&customer_name,
-			&customer_phone,
-			&courier_name,
-			&courier_phone,
-			&created_at,
-			&updated_at,
+			&category_id,
+			&category_name,
+			&tax_rate,
 		)
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%w: %s", storage.ErrProductNotFound, item.ProductId)
+		} else if err != nil {
+			return err
+		}
+
+		rate, err := orderExchangeRate(ctx, tx, orderId, price_currency, currency)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		user.Name = user_name.String
-		user.Phone = user_phone.String
-		courier.Name = courier_name.String
-		courier.Phone = courier_phone.String
-		customer.Name = customer_name.String
-		customer.Phone = customer_phone.String
-		product.Name = product_name.String
-		product.Price = price.Float64
+		unitPrice := money.New(price, price_currency).Convert(currency, rate)
 
-		order.User = user
-		order.Product = product
-		order.Customer = customer
-		order.Courier = courier
+		query = `
+			INSERT INTO order_items(
+				id,
+				order_id,
+				product_id,
+				product_name,
+				category_id,
+				category_name,
+				quantity,
+				price,
+				total_price,
+				tax_rate
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`
 
-		resp.Orders = append(resp.Orders, &order)
-	}
+		_, err = tx.Exec(ctx, query,
+			uuid.New().String(),
+			orderId,
+			item.ProductId,
+			product_name,
+			category_id,
+			category_name,
+			item.Quantity,
+			unitPrice.Amount,
+			unitPrice.Mul(int64(item.Quantity)).Amount,
+			tax_rate.Float64,
+		)
+		if err != nil {
+			return err
+		}
 
-	resp.Count = len(resp.Orders)
+		err = changeStock(ctx, tx, &models.StockMovement{
+			ProductId:   item.ProductId,
+			WarehouseId: warehouseId,
+			OrderId:     orderId,
+			Quantity:    -item.Quantity,
+			Reason:      models.StockReasonOrderReserve,
+		})
+		if err != nil {
+			return err
+		}
+	}
 
-	return resp, nil
+	return nil
 }
 
-func (o *orderRepo) Update(ctx context.Context, req *models.UpdateOrder) (int64, error) {
+// pickWarehouse chooses the warehouse that can fulfil every line of the order on its own,
+// preferring the one with the most of the requested products on hand.
+func (o *orderRepo) pickWarehouse(ctx context.Context, tx pgx.Tx, items []*models.CreateOrderItem) (string, error) {
 	var (
-		query  string
-		params map[string]interface{}
+		warehouseId sql.NullString
+		productIds  []string
+		quantities  []int32
+		requested   = make(map[string]int32)
 	)
 
-	query = `
-		UPDATE 
-			orders
-		SET 
-			name = :name,
-			quantity = :quantity,
-			user_id = :user_id,
-			product_id = :product_id,
-			customer_id = :customer_id,
-			courier_id = :courier_id,
-			updated_at = now()
-		WHERE id = :id
-	`
+	for _, item := range items {
+		if _, ok := requested[item.ProductId]; !ok {
+			productIds = append(productIds, item.ProductId)
+		}
+		requested[item.ProductId] += item.Quantity
+	}
 
-	params = map[string]interface{}{
-		"id":          req.Id,
-		"name":        req.Name,
-		"quantity":    req.Quantity,
-		"user_id":     helper.NewNullString(req.UserId),
-		"product_id":  helper.NewNullString(req.ProductId),
-		"customer_id": helper.NewNullString(req.CustomerId),
-		"courier_id":  helper.NewNullString(req.CourierId),
+	for _, productId := range productIds {
+		quantities = append(quantities, requested[productId])
 	}
 
-	query, args := helper.ReplaceQueryParams(query, params)
+	query := `
+		SELECT
+			ws.warehouse_id
+		FROM warehouse_stocks AS ws
+		JOIN UNNEST($1::VARCHAR[], $2::INT[]) AS r(product_id, quantity) ON r.product_id = ws.product_id
+		WHERE ws.quantity >= r.quantity
+		GROUP BY ws.warehouse_id
+		HAVING COUNT(*) = $3
+		ORDER BY SUM(ws.quantity) DESC
+		LIMIT 1
+	`
 
-	result, err := o.db.Exec(ctx, query, args...)
-	if err != nil {
-		return 0, err
+	err := tx.QueryRow(ctx, query, productIds, quantities, len(productIds)).Scan(&warehouseId)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("%w: no warehouse can fulfil the order", storage.ErrInsufficientStock)
+	} else if err != nil {
+		return "", err
 	}
 
-	return result.RowsAffected(), nil
+	return warehouseId.String, nil
 }
 
-func (o *orderRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64, error) {
+// releaseStock returns whatever the order still holds according to the stock ledger,
+// so orders placed before stock tracking existed never inflate the balance.
+func (o *orderRepo) releaseStock(ctx context.Context, tx pgx.Tx, orderId string) error {
+	var movements []*models.StockMovement
 
-	var (
-		query string
-		set   string
-	)
+	query := `
+		SELECT
+			product_id,
+			warehouse_id,
+			-SUM(quantity)
+		FROM stock_movements
+		WHERE order_id = $1 AND reason IN ($2, $3)
+		GROUP BY product_id, warehouse_id
+		HAVING SUM(quantity) < 0
+	`
 
-	if len(req.Fields) <= 0 {
-		return 0, errors.New("no fields")
+	rows, err := tx.Query(ctx, query, orderId, models.StockReasonOrderReserve, models.StockReasonOrderRelease)
+	if err != nil {
+		return err
 	}
 
-	for key := range req.Fields {
-		set += fmt.Sprintf(" %s = :%s, ", key, key)
-	}
+	for rows.Next() {
+		var movement = models.StockMovement{
+			OrderId: orderId,
+			Reason:  models.StockReasonOrderRelease,
+		}
 
-	query = `
-		UPDATE
-			orders
-		SET
-	` + set + ` updated_at = now()
-		WHERE id = :id
-	`
+		err = rows.Scan(&movement.ProductId, &movement.WarehouseId, &movement.Quantity)
+		if err != nil {
+			rows.Close()
+			return err
+		}
 
-	req.Fields["id"] = req.ID
+		movements = append(movements, &movement)
+	}
+	rows.Close()
 
-	query, args := helper.ReplaceQueryParams(query, req.Fields)
+	if err = rows.Err(); err != nil {
+		return err
+	}
 
-	result, err := o.db.Exec(ctx, query, args...)
-	if err != nil {
-		return 0, err
+	for _, movement := range movements {
+		err = changeStock(ctx, tx, movement)
+		if err != nil {
+			return err
+		}
 	}
 
-	return result.RowsAffected(), nil
+	return nil
 }
 
-func (o *orderRepo) Delete(ctx context.Context, req *models.OrderPrimaryKey) error {
-	_, err := o.db.Exec(ctx,
-		"DELETE FROM products WHERE id = $1", req.Id,
+// calculateTotal recomputes the subtotal, tax and grand total of an order from its
+// lines, its discount and its delivery fee. Delivery fees are not taxed.
+func (o *orderRepo) calculateTotal(ctx context.Context, tx pgx.Tx, orderId string) error {
+	var (
+		discount     int64
+		deliveryFee  int64
+		currency     string
+		taxInclusive bool
+		ids          []string
+		lines        []tax.Line
 	)
 
+	err := tx.QueryRow(ctx,
+		"SELECT discount_amount, delivery_fee, currency, tax_inclusive FROM orders WHERE id = $1", orderId,
+	).Scan(&discount, &deliveryFee, &currency, &taxInclusive)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
\ No newline at end of file
+	var (
+		subtotal = money.Zero(currency)
+		taxTotal = money.Zero(currency)
+	)
+
+	rows, err := tx.Query(ctx,
+		"SELECT id, total_price, tax_rate FROM order_items WHERE order_id = $1 ORDER BY created_at, id", orderId,
+	)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var (
+			id     string
+			amount int64
+			line   tax.Line
+		)
+
+		err = rows.Scan(&id, &amount, &line.Rate)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		line.Amount = money.New(amount, currency)
+		ids = append(ids, id)
+		lines = append(lines, line)
+		subtotal = subtotal.Add(line.Amount)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	taxes, err := tax.Calculate(lines, money.New(discount, currency), taxInclusive)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range taxes {
+		_, err = tx.Exec(ctx,
+			"UPDATE order_items SET taxable_amount = $2, tax_amount = $3 WHERE id = $1",
+			ids[i], line.Taxable.Amount, line.Tax.Amount,
+		)
+		if err != nil {
+			return err
+		}
+
+		taxTotal = taxTotal.Add(line.Tax)
+	}
+
+	total := subtotal.Sub(money.New(discount, currency)).Add(money.New(deliveryFee, currency))
+	if !taxInclusive {
+		total = total.Add(taxTotal)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE
+			orders
+		SET
+			subtotal = $2,
+			tax_amount = $3,
+			total_price = $4,
+			updated_at = NOW()
+		WHERE id = $1
+	`, orderId, subtotal.Amount, taxTotal.Amount, total.Amount)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// applyPromotions recomputes the discounts of an order from its lines: every
+// active automatic promotion plus the one named by the order's promo code.
+// Usage limits do not count redemptions on cancelled orders.
+func (o *orderRepo) applyPromotions(ctx context.Context, tx pgx.Tx, orderId string) error {
+	var (
+		customerId sql.NullString
+		promoCode  sql.NullString
+		currency   string
+		cart       promotion.Cart
+		candidates []*models.Promotion
+	)
+
+	err := tx.QueryRow(ctx,
+		"SELECT customer_id, promo_code, currency FROM orders WHERE id = $1", orderId,
+	).Scan(&customerId, &promoCode, &currency)
+	if err != nil {
+		return err
+	}
+
+	cart.Subtotal = money.Zero(currency)
+
+	_, err = tx.Exec(ctx, "DELETE FROM order_discounts WHERE order_id = $1", orderId)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx,
+		"SELECT product_id, category_id, total_price FROM order_items WHERE order_id = $1", orderId,
+	)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var (
+			line                    promotion.Line
+			product_id, category_id sql.NullString
+			amount                  int64
+		)
+
+		err = rows.Scan(&product_id, &category_id, &amount)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		line.ProductId, line.CategoryId = product_id.String, category_id.String
+		line.Amount = money.New(amount, currency)
+		cart.Subtotal = cart.Subtotal.Add(line.Amount)
+		cart.Lines = append(cart.Lines, line)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	query := `
+		SELECT ` + promotionColumns + `,
+			(
+				SELECT COUNT(*)
+				FROM order_discounts AS d
+				JOIN orders AS o ON o.id = d.order_id
+				WHERE d.promotion_id = p.id AND d.customer_id = $2 AND o.status <> 'cancelled'
+			)
+		FROM promotions AS p
+		WHERE p.active
+			AND (p.starts_at IS NULL OR p.starts_at <= NOW())
+			AND (p.ends_at IS NULL OR p.ends_at > NOW())
+			AND (p.code IS NULL OR UPPER(p.code) = UPPER($1))
+		ORDER BY p.created_at
+		FOR UPDATE OF p
+	`
+
+	rows, err = tx.Query(ctx, query, promoCode.String, customerId.String)
+	if err != nil {
+		return err
+	}
+
+	var codeFound bool
+
+	for rows.Next() {
+		var customerUsed int
+
+		candidate, err := scanPromotion(rowScanFunc(func(dest ...interface{}) error {
+			return rows.Scan(append(dest, &customerUsed)...)
+		}))
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		isCode := len(candidate.Code) > 0
+		if isCode {
+			codeFound = true
+		}
+
+		exhausted := (candidate.UsageLimit > 0 && candidate.UsedCount >= candidate.UsageLimit) ||
+			(candidate.PerCustomerLimit > 0 && (!customerId.Valid || customerUsed >= candidate.PerCustomerLimit))
+
+		if exhausted {
+			if isCode {
+				rows.Close()
+				return storage.ErrPromoUsageExceeded
+			}
+			continue
+		}
+
+		candidates = append(candidates, candidate)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if promoCode.Valid && !codeFound {
+		return storage.ErrPromoCodeInvalid
+	}
+
+	discounts, err := promotion.Apply(&cart, candidates, promoCode.String)
+	if errors.Is(err, promotion.ErrNotApplicable) {
+		return storage.ErrPromoNotApplicable
+	} else if err != nil {
+		return err
+	}
+
+	total := money.Zero(currency)
+
+	for _, discount := range discounts {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO order_discounts(
+				order_id,
+				promotion_id,
+				customer_id,
+				code,
+				name,
+				amount
+			) VALUES ($1, $2, $3, $4, $5, $6)
+		`,
+			orderId,
+			discount.PromotionId,
+			customerId,
+			helper.NewNullString(discount.Code),
+			discount.Name,
+			discount.Amount.Amount,
+		)
+		if err != nil {
+			return err
+		}
+
+		total = total.Add(discount.Amount)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE orders SET discount_amount = $2 WHERE id = $1", orderId, total.Amount,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rowScanFunc lets a scan helper read a row that has extra trailing columns.
+type rowScanFunc func(dest ...interface{}) error
+
+func (f rowScanFunc) Scan(dest ...interface{}) error {
+	return f(dest...)
+}
+
+// resolveAddress picks the delivery address for a new order: the saved address
+// named by AddressId, else the inline address, else the customer's default one.
+func (o *orderRepo) resolveAddress(ctx context.Context, tx pgx.Tx, req *models.CreateOrder) (*models.OrderAddress, error) {
+
+	if req.Address != nil && len(req.AddressId) <= 0 {
+		return &models.OrderAddress{
+			Label:     req.Address.Label,
+			Street:    req.Address.Street,
+			Apartment: req.Address.Apartment,
+			Lat:       req.Address.Lat,
+			Lng:       req.Address.Lng,
+			Notes:     req.Address.Notes,
+		}, nil
+	}
+
+	if len(req.CustomerId) <= 0 {
+		return nil, storage.ErrAddressRequired
+	}
+
+	var (
+		query     string
+		args      []interface{}
+		address   models.OrderAddress
+		label     sql.NullString
+		apartment sql.NullString
+		notes     sql.NullString
+	)
+
+	query = `
+		SELECT
+			id,
+			label,
+			street,
+			apartment,
+			lat,
+			lng,
+			notes
+		FROM customer_addresses
+		WHERE customer_id = $1
+	`
+	args = append(args, req.CustomerId)
+
+	if len(req.AddressId) > 0 {
+		query += " AND id = $2"
+		args = append(args, req.AddressId)
+	} else {
+		query += " AND is_default"
+	}
+
+	err := tx.QueryRow(ctx, query, args...).Scan(
+		&address.AddressId,
+		&label,
+		&address.Street,
+		&apartment,
+		&address.Lat,
+		&address.Lng,
+		&notes,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if len(req.AddressId) > 0 {
+			return nil, storage.ErrAddressNotFound
+		}
+		return nil, storage.ErrAddressRequired
+	} else if err != nil {
+		return nil, err
+	}
+
+	address.Label = label.String
+	address.Apartment = apartment.String
+	address.Notes = notes.String
+
+	return &address, nil
+}
+
+// findDeliveryZone returns the active zone containing the destination. As long
+// as no zone is active every address is served, with no zone, fee or minimum,
+// so deployments without zones keep taking orders.
+func (o *orderRepo) findDeliveryZone(ctx context.Context, tx pgx.Tx, destination models.Point) (*models.DeliveryZone, error) {
+
+	var zones int
+
+	query := `
+		SELECT
+			id,
+			polygon,
+			delivery_fee,
+			min_order_amount,
+			currency
+		FROM delivery_zones
+		WHERE active
+		ORDER BY delivery_fee, id
+	`
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			zone             models.DeliveryZone
+			id               sql.NullString
+			polygon          []byte
+			delivery_fee     sql.NullInt64
+			min_order_amount sql.NullInt64
+			currency         sql.NullString
+			vertices         [][2]float64
+		)
+
+		err = rows.Scan(
+			&id,
+			&polygon,
+			&delivery_fee,
+			&min_order_amount,
+			&currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+		zones++
+
+		err = json.Unmarshal(polygon, &zone.Polygon)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, point := range zone.Polygon {
+			vertices = append(vertices, [2]float64{point.Lat, point.Lng})
+		}
+
+		if helper.PointInPolygon(destination.Lat, destination.Lng, vertices) {
+			zone.Id = id.String
+			zone.DeliveryFee = money.New(delivery_fee.Int64, currency.String)
+			zone.MinOrderAmount = money.New(min_order_amount.Int64, currency.String)
+			return &zone, nil
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if zones <= 0 {
+		return &models.DeliveryZone{}, nil
+	}
+
+	return nil, storage.ErrOutOfDeliveryZone
+}
+
+func (o *orderRepo) checkMinimumOrder(ctx context.Context, tx pgx.Tx, orderId string) error {
+	var (
+		subtotal         sql.NullInt64
+		min_order_amount sql.NullInt64
+		zone_currency    sql.NullString
+		currency         sql.NullString
+	)
+
+	query := `
+		SELECT
+			o.subtotal,
+			dz.min_order_amount,
+			dz.currency,
+			o.currency
+		FROM orders AS o
+		LEFT JOIN delivery_zones AS dz ON o.delivery_zone_id = dz.id
+		WHERE o.id = $1
+	`
+
+	err := tx.QueryRow(ctx, query, orderId).Scan(&subtotal, &min_order_amount, &zone_currency, &currency)
+	if err != nil {
+		return err
+	}
+
+	if !min_order_amount.Valid {
+		return nil
+	}
+
+	rate, err := orderExchangeRate(ctx, tx, orderId, zone_currency.String, currency.String)
+	if err != nil {
+		return err
+	}
+
+	var (
+		orderSubtotal = money.New(subtotal.Int64, currency.String)
+		minimum       = money.New(min_order_amount.Int64, zone_currency.String).Convert(currency.String, rate)
+	)
+
+	if orderSubtotal.Cmp(minimum) < 0 {
+		return fmt.Errorf("%w: %s < %s", storage.ErrBelowMinimumOrder, orderSubtotal, minimum)
+	}
+
+	return nil
+}
+
+func (o *orderRepo) getItems(ctx context.Context, orderIds []string) (map[string][]*models.OrderItem, error) {
+	var (
+		query string
+		items = make(map[string][]*models.OrderItem)
+	)
+
+	query = `
+		SELECT
+			oi.id,
+			oi.order_id,
+			oi.product_id,
+			oi.product_name,
+			oi.category_id,
+			oi.category_name,
+			oi.quantity,
+			oi.price,
+			oi.total_price,
+			oi.refunded_quantity,
+			oi.tax_rate,
+			oi.taxable_amount,
+			oi.tax_amount,
+			o.currency
+		FROM order_items AS oi
+		JOIN orders AS o ON o.id = oi.order_id
+		WHERE oi.order_id = ANY($1)
+		ORDER BY oi.created_at
+	`
+
+	rows, err := o.db.Query(ctx, query, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id            sql.NullString
+			order_id      sql.NullString
+			product_id    sql.NullString
+			product_name  sql.NullString
+			category_id   sql.NullString
+			category_name sql.NullString
+			quantity      sql.NullInt32
+			price         sql.NullInt64
+			total_price   sql.NullInt64
+			refunded      sql.NullInt32
+			tax_rate      sql.NullFloat64
+			taxable       sql.NullInt64
+			tax_amount    sql.NullInt64
+			currency      sql.NullString
+		)
+
+		err = rows.Scan(
+			&id,
+			&order_id,
+			&product_id,
+			&product_name,
+			&category_id,
+			&category_name,
+			&quantity,
+			&price,
+			&total_price,
+			&refunded,
+			&tax_rate,
+			&taxable,
+			&tax_amount,
+			&currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		items[order_id.String] = append(items[order_id.String], &models.OrderItem{
+			Id:               id.String,
+			ProductId:        product_id.String,
+			ProductName:      product_name.String,
+			CategoryId:       category_id.String,
+			CategoryName:     category_name.String,
+			Quantity:         quantity.Int32,
+			Price:            money.New(price.Int64, currency.String),
+			TotalPrice:       money.New(total_price.Int64, currency.String),
+			RefundedQuantity: refunded.Int32,
+			TaxRate:          tax_rate.Float64,
+			TaxableAmount:    money.New(taxable.Int64, currency.String),
+			TaxAmount:        money.New(tax_amount.Int64, currency.String),
+		})
+	}
+
+	return items, nil
+}
+
+func (o *orderRepo) getDiscounts(ctx context.Context, orderIds []string) (map[string][]*models.OrderDiscount, error) {
+	var (
+		query     string
+		discounts = make(map[string][]*models.OrderDiscount)
+	)
+
+	query = `
+		SELECT
+			d.order_id,
+			d.promotion_id,
+			d.code,
+			d.name,
+			d.amount,
+			o.currency
+		FROM order_discounts AS d
+		JOIN orders AS o ON o.id = d.order_id
+		WHERE d.order_id = ANY($1)
+		ORDER BY d.amount DESC
+	`
+
+	rows, err := o.db.Query(ctx, query, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			order_id     sql.NullString
+			promotion_id sql.NullString
+			code         sql.NullString
+			name         sql.NullString
+			amount       sql.NullInt64
+			currency     sql.NullString
+		)
+
+		err = rows.Scan(
+			&order_id,
+			&promotion_id,
+			&code,
+			&name,
+			&amount,
+			&currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		discounts[order_id.String] = append(discounts[order_id.String], &models.OrderDiscount{
+			PromotionId: promotion_id.String,
+			Code:        code.String,
+			Name:        name.String,
+			Amount:      money.New(amount.Int64, currency.String),
+		})
+	}
+
+	return discounts, nil
+}
+
+// getTaxes sums the taxes of every order per rate.
+func (o *orderRepo) getTaxes(ctx context.Context, orderIds []string) (map[string][]*models.OrderTax, error) {
+	var (
+		query string
+		taxes = make(map[string][]*models.OrderTax)
+	)
+
+	query = `
+		SELECT
+			oi.order_id,
+			oi.tax_rate,
+			SUM(oi.taxable_amount)::BIGINT,
+			SUM(oi.tax_amount)::BIGINT,
+			o.currency
+		FROM order_items AS oi
+		JOIN orders AS o ON o.id = oi.order_id
+		WHERE oi.order_id = ANY($1) AND oi.tax_rate > 0
+		GROUP BY oi.order_id, oi.tax_rate, o.currency
+		ORDER BY oi.tax_rate
+	`
+
+	rows, err := o.db.Query(ctx, query, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			order_id       sql.NullString
+			tax_rate       sql.NullFloat64
+			taxable_amount sql.NullInt64
+			tax_amount     sql.NullInt64
+			currency       sql.NullString
+		)
+
+		err = rows.Scan(
+			&order_id,
+			&tax_rate,
+			&taxable_amount,
+			&tax_amount,
+			&currency,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		taxes[order_id.String] = append(taxes[order_id.String], &models.OrderTax{
+			Rate:          tax_rate.Float64,
+			TaxableAmount: money.New(taxable_amount.Int64, currency.String),
+			Amount:        money.New(tax_amount.Int64, currency.String),
+		})
+	}
+
+	return taxes, nil
+}
+
+// getExchangeRates returns the rates snapshotted on every order.
+func (o *orderRepo) getExchangeRates(ctx context.Context, orderIds []string) (map[string][]*models.OrderExchangeRate, error) {
+	var (
+		query string
+		rates = make(map[string][]*models.OrderExchangeRate)
+	)
+
+	query = `
+		SELECT
+			order_id,
+			base_currency,
+			quote_currency,
+			rate::TEXT,
+			TO_CHAR(effective_from, 'YYYY-MM-DD HH24:MI:SS')
+		FROM order_exchange_rates
+		WHERE order_id = ANY($1)
+		ORDER BY base_currency
+	`
+
+	rows, err := o.db.Query(ctx, query, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			order_id string
+			rate     models.OrderExchangeRate
+		)
+
+		err = rows.Scan(
+			&order_id,
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.EffectiveFrom,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rates[order_id] = append(rates[order_id], &rate)
+	}
+
+	return rates, nil
+}
+
+func (o *orderRepo) GetByID(ctx context.Context, req *models.OrderPrimaryKey) (*models.Order, error) {
+	var (
+		query              string
+		id                 sql.NullString
+		name               sql.NullString
+		status             sql.NullString
+		warehouse_id       sql.NullString
+		address_id         sql.NullString
+		delivery_label     sql.NullString
+		delivery_street    sql.NullString
+		delivery_apartment sql.NullString
+		delivery_notes     sql.NullString
+		delivery_lat       sql.NullFloat64
+		delivery_lng       sql.NullFloat64
+		zone_id            sql.NullString
+		zone_name          sql.NullString
+		subtotal           sql.NullInt64
+		delivery_fee       sql.NullInt64
+		discount_amount    sql.NullInt64
+		promo_code         sql.NullString
+		tax_inclusive      sql.NullBool
+		tax_amount         sql.NullInt64
+		total_price        sql.NullInt64
+		paid_amount        sql.NullInt64
+		refunded_amount    sql.NullInt64
+		currency           sql.NullString
+		user_name          sql.NullString
+		user_phone         sql.NullString
+		customer_id        sql.NullString
+		customer_name      sql.NullString
+		customer_phone     sql.NullString
+		courier_id         sql.NullString
+		courier_name       sql.NullString
+		courier_phone      sql.NullString
+		created_at         sql.NullString
+		updated_at         sql.NullString
+	)
+
+	query = `
+		SELECT
+			o.id,
+			o.name,
+			o.status,
+			o.warehouse_id,
+			o.delivery_address_id,
+			o.delivery_label,
+			o.delivery_street,
+			o.delivery_apartment,
+			o.delivery_notes,
+			o.delivery_lat,
+			o.delivery_lng,
+			o.delivery_zone_id,
+			dz.name,
+			o.subtotal,
+			o.delivery_fee,
+			o.discount_amount,
+			o.promo_code,
+			o.tax_inclusive,
+			o.tax_amount,
+			o.total_price,
+			o.paid_amount,
+			o.refunded_amount,
+			o.currency,
+			u.name,
+			u.phone,
+			o.customer_id,
+			c.name,
+			c.phone,
+			o.courier_id,
+			co.name,
+			co.phone,
+			o.created_at,
+			o.updated_at
+		FROM orders AS o
+		LEFT JOIN users AS u ON o.user_id = u.id
+		LEFT JOIN customers AS c ON o.customer_id = c.id
+		LEFT JOIN couriers AS co ON o.courier_id = co.id
+		LEFT JOIN delivery_zones AS dz ON o.delivery_zone_id = dz.id
+		WHERE o.id = $1
+	`
+
+	err := o.db.QueryRow(ctx, query, req.Id).Scan(
+		&id,
+		&name,
+		&status,
+		&warehouse_id,
+		&address_id,
+		&delivery_label,
+		&delivery_street,
+		&delivery_apartment,
+		&delivery_notes,
+		&delivery_lat,
+		&delivery_lng,
+		&zone_id,
+		&zone_name,
+		&subtotal,
+		&delivery_fee,
+		&discount_amount,
+		&promo_code,
+		&tax_inclusive,
+		&tax_amount,
+		&total_price,
+		&paid_amount,
+		&refunded_amount,
+		&currency,
+		&user_name,
+		&user_phone,
+		&customer_id,
+		&customer_name,
+		&customer_phone,
+		&courier_id,
+		&courier_name,
+		&courier_phone,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := o.getItems(ctx, []string{id.String})
+	if err != nil {
+		return nil, err
+	}
+
+	discounts, err := o.getDiscounts(ctx, []string{id.String})
+	if err != nil {
+		return nil, err
+	}
+
+	taxes, err := o.getTaxes(ctx, []string{id.String})
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := o.getExchangeRates(ctx, []string{id.String})
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.ReturnUser
+	user.Name = user_name.String
+	user.Phone = user_phone.String
+
+	var customer models.ReturnCustomer
+	customer.Id = customer_id.String
+	customer.Name = customer_name.String
+	customer.Phone = customer_phone.String
+
+	var courier models.ReturnCourier
+	courier.Id = courier_id.String
+	courier.Name = courier_name.String
+	courier.Phone = courier_phone.String
+
+	var (
+		totalPrice = money.New(total_price.Int64, currency.String)
+		paidAmount = money.New(paid_amount.Int64, currency.String)
+		refunded   = money.New(refunded_amount.Int64, currency.String)
+	)
+
+	return &models.Order{
+		Id:          id.String,
+		Name:        name.String,
+		Status:      status.String,
+		WarehouseId: warehouse_id.String,
+		DeliveryAddress: models.OrderAddress{
+			AddressId: address_id.String,
+			Label:     delivery_label.String,
+			Street:    delivery_street.String,
+			Apartment: delivery_apartment.String,
+			Lat:       delivery_lat.Float64,
+			Lng:       delivery_lng.Float64,
+			Notes:     delivery_notes.String,
+		},
+		DeliveryZone: models.ReturnDeliveryZone{
+			Id:   zone_id.String,
+			Name: zone_name.String,
+		},
+		Subtotal:       money.New(subtotal.Int64, currency.String),
+		DeliveryFee:    money.New(delivery_fee.Int64, currency.String),
+		DiscountAmount: money.New(discount_amount.Int64, currency.String),
+		Discounts:      discounts[id.String],
+		PromoCode:      promo_code.String,
+		TaxInclusive:   tax_inclusive.Bool,
+		TaxAmount:      money.New(tax_amount.Int64, currency.String),
+		Taxes:          taxes[id.String],
+		ExchangeRates:  rates[id.String],
+		TotalPrice:     totalPrice,
+		Currency:       currency.String,
+		PaidAmount:     paidAmount,
+		RefundedAmount: refunded,
+		Outstanding:    models.OrderOutstanding(totalPrice, paidAmount),
+		PaymentStatus:  models.OrderPaymentStatus(paidAmount, refunded, totalPrice),
+		User:           user,
+		Customer:       customer,
+		Courier:        courier,
+		Items:          items[id.String],
+		CreatedAt:      created_at.String,
+		UpdatedAt:      updated_at.String,
+	}, nil
+}
+
+func (o *orderRepo) GetList(ctx context.Context, req *models.GetListOrderRequest) (resp *models.GetListOrderResponse, err error) {
+	resp = &models.GetListOrderResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT
+			o.id,
+			o.name,
+			o.status,
+			o.warehouse_id,
+			o.delivery_address_id,
+			o.delivery_label,
+			o.delivery_street,
+			o.delivery_apartment,
+			o.delivery_notes,
+			o.delivery_lat,
+			o.delivery_lng,
+			o.delivery_zone_id,
+			dz.name,
+			o.subtotal,
+			o.delivery_fee,
+			o.discount_amount,
+			o.promo_code,
+			o.tax_inclusive,
+			o.tax_amount,
+			o.total_price,
+			o.paid_amount,
+			o.refunded_amount,
+			o.currency,
+			u.name,
+			u.phone,
+			o.customer_id,
+			c.name,
+			c.phone,
+			o.courier_id,
+			co.name,
+			co.phone,
+			o.created_at,
+			o.updated_at
+		FROM orders AS o
+		LEFT JOIN users AS u ON o.user_id = u.id
+		LEFT JOIN customers AS c ON o.customer_id = c.id
+		LEFT JOIN couriers AS co ON o.courier_id = co.id
+		LEFT JOIN delivery_zones AS dz ON o.delivery_zone_id = dz.id
+	`
+
+	if len(req.Search) > 0 {
+		filter += " AND o.name ILIKE '%' || :search || '%' "
+		params["search"] = req.Search
+	}
+
+	if len(req.CourierId) > 0 {
+		filter += " AND o.courier_id = :courier_id "
+		params["courier_id"] = req.CourierId
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + " ORDER BY o.created_at DESC " + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := o.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orderIds []string
+
+	for rows.Next() {
+
+		var order models.Order
+		var user models.ReturnUser
+		var customer models.ReturnCustomer
+		var courier models.ReturnCourier
+
+		var (
+			id                 sql.NullString
+			name               sql.NullString
+			status             sql.NullString
+			warehouse_id       sql.NullString
+			address_id         sql.NullString
+			delivery_label     sql.NullString
+			delivery_street    sql.NullString
+			delivery_apartment sql.NullString
+			delivery_notes     sql.NullString
+			zone_id            sql.NullString
+			zone_name          sql.NullString
+			user_name          sql.NullString
+			user_phone         sql.NullString
+			courier_id         sql.NullString
+			courier_name       sql.NullString
+			courier_phone      sql.NullString
+			customer_id        sql.NullString
+			customer_name      sql.NullString
+			customer_phone     sql.NullString
+			created_at         sql.NullString
+			updated_at         sql.NullString
+
+			delivery_lat sql.NullFloat64
+			delivery_lng sql.NullFloat64
+			subtotal     sql.NullInt64
+			delivery_fee sql.NullInt64
+			discount     sql.NullInt64
+			promo_code   sql.NullString
+			inclusive    sql.NullBool
+			tax_amount   sql.NullInt64
+			total_price  sql.NullInt64
+			paid_amount  sql.NullInt64
+			refunded     sql.NullInt64
+			currency     sql.NullString
+		)
+
+		err = rows.Scan(
+			&id,
+			&name,
+			&status,
+			&warehouse_id,
+			&address_id,
+			&delivery_label,
+			&delivery_street,
+			&delivery_apartment,
+			&delivery_notes,
+			&delivery_lat,
+			&delivery_lng,
+			&zone_id,
+			&zone_name,
+			&subtotal,
+			&delivery_fee,
+			&discount,
+			&promo_code,
+			&inclusive,
+			&tax_amount,
+			&total_price,
+			&paid_amount,
+			&refunded,
+			&currency,
+			&user_name,
+			&user_phone,
+			&customer_id,
+			&customer_name,
+			&customer_phone,
+			&courier_id,
+			&courier_name,
+			&courier_phone,
+			&created_at,
+			&updated_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Name = user_name.String
+		user.Phone = user_phone.String
+		courier.Id = courier_id.String
+		courier.Name = courier_name.String
+		courier.Phone = courier_phone.String
+		customer.Id = customer_id.String
+		customer.Name = customer_name.String
+		customer.Phone = customer_phone.String
+
+		order.Id = id.String
+		order.Name = name.String
+		order.Status = status.String
+		order.WarehouseId = warehouse_id.String
+		order.DeliveryAddress = models.OrderAddress{
+			AddressId: address_id.String,
+			Label:     delivery_label.String,
+			Street:    delivery_street.String,
+			Apartment: delivery_apartment.String,
+			Lat:       delivery_lat.Float64,
+			Lng:       delivery_lng.Float64,
+			Notes:     delivery_notes.String,
+		}
+		order.DeliveryZone = models.ReturnDeliveryZone{Id: zone_id.String, Name: zone_name.String}
+		order.Subtotal = money.New(subtotal.Int64, currency.String)
+		order.DeliveryFee = money.New(delivery_fee.Int64, currency.String)
+		order.DiscountAmount = money.New(discount.Int64, currency.String)
+		order.PromoCode = promo_code.String
+		order.TaxInclusive = inclusive.Bool
+		order.TaxAmount = money.New(tax_amount.Int64, currency.String)
+		order.TotalPrice = money.New(total_price.Int64, currency.String)
+		order.Currency = currency.String
+		order.PaidAmount = money.New(paid_amount.Int64, currency.String)
+		order.Outstanding = models.OrderOutstanding(order.TotalPrice, order.PaidAmount)
+		order.RefundedAmount = money.New(refunded.Int64, currency.String)
+		order.PaymentStatus = models.OrderPaymentStatus(order.PaidAmount, order.RefundedAmount, order.TotalPrice)
+		order.User = user
+		order.Customer = customer
+		order.Courier = courier
+		order.CreatedAt = created_at.String
+		order.UpdatedAt = updated_at.String
+
+		orderIds = append(orderIds, order.Id)
+		resp.Orders = append(resp.Orders, &order)
+	}
+	rows.Close()
+
+	if len(orderIds) > 0 {
+		items, err := o.getItems(ctx, orderIds)
+		if err != nil {
+			return nil, err
+		}
+
+		discounts, err := o.getDiscounts(ctx, orderIds)
+		if err != nil {
+			return nil, err
+		}
+
+		taxes, err := o.getTaxes(ctx, orderIds)
+		if err != nil {
+			return nil, err
+		}
+
+		rates, err := o.getExchangeRates(ctx, orderIds)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, order := range resp.Orders {
+			order.Items = items[order.Id]
+			order.Discounts = discounts[order.Id]
+			order.Taxes = taxes[order.Id]
+			order.ExchangeRates = rates[order.Id]
+		}
+	}
+
+	resp.Count = len(resp.Orders)
+
+	return resp, nil
+}
+
+func (o *orderRepo) Update(ctx context.Context, req *models.UpdateOrder) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query = `
+		UPDATE
+			orders
+		SET
+			name = :name,
+			user_id = :user_id,
+			customer_id = :customer_id,
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":          req.Id,
+		"name":        req.Name,
+		"user_id":     helper.NewNullString(req.UserId),
+		"customer_id": helper.NewNullString(req.CustomerId),
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() > 0 && len(req.Items) > 0 {
+		var status sql.NullString
+
+		err = tx.QueryRow(ctx, "SELECT status FROM orders WHERE id = $1", req.Id).Scan(&status)
+		if err != nil {
+			return 0, err
+		}
+
+		if status.String != models.OrderStatusNew {
+			return 0, storage.ErrOrderLocked
+		}
+
+		err = o.releaseStock(ctx, tx, req.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, "DELETE FROM order_items WHERE order_id = $1", req.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		warehouseId, err := o.pickWarehouse(ctx, tx, req.Items)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE orders SET warehouse_id = $2 WHERE id = $1", req.Id, warehouseId)
+		if err != nil {
+			return 0, err
+		}
+
+		err = o.insertItems(ctx, tx, req.Id, warehouseId, req.Items)
+		if err != nil {
+			return 0, err
+		}
+
+		err = o.applyPromotions(ctx, tx, req.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		err = o.calculateTotal(ctx, tx, req.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		err = o.checkMinimumOrder(ctx, tx, req.Id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if result.RowsAffected() > 0 {
+		err = o.insertEvent(ctx, tx, &models.OrderEvent{
+			OrderId:   req.Id,
+			ActorType: req.ActorType,
+			ActorId:   req.ActorId,
+			Event:     models.OrderEventUpdated,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// patchableOrderFields are the order columns a patch may set directly. Status,
+// money and stock related columns have their own flows and are left out.
+var patchableOrderFields = map[string]bool{
+	"name":               true,
+	"user_id":            true,
+	"customer_id":        true,
+	"courier_id":         true,
+	"delivery_label":     true,
+	"delivery_apartment": true,
+	"delivery_notes":     true,
+}
+
+func (o *orderRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64, error) {
+
+	var (
+		query  string
+		set    string
+		fields []string
+	)
+
+	if len(req.Fields) <= 0 {
+		return 0, errors.New("no fields")
+	}
+
+	if _, ok := req.Fields["status"]; ok {
+		return 0, storage.ErrOrderStatusReadOnly
+	}
+
+	for key := range req.Fields {
+		if !patchableOrderFields[key] {
+			return 0, fmt.Errorf("%w: %s", storage.ErrFieldNotPatchable, key)
+		}
+		set += fmt.Sprintf(" %s = :%s, ", key, key)
+		fields = append(fields, key)
+	}
+
+	sort.Strings(fields)
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query = `
+		UPDATE
+			orders
+		SET
+	` + set + ` updated_at = now()
+		WHERE id = :id
+	`
+
+	req.Fields["id"] = req.ID
+
+	query, args := helper.ReplaceQueryParams(query, req.Fields)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.RowsAffected() > 0 {
+		err = o.insertEvent(ctx, tx, &models.OrderEvent{
+			OrderId:   req.ID,
+			ActorType: req.ActorType,
+			ActorId:   req.ActorId,
+			Event:     models.OrderEventUpdated,
+			Note:      "patched fields: " + strings.Join(fields, ", "),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		if courierId, ok := req.Fields["courier_id"]; ok {
+			err = o.insertEvent(ctx, tx, &models.OrderEvent{
+				OrderId:   req.ID,
+				ActorType: req.ActorType,
+				ActorId:   req.ActorId,
+				Event:     models.OrderEventCourierAssigned,
+				Note:      fmt.Sprintf("manual override: courier %v", courierId),
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionAssigned, req.ID)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// Delete removes an order. Stock it still reserves goes back to the warehouse
+// unless the goods already left it with the courier.
+func (o *orderRepo) Delete(ctx context.Context, req *models.OrderPrimaryKey) error {
+	var status sql.NullString
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", req.Id).Scan(&status)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	switch status.String {
+	case models.OrderStatusNew, models.OrderStatusAccepted, models.OrderStatusPreparing, models.OrderStatusReady:
+		err = o.releaseStock(ctx, tx, req.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM orders WHERE id = $1", req.Id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (o *orderRepo) Transition(ctx context.Context, req *models.TransitionOrder) error {
+	var (
+		status     sql.NullString
+		courier_id sql.NullString
+	)
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT status, courier_id FROM orders WHERE id = $1 FOR UPDATE", req.Id).Scan(&status, &courier_id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrOrderNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if !canTransition(status.String, req.Status) {
+		return fmt.Errorf("%w: %s -> %s", storage.ErrInvalidOrderTransition, status.String, req.Status)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE orders SET status = $2, updated_at = NOW() WHERE id = $1", req.Id, req.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	if req.Status == models.OrderStatusCancelled {
+		err = o.releaseStock(ctx, tx, req.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	if courier_id.Valid {
+		err = updateCourierBusy(ctx, tx, courier_id.String, req.Id, req.Status)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = o.insertEvent(ctx, tx, &models.OrderEvent{
+		OrderId:    req.Id,
+		ActorType:  req.ActorType,
+		ActorId:    req.ActorId,
+		Event:      models.OrderEventStatusChanged,
+		FromStatus: status.String,
+		ToStatus:   req.Status,
+		Note:       req.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	// a delivered order is final enough to be invoiced
+	if req.Status == models.OrderStatusDelivered {
+		_, err = o.issueInvoice(ctx, tx, &models.IssueOrderInvoice{
+			Id:        req.Id,
+			ActorType: req.ActorType,
+			ActorId:   req.ActorId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionStatusChanged, req.Id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// updateCourierBusy keeps the availability of the courier of an order in step
+// with it: an online courier who picks the order up is busy, and a busy courier
+// is online again once no picked up order is left. Offline couriers stay
+// offline.
+func updateCourierBusy(ctx context.Context, tx pgx.Tx, courierId, orderId, status string) error {
+
+	var (
+		result pgconn.CommandTag
+		err    error
+	)
+
+	switch status {
+	case models.OrderStatusPickedUp:
+		result, err = tx.Exec(ctx,
+			"UPDATE couriers SET status = $2, updated_at = NOW() WHERE id = $1 AND status = $3",
+			courierId, models.CourierStatusBusy, models.CourierStatusOnline,
+		)
+	case models.OrderStatusDelivered, models.OrderStatusReturned, models.OrderStatusCancelled:
+		result, err = tx.Exec(ctx, `
+			UPDATE couriers SET status = $2, updated_at = NOW()
+			WHERE id = $1 AND status = $3 AND NOT EXISTS (
+				SELECT 1 FROM orders WHERE courier_id = $1 AND status = $4 AND id <> $5
+			)
+		`, courierId, models.CourierStatusOnline, models.CourierStatusBusy, models.OrderStatusPickedUp, orderId)
+	}
+
+	if err != nil || result.RowsAffected() <= 0 {
+		return err
+	}
+
+	return writeOutbox(ctx, tx, "couriers", models.OutboxAggregateCourier, models.OutboxActionStatusChanged, courierId)
+}
+
+func (o *orderRepo) History(ctx context.Context, req *models.OrderPrimaryKey) (resp *models.GetOrderHistoryResponse, err error) {
+	resp = &models.GetOrderHistoryResponse{}
+
+	query := `
+		SELECT
+			id,
+			order_id,
+			actor_type,
+			actor_id,
+			event,
+			from_status,
+			to_status,
+			note,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24:MI:SS.MS')
+		FROM order_events
+		WHERE order_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := o.db.Query(ctx, query, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id          sql.NullString
+			order_id    sql.NullString
+			actor_type  sql.NullString
+			actor_id    sql.NullString
+			event       sql.NullString
+			from_status sql.NullString
+			to_status   sql.NullString
+			note        sql.NullString
+			created_at  sql.NullString
+		)
+
+		err = rows.Scan(
+			&id,
+			&order_id,
+			&actor_type,
+			&actor_id,
+			&event,
+			&from_status,
+			&to_status,
+			&note,
+			&created_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Events = append(resp.Events, &models.OrderEvent{
+			Id:         id.String,
+			OrderId:    order_id.String,
+			ActorType:  actor_type.String,
+			ActorId:    actor_id.String,
+			Event:      event.String,
+			FromStatus: from_status.String,
+			ToStatus:   to_status.String,
+			Note:       note.String,
+			CreatedAt:  created_at.String,
+		})
+	}
+
+	resp.Count = len(resp.Events)
+
+	return resp, nil
+}
+
+// AssignCourier sets the courier of an order that does not have one yet.
+func (o *orderRepo) AssignCourier(ctx context.Context, req *models.AssignCourier) error {
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		"UPDATE orders SET courier_id = $2, updated_at = NOW() WHERE id = $1 AND courier_id IS NULL",
+		req.OrderId, req.CourierId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() <= 0 {
+		return storage.ErrOrderAlreadyAssigned
+	}
+
+	err = o.insertEvent(ctx, tx, &models.OrderEvent{
+		OrderId: req.OrderId,
+		Event:   models.OrderEventCourierAssigned,
+		Note:    req.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionAssigned, req.OrderId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetUndispatched returns the ids of ready orders without a courier, the ones
+// that have waited longest first.
+func (o *orderRepo) GetUndispatched(ctx context.Context) ([]string, error) {
+	var ids []string
+
+	rows, err := o.db.Query(ctx,
+		"SELECT id FROM orders WHERE status = $1 AND courier_id IS NULL ORDER BY updated_at, id",
+		models.OrderStatusReady,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Refund records a refund and adds it to the refunded amount of the order. Line
+// refunds are priced at what the customer paid per unit, after discounts and
+// with tax; a refund without items gives back everything still refundable.
+// Refunds never exceed the captured payments.
+func (o *orderRepo) Refund(ctx context.Context, req *models.CreateRefund) (string, error) {
+	var (
+		id         = uuid.New().String()
+		paid       int64
+		refunded   int64
+		currency   string
+		fullRefund = len(req.Items) <= 0
+		lines      []*models.RefundItem
+	)
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		"SELECT paid_amount, refunded_amount, currency FROM orders WHERE id = $1 FOR UPDATE",
+		req.OrderId,
+	).Scan(&paid, &refunded, &currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrOrderNotFound
+	} else if err != nil {
+		return "", err
+	}
+
+	var (
+		refundable = money.New(paid-refunded, currency)
+		amount     = money.Zero(currency)
+	)
+
+	if fullRefund {
+		amount = refundable
+	}
+
+	for _, item := range req.Items {
+		var (
+			line     = models.RefundItem{OrderItemId: item.OrderItemId, Quantity: item.Quantity}
+			linePaid int64
+			quantity int64
+		)
+
+		err = tx.QueryRow(ctx, `
+			SELECT
+				i.taxable_amount + CASE WHEN o.tax_inclusive THEN 0 ELSE i.tax_amount END,
+				i.quantity
+			FROM order_items AS i
+			JOIN orders AS o ON o.id = i.order_id
+			WHERE i.id = $1 AND i.order_id = $2 AND i.quantity - i.refunded_quantity >= $3
+			FOR UPDATE OF i
+		`, item.OrderItemId, req.OrderId, item.Quantity).Scan(&linePaid, &quantity)
+		if errors.Is(err, pgx.ErrNoRows) {
+			var exists bool
+
+			err = tx.QueryRow(ctx,
+				"SELECT EXISTS(SELECT 1 FROM order_items WHERE id = $1 AND order_id = $2)",
+				item.OrderItemId, req.OrderId,
+			).Scan(&exists)
+			if err != nil {
+				return "", err
+			}
+
+			if !exists {
+				return "", storage.ErrRefundItemNotFound
+			}
+			return "", storage.ErrRefundQuantityExceeded
+		} else if err != nil {
+			return "", err
+		}
+
+		line.Amount = money.New(linePaid, currency).MulRat(int64(item.Quantity), quantity)
+		amount = amount.Add(line.Amount)
+		lines = append(lines, &line)
+	}
+
+	if !amount.IsPositive() || amount.Cmp(refundable) > 0 {
+		return "", storage.ErrRefundExceedsPayments
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO refunds(
+			id,
+			order_id,
+			amount,
+			reason,
+			full_refund,
+			actor_type,
+			actor_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		id,
+		req.OrderId,
+		amount.Amount,
+		req.Reason,
+		fullRefund,
+		helper.NewNullString(req.ActorType),
+		helper.NewNullString(req.ActorId),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range lines {
+		_, err = tx.Exec(ctx,
+			"INSERT INTO refund_items(refund_id, order_item_id, quantity, amount) VALUES ($1, $2, $3, $4)",
+			id, line.OrderItemId, line.Quantity, line.Amount.Amount,
+		)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE order_items SET refunded_quantity = refunded_quantity + $2 WHERE id = $1",
+			line.OrderItemId, line.Quantity,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if fullRefund {
+		_, err = tx.Exec(ctx, "UPDATE order_items SET refunded_quantity = quantity WHERE order_id = $1", req.OrderId)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE orders SET refunded_amount = refunded_amount + $2, updated_at = NOW() WHERE id = $1",
+		req.OrderId, amount.Amount,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = o.insertEvent(ctx, tx, &models.OrderEvent{
+		OrderId:   req.OrderId,
+		ActorType: req.ActorType,
+		ActorId:   req.ActorId,
+		Event:     models.OrderEventRefunded,
+		Note:      fmt.Sprintf("%s: %s", amount, req.Reason),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionRefunded, req.OrderId)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (o *orderRepo) GetRefunds(ctx context.Context, req *models.OrderPrimaryKey) (resp *models.GetListRefundResponse, err error) {
+	resp = &models.GetListRefundResponse{}
+
+	var (
+		refunds = make(map[string]*models.Refund)
+	)
+
+	query := `
+		SELECT
+			r.id,
+			r.order_id,
+			r.amount,
+			o.currency,
+			r.reason,
+			r.full_refund,
+			r.actor_type,
+			r.actor_id,
+			TO_CHAR(r.created_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM refunds AS r
+		JOIN orders AS o ON o.id = r.order_id
+		WHERE r.order_id = $1
+		ORDER BY r.created_at
+	`
+
+	rows, err := o.db.Query(ctx, query, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			refund                           models.Refund
+			amount                           int64
+			currency                         string
+			actor_type, actor_id, created_at sql.NullString
+		)
+
+		err = rows.Scan(
+			&refund.Id,
+			&refund.OrderId,
+			&amount,
+			&currency,
+			&refund.Reason,
+			&refund.FullRefund,
+			&actor_type,
+			&actor_id,
+			&created_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		refund.Amount = money.New(amount, currency)
+		refund.ActorType = actor_type.String
+		refund.ActorId = actor_id.String
+		refund.CreatedAt = created_at.String
+
+		refunds[refund.Id] = &refund
+		resp.Refunds = append(resp.Refunds, &refund)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	itemRows, err := o.db.Query(ctx, `
+		SELECT
+			ri.refund_id,
+			ri.order_item_id,
+			ri.quantity,
+			ri.amount,
+			o.currency
+		FROM refund_items AS ri
+		JOIN refunds AS r ON r.id = ri.refund_id
+		JOIN orders AS o ON o.id = r.order_id
+		WHERE r.order_id = $1
+	`, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			refundId string
+			item     models.RefundItem
+			amount   int64
+			currency string
+		)
+
+		err = itemRows.Scan(&refundId, &item.OrderItemId, &item.Quantity, &amount, &currency)
+		if err != nil {
+			return nil, err
+		}
+
+		item.Amount = money.New(amount, currency)
+
+		if refund, ok := refunds[refundId]; ok {
+			refund.Items = append(refund.Items, &item)
+		}
+	}
+
+	resp.Count = len(resp.Refunds)
+
+	return resp, itemRows.Err()
+}
+
+// IssueInvoice numbers the invoice of an order that is not cancelled; an
+// order that already has one keeps it.
+func (o *orderRepo) IssueInvoice(ctx context.Context, req *models.IssueOrderInvoice) (*models.OrderInvoice, error) {
+	var status string
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT status FROM orders WHERE id = $1 FOR UPDATE", req.Id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrOrderNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	if status == models.OrderStatusCancelled {
+		return nil, storage.ErrOrderNotInvoiceable
+	}
+
+	invoice, err := o.issueInvoice(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// GetInvoice returns the invoice of an order without issuing one, so reading a
+// receipt never uses up a number.
+func (o *orderRepo) GetInvoice(ctx context.Context, req *models.OrderPrimaryKey) (*models.OrderInvoice, error) {
+
+	var invoice = models.OrderInvoice{OrderId: req.Id}
+
+	err := o.db.QueryRow(ctx, invoiceQuery, req.Id).Scan(&invoice.Number, &invoice.IssuedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrInvoiceNotIssued
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// invoiceQuery reads the invoice of an order, if it has one.
+const invoiceQuery = `
+	SELECT
+		number,
+		TO_CHAR(issued_at, 'YYYY-MM-DD HH24:MI:SS')
+	FROM order_invoices
+	WHERE order_id = $1
+`
+
+// issueInvoice numbers the invoice of an order inside tx, which must hold the
+// lock on the order row. The counter row stays locked until tx commits, so a
+// number is only ever taken by an invoice that is actually stored and the
+// sequence has no gaps.
+func (o *orderRepo) issueInvoice(ctx context.Context, tx pgx.Tx, req *models.IssueOrderInvoice) (*models.OrderInvoice, error) {
+
+	var invoice = models.OrderInvoice{OrderId: req.Id}
+
+	err := tx.QueryRow(ctx, invoiceQuery, req.Id).Scan(&invoice.Number, &invoice.IssuedAt)
+	if err == nil {
+		return &invoice, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx,
+		"UPDATE invoice_counter SET last_number = last_number + 1 RETURNING last_number",
+	).Scan(&invoice.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO order_invoices(number, order_id)
+		VALUES ($1, $2)
+		RETURNING TO_CHAR(issued_at, 'YYYY-MM-DD HH24:MI:SS')
+	`, invoice.Number, req.Id).Scan(&invoice.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.insertEvent(ctx, tx, &models.OrderEvent{
+		OrderId:   req.Id,
+		ActorType: req.ActorType,
+		ActorId:   req.ActorId,
+		Event:     models.OrderEventInvoiced,
+		Note:      fmt.Sprintf("invoice %d", invoice.Number),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
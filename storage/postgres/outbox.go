@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"app/api/models"
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type outboxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepo(db *pgxpool.Pool) *outboxRepo {
+	return &outboxRepo{
+		db: db,
+	}
+}
+
+// GetPending returns the events that are neither published nor parked, by id.
+// Ids are taken when an event is written, not when its transaction commits, so
+// events of concurrent transactions may come out in a different order than they
+// committed in. Writes to the same row wait for each other's row lock, which
+// keeps the events of one aggregate in order.
+func (o *outboxRepo) GetPending(ctx context.Context, req *models.GetPendingOutboxEvents) ([]*models.OutboxEvent, error) {
+
+	query := `
+		SELECT
+			id,
+			COALESCE(published_seq, 0),
+			aggregate_type,
+			aggregate_id,
+			event_type,
+			payload::TEXT,
+			attempts,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+	`
+
+	rows, err := o.db.Query(ctx, query, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxEvents(rows)
+}
+
+// AssignSeq gives the event the next published_seq, or returns the one it got
+// on an earlier attempt. The relay calls it right before handing the event
+// out; as a single relay does so one event at a time, the sequence follows the
+// order subscribers see events in.
+func (o *outboxRepo) AssignSeq(ctx context.Context, req *models.OutboxEventPrimaryKey) (int64, error) {
+
+	var seq int64
+
+	err := o.db.QueryRow(ctx, `
+		UPDATE outbox
+		SET published_seq = COALESCE(published_seq, nextval('outbox_published_seq'))
+		WHERE id = $1
+		RETURNING published_seq
+	`, req.Id).Scan(&seq)
+
+	return seq, err
+}
+
+func (o *outboxRepo) MarkPublished(ctx context.Context, req *models.OutboxEventPrimaryKey) error {
+
+	_, err := o.db.Exec(ctx,
+		"UPDATE outbox SET published_at = NOW() WHERE id = $1", req.Id,
+	)
+
+	return err
+}
+
+// MarkFailed counts a failed attempt, parking the event when req.Dead is set.
+func (o *outboxRepo) MarkFailed(ctx context.Context, req *models.FailOutboxEvent) error {
+
+	_, err := o.db.Exec(ctx, `
+		UPDATE outbox
+		SET
+			attempts = attempts + 1,
+			last_error = $2,
+			failed_at = CASE WHEN $3::BOOLEAN THEN NOW() END
+		WHERE id = $1
+	`, req.Id, req.Error, req.Dead)
+
+	return err
+}
+
+// GetList returns the events of an aggregate the relay handed out after the
+// given seq, in that order. It includes an event being relayed right now, so a
+// stream that starts listening before it reads the list misses nothing; the
+// stream drops what it gets twice.
+func (o *outboxRepo) GetList(ctx context.Context, req *models.GetListOutboxEventRequest) ([]*models.OutboxEvent, error) {
+
+	query := `
+		SELECT
+			id,
+			COALESCE(published_seq, 0),
+			aggregate_type,
+			aggregate_id,
+			event_type,
+			payload::TEXT,
+			attempts,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM outbox
+		WHERE aggregate_type = $1 AND published_seq > $2
+		ORDER BY published_seq
+		LIMIT $3
+	`
+
+	rows, err := o.db.Query(ctx, query, req.AggregateType, req.AfterSeq, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxEvents(rows)
+}
+
+func scanOutboxEvents(rows pgx.Rows) ([]*models.OutboxEvent, error) {
+
+	var events []*models.OutboxEvent
+
+	for rows.Next() {
+
+		var (
+			event      models.OutboxEvent
+			created_at sql.NullString
+		)
+
+		err := rows.Scan(
+			&event.Id,
+			&event.Seq,
+			&event.AggregateType,
+			&event.AggregateId,
+			&event.EventType,
+			&event.Payload,
+			&event.Attempts,
+			&created_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.CreatedAt = created_at.String
+
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// writeOutbox records an event for the row of table with the given id, using
+// the row itself as payload minus the omitted columns. It must run inside the
+// transaction of the change: after it for creates and updates, before it for
+// deletes. When the row does not exist nothing is written, so callers need not
+// check rows affected first.
+func writeOutbox(ctx context.Context, tx pgx.Tx, table, aggregate, action, id string, omit ...string) error {
+
+	if omit == nil {
+		omit = []string{}
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO outbox(
+			aggregate_type,
+			aggregate_id,
+			event_type,
+			payload
+		)
+		SELECT $1, $2, $3, to_jsonb(t) - $4::TEXT[]
+		FROM `+table+` AS t
+		WHERE t.id = $2
+	`, aggregate, id, aggregate+"."+action, omit)
+
+	return err
+}
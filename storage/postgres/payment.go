@@ -0,0 +1,267 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/money"
+	"app/storage"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type paymentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPaymentRepo(db *pgxpool.Pool) *paymentRepo {
+	return &paymentRepo{
+		db: db,
+	}
+}
+
+// Create records a pending payment. The order row is locked so that two
+// payments started at once cannot together exceed the outstanding balance,
+// which counts pending payments as already taken.
+func (p *paymentRepo) Create(ctx context.Context, req *models.CreatePayment) (string, error) {
+	var (
+		id        = uuid.New().String()
+		available int64
+		currency  string
+	)
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT
+			o.total_price - o.paid_amount - COALESCE((
+				SELECT SUM(amount)
+				FROM payments
+				WHERE order_id = o.id AND status = 'pending'
+			), 0)::BIGINT,
+			o.currency
+		FROM orders AS o
+		WHERE o.id = $1
+		FOR UPDATE
+	`
+
+	err = tx.QueryRow(ctx, query, req.OrderId).Scan(&available, &currency)
+	if err != nil {
+		return "", err
+	}
+
+	if req.Amount.Currency != currency {
+		return "", storage.ErrCurrencyMismatch
+	}
+
+	if req.Amount.Amount > available {
+		return "", storage.ErrPaymentExceedsBalance
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO payments(
+			id,
+			order_id,
+			provider,
+			status,
+			amount,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, id, req.OrderId, req.Provider, models.PaymentStatusPending, req.Amount.Amount)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "payments", models.OutboxAggregatePayment, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (p *paymentRepo) GetByID(ctx context.Context, req *models.PaymentPrimaryKey) (*models.Payment, error) {
+
+	query := `
+		SELECT
+			p.id,
+			p.order_id,
+			p.provider,
+			p.status,
+			p.amount,
+			o.currency,
+			p.external_id,
+			p.failure_reason,
+			TO_CHAR(p.created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(p.updated_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(p.confirmed_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM payments AS p
+		JOIN orders AS o ON o.id = p.order_id
+		WHERE p.id = $1
+	`
+
+	return scanPayment(p.db.QueryRow(ctx, query, req.Id))
+}
+
+func (p *paymentRepo) GetList(ctx context.Context, req *models.GetListPaymentRequest) (resp *models.GetListPaymentResponse, err error) {
+	resp = &models.GetListPaymentResponse{}
+
+	query := `
+		SELECT
+			p.id,
+			p.order_id,
+			p.provider,
+			p.status,
+			p.amount,
+			o.currency,
+			p.external_id,
+			p.failure_reason,
+			TO_CHAR(p.created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(p.updated_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(p.confirmed_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM payments AS p
+		JOIN orders AS o ON o.id = p.order_id
+		WHERE p.order_id = $1
+		ORDER BY p.created_at
+	`
+
+	rows, err := p.db.Query(ctx, query, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Payments = append(resp.Payments, payment)
+	}
+
+	resp.Count = len(resp.Payments)
+
+	return resp, rows.Err()
+}
+
+// UpdateStatus changes a pending payment and reconciles the paid amount of its
+// order with the payments that succeeded.
+func (p *paymentRepo) UpdateStatus(ctx context.Context, req *models.UpdatePaymentStatus) error {
+	var orderId string
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE payments
+		SET
+			status = $2,
+			external_id = COALESCE(NULLIF($3, ''), external_id),
+			failure_reason = NULLIF($4, ''),
+			confirmed_at = CASE WHEN $2 = 'succeeded' THEN NOW() END,
+			updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING order_id
+	`
+
+	err = tx.QueryRow(ctx, query, req.Id, req.Status, req.ExternalId, req.FailureReason).Scan(&orderId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ErrPaymentNotPending
+	} else if err != nil {
+		return err
+	}
+
+	err = writeOutbox(ctx, tx, "payments", models.OutboxAggregatePayment, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if req.Status == models.PaymentStatusSucceeded {
+		err = reconcilePayments(ctx, tx, orderId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// reconcilePayments recomputes the paid amount of an order from its payments.
+func reconcilePayments(ctx context.Context, tx pgx.Tx, orderId string) error {
+
+	_, err := tx.Exec(ctx, `
+		UPDATE orders
+		SET
+			paid_amount = COALESCE((
+				SELECT SUM(amount)
+				FROM payments
+				WHERE order_id = $1 AND status = 'succeeded'
+			), 0),
+			updated_at = NOW()
+		WHERE id = $1
+	`, orderId)
+	if err != nil {
+		return err
+	}
+
+	return writeOutbox(ctx, tx, "orders", models.OutboxAggregateOrder, models.OutboxActionUpdated, orderId)
+}
+
+type paymentScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row paymentScanner) (*models.Payment, error) {
+
+	var (
+		payment                              models.Payment
+		amount                               int64
+		currency, external_id                sql.NullString
+		failure_reason                       sql.NullString
+		created_at, updated_at, confirmed_at sql.NullString
+	)
+
+	err := row.Scan(
+		&payment.Id,
+		&payment.OrderId,
+		&payment.Provider,
+		&payment.Status,
+		&amount,
+		&currency,
+		&external_id,
+		&failure_reason,
+		&created_at,
+		&updated_at,
+		&confirmed_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.Amount = money.New(amount, currency.String)
+	payment.ExternalId = external_id.String
+	payment.FailureReason = failure_reason.String
+	payment.CreatedAt = created_at.String
+	payment.UpdatedAt = updated_at.String
+	payment.ConfirmedAt = confirmed_at.String
+
+	return &payment, nil
+}
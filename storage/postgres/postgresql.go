@@ -11,12 +11,22 @@ import (
 
 type Store struct {
 	db *pgxpool.Pool
+	taxInclusive bool
 	customer storage.CustomerRepoI
 	user storage.UserRepoI
 	courier storage.CourierRepoI
 	category storage.CategoryRepoI
 	product storage.ProductRepoI
 	order storage.OrderRepoI
+	warehouse storage.WarehouseRepoI
+	deliveryZone storage.DeliveryZoneRepoI
+	notification storage.NotificationRepoI
+	webhook storage.WebhookRepoI
+	outbox storage.OutboxRepoI
+	payment storage.PaymentRepoI
+	promotion storage.PromotionRepoI
+	taxClass storage.TaxClassRepoI
+	exchangeRate storage.ExchangeRateRepoI
 }
 
 func NewConnectPostgresql(cfg *config.Config) (storage.StorageI, error) {
@@ -43,12 +53,22 @@ func NewConnectPostgresql(cfg *config.Config) (storage.StorageI, error) {
 
 	return &Store{
 		db: pgpool,
+		taxInclusive: cfg.TaxInclusive,
 		customer: NewCustomerRepo(pgpool),
 		user: NewUserRepo(pgpool),
 		courier: NewCourierRepo(pgpool),
 		category: NewCategoryRepo(pgpool),
 		product: NewProductRepo(pgpool),
-		order: NewOrderRepo(pgpool),
+		order: NewOrderRepo(pgpool, cfg.TaxInclusive),
+		warehouse: NewWarehouseRepo(pgpool),
+		deliveryZone: NewDeliveryZoneRepo(pgpool),
+		notification: NewNotificationRepo(pgpool),
+		webhook: NewWebhookRepo(pgpool),
+		outbox: NewOutboxRepo(pgpool),
+		payment: NewPaymentRepo(pgpool),
+		promotion: NewPromotionRepo(pgpool),
+		taxClass: NewTaxClassRepo(pgpool),
+		exchangeRate: NewExchangeRateRepo(pgpool),
 	}, nil
 }
 
@@ -93,7 +113,70 @@ func (s *Store) Product() storage.ProductRepoI {
 
 func (s *Store) Order() storage.OrderRepoI {
 	if s.order == nil {
-		s.order = NewOrderRepo(s.db)
+		s.order = NewOrderRepo(s.db, s.taxInclusive)
 	}
 	return s.order
-}
\ No newline at end of file
+}
+
+func (s *Store) Warehouse() storage.WarehouseRepoI {
+	if s.warehouse == nil {
+		s.warehouse = NewWarehouseRepo(s.db)
+	}
+	return s.warehouse
+}
+
+func (s *Store) DeliveryZone() storage.DeliveryZoneRepoI {
+	if s.deliveryZone == nil {
+		s.deliveryZone = NewDeliveryZoneRepo(s.db)
+	}
+	return s.deliveryZone
+}
+
+func (s *Store) Notification() storage.NotificationRepoI {
+	if s.notification == nil {
+		s.notification = NewNotificationRepo(s.db)
+	}
+	return s.notification
+}
+
+func (s *Store) Webhook() storage.WebhookRepoI {
+	if s.webhook == nil {
+		s.webhook = NewWebhookRepo(s.db)
+	}
+	return s.webhook
+}
+
+func (s *Store) Outbox() storage.OutboxRepoI {
+	if s.outbox == nil {
+		s.outbox = NewOutboxRepo(s.db)
+	}
+	return s.outbox
+}
+
+func (s *Store) Payment() storage.PaymentRepoI {
+	if s.payment == nil {
+		s.payment = NewPaymentRepo(s.db)
+	}
+	return s.payment
+}
+
+func (s *Store) Promotion() storage.PromotionRepoI {
+	if s.promotion == nil {
+		s.promotion = NewPromotionRepo(s.db)
+	}
+	return s.promotion
+}
+
+func (s *Store) TaxClass() storage.TaxClassRepoI {
+	if s.taxClass == nil {
+		s.taxClass = NewTaxClassRepo(s.db)
+	}
+	return s.taxClass
+}
+
+func (s *Store) ExchangeRate() storage.ExchangeRateRepoI {
+	if s.exchangeRate == nil {
+		s.exchangeRate = NewExchangeRateRepo(s.db)
+	}
+	return s.exchangeRate
+}
@@ -3,12 +3,15 @@ package postgres
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/money"
+	"app/storage"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -33,22 +36,42 @@ func (c *productRepo) Create(ctx context.Context, req *models.CreateProduct) (st
 			id, 
 			name,
 			price,
+			currency,
 			category_id,
+			tax_class_id,
 			updated_at
 		)
-		VALUES (:id, :name, :price, :category_id, NOW())
+		VALUES (:id, :name, :price, :currency, :category_id, :tax_class_id, NOW())
 	`
 
 	params := map[string]interface{}{
-		"id":          id,
-		"name":        req.Name,
-		"price":       req.Price,
-		"category_id": helper.NewNullString(req.CategoryId),
+		"id":           id,
+		"name":         req.Name,
+		"price":        req.Price.Amount,
+		"currency":     req.Price.Currency,
+		"category_id":  helper.NewNullString(req.CategoryId),
+		"tax_class_id": helper.NewNullString(req.TaxClassId),
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	_, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "products", models.OutboxAggregateProduct, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -61,8 +84,11 @@ func (c *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 		query         string
 		id            sql.NullString
 		name          sql.NullString
-		price         sql.NullFloat64
+		price         sql.NullInt64
+		currency      sql.NullString
+		stock         sql.NullInt32
 		category_name sql.NullString
+		tax_class_id  sql.NullString
 		created_at    sql.NullString
 		updated_at    sql.NullString
 	)
@@ -72,7 +98,10 @@ func (c *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 			p.id,
 			p.name,
 			price,
+			p.currency,
+			(SELECT COALESCE(SUM(quantity), 0) FROM warehouse_stocks WHERE product_id = p.id),
 			COALESCE(c.name, ''),
+			p.tax_class_id,
 			TO_CHAR(p.created_at, 'YYYY-MM-DD HH24-MI-SS'),
 			TO_CHAR(p.updated_at, 'YYYY-MM-DD HH24-MI-SS')
 		FROM products AS p
@@ -84,7 +113,10 @@ func (c *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 		&id,
 		&name,
 		&price,
+		&currency,
+		&stock,
 		&category_name,
+		&tax_class_id,
 		&created_at,
 		&updated_at,
 	)
@@ -97,12 +129,14 @@ func (c *productRepo) GetByID(ctx context.Context, req *models.ProductPrimaryKey
 	}
 
 	return &models.Product{
-		Id:        id.String,
-		Name:      name.String,
-		Price:     price.Float64,
-		Category:  category,
-		CreatedAt: created_at.String,
-		UpdatedAt: updated_at.String,
+		Id:            id.String,
+		Name:          name.String,
+		Price:         money.New(price.Int64, currency.String),
+		StockQuantity: stock.Int32,
+		TaxClassId:    tax_class_id.String,
+		Category:      category,
+		CreatedAt:     created_at.String,
+		UpdatedAt:     updated_at.String,
 	}, nil
 }
 
@@ -121,7 +155,10 @@ func (c *productRepo) GetList(ctx context.Context, req *models.GetListProductReq
 			p.id, 
 			p.name,
 			p.price,
+			p.currency,
+			(SELECT COALESCE(SUM(quantity), 0) FROM warehouse_stocks WHERE product_id = p.id),
 			c.name,
+			p.tax_class_id,
 			p.created_at,
 			p.updated_at
 		FROM products AS p
@@ -153,14 +190,18 @@ func (c *productRepo) GetList(ctx context.Context, req *models.GetListProductReq
 		var product models.Product
 		var category models.ReturnCategory
 
-		var id, name, category_name, created_at, updated_at sql.NullString
-		var price sql.NullFloat64
+		var id, name, currency, category_name, tax_class_id, created_at, updated_at sql.NullString
+		var price sql.NullInt64
+		var stock sql.NullInt32
 
 		err = rows.Scan(
 			&id,
 			&name,
-			&price.Float64,
+			&price,
+			&currency,
+			&stock,
 			&category_name,
+			&tax_class_id,
 			&created_at,
 			&updated_at,
 		)
@@ -168,7 +209,9 @@ func (c *productRepo) GetList(ctx context.Context, req *models.GetListProductReq
 		product.Id = id.String
 		product.Name = name.String
 		category.Name = category_name.String
-		product.Price = price.Float64
+		product.Price = money.New(price.Int64, currency.String)
+		product.StockQuantity = stock.Int32
+		product.TaxClassId = tax_class_id.String
 		product.CreatedAt = created_at.String
 		product.UpdatedAt = updated_at.String
 
@@ -197,21 +240,41 @@ func (c *productRepo) Update(ctx context.Context, req *models.UpdateProduct) (in
 		SET 
 			name = :name,
 			price = :price,
+			currency = :currency,
 			category_id = :category_id,
+			tax_class_id = :tax_class_id,
 			updated_at = now()
 		WHERE id = :id
 	`
 
 	params = map[string]interface{}{
-		"id":          req.Id,
-		"name":        req.Name,
-		"price":       req.Price,
-		"category_id": req.CategoryId,
+		"id":           req.Id,
+		"name":         req.Name,
+		"price":        req.Price.Amount,
+		"currency":     req.Price.Currency,
+		"category_id":  req.CategoryId,
+		"tax_class_id": helper.NewNullString(req.TaxClassId),
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "products", models.OutboxAggregateProduct, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -230,6 +293,11 @@ func (c *productRepo) Patch(ctx context.Context, req *models.PatchRequest) (int6
 		return 0, errors.New("no fields")
 	}
 
+	err := flattenMoneyFields(req.Fields, "price")
+	if err != nil {
+		return 0, err
+	}
+
 	for key := range req.Fields {
 		set += fmt.Sprintf(" %s = :%s, ", key, key)
 	}
@@ -246,7 +314,23 @@ func (c *productRepo) Patch(ctx context.Context, req *models.PatchRequest) (int6
 
 	query, args := helper.ReplaceQueryParams(query, req.Fields)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "products", models.OutboxAggregateProduct, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -256,7 +340,18 @@ func (c *productRepo) Patch(ctx context.Context, req *models.PatchRequest) (int6
 
 func (c *productRepo) Delete(ctx context.Context, req *models.ProductPrimaryKey) error {
 
-	_, err := c.db.Exec(ctx,
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "products", models.OutboxAggregateProduct, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
 		"DELETE FROM products WHERE id = $1", req.Id,
 	)
 
@@ -264,5 +359,293 @@ func (c *productRepo) Delete(ctx context.Context, req *models.ProductPrimaryKey)
 		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
+}
+
+func (c *productRepo) AdjustStock(ctx context.Context, req *models.AdjustStock) error {
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = changeStock(ctx, tx, &models.StockMovement{
+		ProductId:   req.ProductId,
+		WarehouseId: req.WarehouseId,
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+		Note:        req.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// TransferStock moves stock between two warehouses as a pair of movements sharing one transfer id.
+func (c *productRepo) TransferStock(ctx context.Context, req *models.TransferStock) error {
+	var transferId = uuid.New().String()
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = changeStock(ctx, tx, &models.StockMovement{
+		ProductId:   req.ProductId,
+		WarehouseId: req.FromWarehouseId,
+		TransferId:  transferId,
+		Quantity:    -req.Quantity,
+		Reason:      models.StockReasonTransferOut,
+		Note:        req.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = changeStock(ctx, tx, &models.StockMovement{
+		ProductId:   req.ProductId,
+		WarehouseId: req.ToWarehouseId,
+		TransferId:  transferId,
+		Quantity:    req.Quantity,
+		Reason:      models.StockReasonTransferIn,
+		Note:        req.Note,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (c *productRepo) GetStock(ctx context.Context, req *models.ProductPrimaryKey) (resp *models.GetProductStockResponse, err error) {
+	resp = &models.GetProductStockResponse{ProductId: req.Id}
+
+	query := `
+		SELECT
+			w.id,
+			w.name,
+			COALESCE(ws.quantity, 0),
+			TO_CHAR(ws.updated_at, 'YYYY-MM-DD HH24:MI:SS')
+		FROM warehouses AS w
+		LEFT JOIN warehouse_stocks AS ws ON ws.warehouse_id = w.id AND ws.product_id = $1
+		ORDER BY w.name
+	`
+
+	rows, err := c.db.Query(ctx, query, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			warehouse_id   sql.NullString
+			warehouse_name sql.NullString
+			quantity       sql.NullInt32
+			updated_at     sql.NullString
+		)
+
+		err = rows.Scan(
+			&warehouse_id,
+			&warehouse_name,
+			&quantity,
+			&updated_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Total += quantity.Int32
+		resp.Warehouses = append(resp.Warehouses, &models.WarehouseStock{
+			WarehouseId:   warehouse_id.String,
+			WarehouseName: warehouse_name.String,
+			Quantity:      quantity.Int32,
+			UpdatedAt:     updated_at.String,
+		})
+	}
+
+	return resp, nil
+}
+
+func (c *productRepo) GetStockMovements(ctx context.Context, req *models.GetListStockMovementRequest) (resp *models.GetListStockMovementResponse, err error) {
+	resp = &models.GetListStockMovementResponse{}
+
+	var (
+		query  string
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+	)
+
+	query = `
+		SELECT
+			id,
+			product_id,
+			warehouse_id,
+			order_id,
+			transfer_id,
+			quantity,
+			balance_after,
+			reason,
+			note,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24:MI:SS.MS')
+		FROM stock_movements
+		WHERE product_id = $1
+		ORDER BY created_at DESC
+	`
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += offset + limit
+
+	rows, err := c.db.Query(ctx, query, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id            sql.NullString
+			product_id    sql.NullString
+			warehouse_id  sql.NullString
+			order_id      sql.NullString
+			transfer_id   sql.NullString
+			quantity      sql.NullInt32
+			balance_after sql.NullInt32
+			reason        sql.NullString
+			note          sql.NullString
+			created_at    sql.NullString
+		)
+
+		err = rows.Scan(
+			&id,
+			&product_id,
+			&warehouse_id,
+			&order_id,
+			&transfer_id,
+			&quantity,
+			&balance_after,
+			&reason,
+			&note,
+			&created_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Movements = append(resp.Movements, &models.StockMovement{
+			Id:           id.String,
+			ProductId:    product_id.String,
+			WarehouseId:  warehouse_id.String,
+			OrderId:      order_id.String,
+			TransferId:   transfer_id.String,
+			Quantity:     quantity.Int32,
+			BalanceAfter: balance_after.Int32,
+			Reason:       reason.String,
+			Note:         note.String,
+			CreatedAt:    created_at.String,
+		})
+	}
+
+	resp.Count = len(resp.Movements)
+
+	return resp, nil
+}
+
+// changeStock applies req.Quantity to the product stock of one warehouse, records the
+// movement in the ledger and emits it to the outbox. It must run inside the caller's
+// transaction so the ledger never drifts from the balance.
+func changeStock(ctx context.Context, tx pgx.Tx, req *models.StockMovement) error {
+	var (
+		id     = uuid.New().String()
+		exists bool
+		stock  sql.NullInt32
+	)
+
+	err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", req.ProductId).Scan(&exists)
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("product not found: %s", req.ProductId)
+	}
+
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1)", req.WarehouseId).Scan(&exists)
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("warehouse not found: %s", req.WarehouseId)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO warehouse_stocks(warehouse_id, product_id, quantity, updated_at)
+		VALUES ($1, $2, 0, NOW())
+		ON CONFLICT (warehouse_id, product_id) DO NOTHING
+	`, req.WarehouseId, req.ProductId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx,
+		"SELECT quantity FROM warehouse_stocks WHERE warehouse_id = $1 AND product_id = $2 FOR UPDATE",
+		req.WarehouseId, req.ProductId,
+	).Scan(&stock)
+	if err != nil {
+		return err
+	}
+
+	balance := stock.Int32 + req.Quantity
+	if balance < 0 {
+		return fmt.Errorf("%w: product %s has %d in warehouse %s, requested %d",
+			storage.ErrInsufficientStock, req.ProductId, stock.Int32, req.WarehouseId, -req.Quantity,
+		)
+	}
+
+	_, err = tx.Exec(ctx,
+		"UPDATE warehouse_stocks SET quantity = $3, updated_at = NOW() WHERE warehouse_id = $1 AND product_id = $2",
+		req.WarehouseId, req.ProductId, balance,
+	)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO stock_movements(
+			id,
+			product_id,
+			warehouse_id,
+			order_id,
+			transfer_id,
+			quantity,
+			balance_after,
+			reason,
+			note
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = tx.Exec(ctx, query,
+		id,
+		req.ProductId,
+		req.WarehouseId,
+		helper.NewNullString(req.OrderId),
+		helper.NewNullString(req.TransferId),
+		req.Quantity,
+		balance,
+		req.Reason,
+		helper.NewNullString(req.Note),
+	)
+	if err != nil {
+		return err
+	}
+
+	return writeOutbox(ctx, tx, "stock_movements", models.OutboxAggregateStock, models.OutboxActionCreated, id)
 }
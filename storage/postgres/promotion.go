@@ -0,0 +1,498 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/pkg/money"
+	"app/storage"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type promotionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPromotionRepo(db *pgxpool.Pool) *promotionRepo {
+	return &promotionRepo{
+		db: db,
+	}
+}
+
+// promotionColumns is shared by every promotion select; used_count leaves out
+// redemptions of cancelled orders.
+const promotionColumns = `
+	p.id,
+	p.code,
+	p.name,
+	p.discount_type,
+	p.value,
+	p.amount,
+	p.max_discount,
+	p.min_order_amount,
+	p.currency,
+	p.usage_limit,
+	p.per_customer_limit,
+	TO_CHAR(p.starts_at, 'YYYY-MM-DD HH24:MI:SS'),
+	TO_CHAR(p.ends_at, 'YYYY-MM-DD HH24:MI:SS'),
+	p.product_ids,
+	p.category_ids,
+	p.stackable,
+	p.active,
+	(
+		SELECT COUNT(*)
+		FROM order_discounts AS d
+		JOIN orders AS o ON o.id = d.order_id
+		WHERE d.promotion_id = p.id AND o.status <> 'cancelled'
+	),
+	TO_CHAR(p.created_at, 'YYYY-MM-DD HH24-MI-SS'),
+	TO_CHAR(p.updated_at, 'YYYY-MM-DD HH24-MI-SS')
+`
+
+func (p *promotionRepo) Create(ctx context.Context, req *models.CreatePromotion) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	productIds, categoryIds, err := marshalPromotionScope(req.ProductIds, req.CategoryIds)
+	if err != nil {
+		return "", err
+	}
+
+	query = `
+		INSERT INTO promotions(
+			id,
+			code,
+			name,
+			discount_type,
+			value,
+			amount,
+			max_discount,
+			min_order_amount,
+			currency,
+			usage_limit,
+			per_customer_limit,
+			starts_at,
+			ends_at,
+			product_ids,
+			category_ids,
+			stackable,
+			active,
+			updated_at
+		)
+		VALUES (
+			:id, :code, :name, :discount_type, :value, :amount, :max_discount, :min_order_amount, :currency,
+			:usage_limit, :per_customer_limit, :starts_at, :ends_at,
+			:product_ids, :category_ids, :stackable, :active, NOW()
+		)
+	`
+
+	params := map[string]interface{}{
+		"id":                 id,
+		"code":               helper.NewNullString(req.Code),
+		"name":               req.Name,
+		"discount_type":      req.DiscountType,
+		"value":              req.Value,
+		"amount":             req.Amount.Amount,
+		"max_discount":       req.MaxDiscount.Amount,
+		"min_order_amount":   req.MinOrderAmount.Amount,
+		"currency":           req.Amount.Currency,
+		"usage_limit":        req.UsageLimit,
+		"per_customer_limit": req.PerCustomerLimit,
+		"starts_at":          helper.NewNullString(req.StartsAt),
+		"ends_at":            helper.NewNullString(req.EndsAt),
+		"product_ids":        productIds,
+		"category_ids":       categoryIds,
+		"stackable":          req.Stackable,
+		"active":             req.Active,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return "", storage.ErrPromoCodeTaken
+	} else if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "promotions", models.OutboxAggregatePromotion, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (p *promotionRepo) GetByID(ctx context.Context, req *models.PromotionPrimaryKey) (*models.Promotion, error) {
+
+	query := `
+		SELECT ` + promotionColumns + `
+		FROM promotions AS p
+		WHERE p.id = $1
+	`
+
+	return scanPromotion(p.db.QueryRow(ctx, query, req.Id))
+}
+
+func (p *promotionRepo) GetList(ctx context.Context, req *models.GetListPromotionRequest) (resp *models.GetListPromotionResponse, err error) {
+	resp = &models.GetListPromotionResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT ` + promotionColumns + `
+		FROM promotions AS p
+	`
+
+	if len(req.Search) > 0 {
+		filter += " AND (p.name ILIKE '%' || :search || '%' OR p.code ILIKE '%' || :search || '%') "
+		params["search"] = req.Search
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + " ORDER BY p.created_at DESC " + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Promotions = append(resp.Promotions, promotion)
+	}
+
+	resp.Count = len(resp.Promotions)
+
+	return resp, nil
+}
+
+func (p *promotionRepo) Update(ctx context.Context, req *models.UpdatePromotion) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	productIds, categoryIds, err := marshalPromotionScope(req.ProductIds, req.CategoryIds)
+	if err != nil {
+		return 0, err
+	}
+
+	query = `
+		UPDATE
+			promotions
+		SET
+			code = :code,
+			name = :name,
+			discount_type = :discount_type,
+			value = :value,
+			amount = :amount,
+			max_discount = :max_discount,
+			min_order_amount = :min_order_amount,
+			currency = :currency,
+			usage_limit = :usage_limit,
+			per_customer_limit = :per_customer_limit,
+			starts_at = :starts_at,
+			ends_at = :ends_at,
+			product_ids = :product_ids,
+			category_ids = :category_ids,
+			stackable = :stackable,
+			active = :active,
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":                 req.Id,
+		"code":               helper.NewNullString(req.Code),
+		"name":               req.Name,
+		"discount_type":      req.DiscountType,
+		"value":              req.Value,
+		"amount":             req.Amount.Amount,
+		"max_discount":       req.MaxDiscount.Amount,
+		"min_order_amount":   req.MinOrderAmount.Amount,
+		"currency":           req.Amount.Currency,
+		"usage_limit":        req.UsageLimit,
+		"per_customer_limit": req.PerCustomerLimit,
+		"starts_at":          helper.NewNullString(req.StartsAt),
+		"ends_at":            helper.NewNullString(req.EndsAt),
+		"product_ids":        productIds,
+		"category_ids":       categoryIds,
+		"stackable":          req.Stackable,
+		"active":             req.Active,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return 0, storage.ErrPromoCodeTaken
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "promotions", models.OutboxAggregatePromotion, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (p *promotionRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64, error) {
+
+	var (
+		query string
+		set   string
+	)
+
+	if len(req.Fields) <= 0 {
+		return 0, errors.New("no fields")
+	}
+
+	err := flattenMoneyFields(req.Fields, "amount", "max_discount", "min_order_amount")
+	if err != nil {
+		return 0, err
+	}
+
+	for key := range req.Fields {
+		set += fmt.Sprintf(" %s = :%s, ", key, key)
+	}
+
+	for _, key := range []string{"product_ids", "category_ids"} {
+		if ids, ok := req.Fields[key]; ok {
+			body, err := json.Marshal(ids)
+			if err != nil {
+				return 0, err
+			}
+			req.Fields[key] = body
+		}
+	}
+
+	for _, key := range []string{"code", "starts_at", "ends_at"} {
+		if value, ok := req.Fields[key].(string); ok {
+			req.Fields[key] = helper.NewNullString(value)
+		}
+	}
+
+	query = `
+		UPDATE
+			promotions
+		SET
+	` + set + ` updated_at = now()
+		WHERE id = :id
+	`
+
+	req.Fields["id"] = req.ID
+
+	query, args := helper.ReplaceQueryParams(query, req.Fields)
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return 0, storage.ErrPromoCodeTaken
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "promotions", models.OutboxAggregatePromotion, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// Delete removes a promotion that was never redeemed; used ones stay on the
+// orders that reference them and can only be deactivated.
+func (p *promotionRepo) Delete(ctx context.Context, req *models.PromotionPrimaryKey) error {
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var used bool
+
+	err = tx.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM order_discounts WHERE promotion_id = $1)", req.Id,
+	).Scan(&used)
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return storage.ErrPromotionInUse
+	}
+
+	err = writeOutbox(ctx, tx, "promotions", models.OutboxAggregatePromotion, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM promotions WHERE id = $1", req.Id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func marshalPromotionScope(productIds, categoryIds []string) ([]byte, []byte, error) {
+
+	if productIds == nil {
+		productIds = []string{}
+	}
+
+	if categoryIds == nil {
+		categoryIds = []string{}
+	}
+
+	products, err := json.Marshal(productIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	categories, err := json.Marshal(categoryIds)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return products, categories, nil
+}
+
+type promotionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row promotionScanner) (*models.Promotion, error) {
+
+	var (
+		promotion                                   models.Promotion
+		code, currency, starts_at, ends_at          sql.NullString
+		created_at, updated_at                      sql.NullString
+		product_ids, category_ids                   []byte
+		value                                       sql.NullFloat64
+		amount, max_discount, min_order_amount      sql.NullInt64
+		usage_limit, per_customer_limit, used_count sql.NullInt64
+		stackable, active                           sql.NullBool
+	)
+
+	err := row.Scan(
+		&promotion.Id,
+		&code,
+		&promotion.Name,
+		&promotion.DiscountType,
+		&value,
+		&amount,
+		&max_discount,
+		&min_order_amount,
+		&currency,
+		&usage_limit,
+		&per_customer_limit,
+		&starts_at,
+		&ends_at,
+		&product_ids,
+		&category_ids,
+		&stackable,
+		&active,
+		&used_count,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.Code = code.String
+	promotion.Value = value.Float64
+	promotion.Amount = money.New(amount.Int64, currency.String)
+	promotion.MaxDiscount = money.New(max_discount.Int64, currency.String)
+	promotion.MinOrderAmount = money.New(min_order_amount.Int64, currency.String)
+	promotion.UsageLimit = int(usage_limit.Int64)
+	promotion.PerCustomerLimit = int(per_customer_limit.Int64)
+	promotion.StartsAt = starts_at.String
+	promotion.EndsAt = ends_at.String
+	promotion.Stackable = stackable.Bool
+	promotion.Active = active.Bool
+	promotion.UsedCount = int(used_count.Int64)
+	promotion.CreatedAt = created_at.String
+	promotion.UpdatedAt = updated_at.String
+
+	err = json.Unmarshal(product_ids, &promotion.ProductIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(category_ids, &promotion.CategoryIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
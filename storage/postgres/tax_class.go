@@ -0,0 +1,315 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"app/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type taxClassRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTaxClassRepo(db *pgxpool.Pool) *taxClassRepo {
+	return &taxClassRepo{
+		db: db,
+	}
+}
+
+func (t *taxClassRepo) Create(ctx context.Context, req *models.CreateTaxClass) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	query = `
+		INSERT INTO tax_classes(
+			id,
+			name,
+			rate,
+			updated_at
+		)
+		VALUES (:id, :name, :rate, NOW())
+	`
+
+	params := map[string]interface{}{
+		"id":   id,
+		"name": req.Name,
+		"rate": req.Rate,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "tax_classes", models.OutboxAggregateTaxClass, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (t *taxClassRepo) GetByID(ctx context.Context, req *models.TaxClassPrimaryKey) (*models.TaxClass, error) {
+	var (
+		query      string
+		id         sql.NullString
+		name       sql.NullString
+		rate       sql.NullFloat64
+		created_at sql.NullString
+		updated_at sql.NullString
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			rate,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM tax_classes
+		WHERE id = $1
+	`
+
+	err := t.db.QueryRow(ctx, query, req.Id).Scan(
+		&id,
+		&name,
+		&rate,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TaxClass{
+		Id:        id.String,
+		Name:      name.String,
+		Rate:      rate.Float64,
+		CreatedAt: created_at.String,
+		UpdatedAt: updated_at.String,
+	}, nil
+}
+
+func (t *taxClassRepo) GetList(ctx context.Context, req *models.GetListTaxClassRequest) (resp *models.GetListTaxClassResponse, err error) {
+	resp = &models.GetListTaxClassResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			rate,
+			created_at,
+			updated_at
+		FROM tax_classes
+	`
+
+	if len(req.Search) > 0 {
+		filter += " AND name ILIKE '%' || :search || '%' "
+		params["search"] = req.Search
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := t.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var taxClass models.TaxClass
+
+		var id, name, created_at, updated_at sql.NullString
+		var rate sql.NullFloat64
+
+		err = rows.Scan(
+			&id,
+			&name,
+			&rate,
+			&created_at,
+			&updated_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		taxClass.Id = id.String
+		taxClass.Name = name.String
+		taxClass.Rate = rate.Float64
+		taxClass.CreatedAt = created_at.String
+		taxClass.UpdatedAt = updated_at.String
+
+		resp.TaxClasses = append(resp.TaxClasses, &taxClass)
+	}
+
+	resp.Count = len(resp.TaxClasses)
+
+	return resp, nil
+}
+
+// Update changes a tax class. Orders already placed keep the rate they
+// snapshotted.
+func (t *taxClassRepo) Update(ctx context.Context, req *models.UpdateTaxClass) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	query = `
+		UPDATE
+			tax_classes
+		SET
+			name = :name,
+			rate = :rate,
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":   req.Id,
+		"name": req.Name,
+		"rate": req.Rate,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "tax_classes", models.OutboxAggregateTaxClass, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (t *taxClassRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64, error) {
+
+	var (
+		query string
+		set   string
+	)
+
+	if len(req.Fields) <= 0 {
+		return 0, errors.New("no fields")
+	}
+
+	for key := range req.Fields {
+		set += fmt.Sprintf(" %s = :%s, ", key, key)
+	}
+
+	query = `
+		UPDATE
+			tax_classes
+		SET
+	` + set + ` updated_at = now()
+		WHERE id = :id
+	`
+
+	req.Fields["id"] = req.ID
+
+	query, args := helper.ReplaceQueryParams(query, req.Fields)
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "tax_classes", models.OutboxAggregateTaxClass, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (t *taxClassRepo) Delete(ctx context.Context, req *models.TaxClassPrimaryKey) error {
+
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "tax_classes", models.OutboxAggregateTaxClass, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM tax_classes WHERE id = $1", req.Id,
+	)
+	if isForeignKeyViolation(err) {
+		return storage.ErrTaxClassInUse
+	} else if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -3,12 +3,15 @@ package postgres
 import (
 	"app/api/models"
 	"app/pkg/helper"
+	"app/pkg/security"
+	"app/storage"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -28,25 +31,61 @@ func (c *userRepo) Create(ctx context.Context, req *models.CreateUser) (string,
 		id    = uuid.New().String()
 	)
 
+	var passwordHash string
+
+	if len(req.Password) > 0 {
+		hash, err := security.HashPassword(req.Password)
+		if err != nil {
+			return "", err
+		}
+		passwordHash = hash
+	}
+
 	query = `
 		INSERT INTO users(
 			id, 
 			name,
 			phone,
+			login,
+			password_hash,
+			role,
+			courier_id,
 			updated_at
 		)
-		VALUES (:id, :name, :phone, NOW())
+		VALUES (:id, :name, :phone, :login, :password_hash, :role, :courier_id, NOW())
 	`
 
 	params := map[string]interface{}{
-		"id":    id,
-		"name":  req.Name,
-		"phone": req.Phone,
+		"id":            id,
+		"name":          req.Name,
+		"phone":         req.Phone,
+		"login":         helper.NewNullString(req.Login),
+		"password_hash": helper.NewNullString(passwordHash),
+		"role":          req.Role,
+		"courier_id":    helper.NewNullString(req.CourierId),
 	}
 
 	query, args := helper.ReplaceQueryParams(query, params)
 
-	_, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return "", storage.ErrLoginTaken
+	} else if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "users", models.OutboxAggregateUser, models.OutboxActionCreated, id, "password_hash")
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -60,6 +99,9 @@ func (c *userRepo) GetByID(ctx context.Context, req *models.UserPrimaryKey) (*mo
 		id         sql.NullString
 		name       sql.NullString
 		phone      sql.NullString
+		login      sql.NullString
+		role       sql.NullString
+		courier_id sql.NullString
 		created_at sql.NullString
 		updated_at sql.NullString
 	)
@@ -69,6 +111,9 @@ func (c *userRepo) GetByID(ctx context.Context, req *models.UserPrimaryKey) (*mo
 			id,
 			name,
 			phone,
+			login,
+			role,
+			courier_id,
 			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
 			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
 		FROM users
@@ -79,6 +124,9 @@ func (c *userRepo) GetByID(ctx context.Context, req *models.UserPrimaryKey) (*mo
 		&id,
 		&name,
 		&phone,
+		&login,
+		&role,
+		&courier_id,
 		&created_at,
 		&updated_at,
 	)
@@ -91,6 +139,9 @@ func (c *userRepo) GetByID(ctx context.Context, req *models.UserPrimaryKey) (*mo
 		Id:        id.String,
 		Name:      name.String,
 		Phone:     phone.String,
+		Login:     login.String,
+		Role:      role.String,
+		CourierId: courier_id.String,
 		CreatedAt: created_at.String,
 		UpdatedAt: updated_at.String,
 	}, nil
@@ -111,6 +162,9 @@ func (c *userRepo) GetList(ctx context.Context, req *models.GetListUserRequest)
 			id, 
 			name,
 			phone,
+			login,
+			role,
+			courier_id,
 			created_at,
 			updated_at
 		FROM users	
@@ -140,12 +194,15 @@ func (c *userRepo) GetList(ctx context.Context, req *models.GetListUserRequest)
 
 		var user models.User
 
-		var id, name, phone, created_at, updated_at sql.NullString
+		var id, name, phone, login, role, courier_id, created_at, updated_at sql.NullString
 
 		err = rows.Scan(
 			&id,
 			&name,
 			&phone,
+			&login,
+			&role,
+			&courier_id,
 			&created_at,
 			&updated_at,
 		)
@@ -153,6 +210,9 @@ func (c *userRepo) GetList(ctx context.Context, req *models.GetListUserRequest)
 		user.Id = id.String
 		user.Name = name.String
 		user.Phone = phone.String
+		user.Login = login.String
+		user.Role = role.String
+		user.CourierId = courier_id.String
 		user.CreatedAt = created_at.String
 		user.UpdatedAt = updated_at.String
 
@@ -170,29 +230,65 @@ func (c *userRepo) GetList(ctx context.Context, req *models.GetListUserRequest)
 
 func (c *userRepo) Update(ctx context.Context, req *models.UpdateUser) (int64, error) {
 	var (
-		query  string
-		params map[string]interface{}
+		query    string
+		params   map[string]interface{}
+		password string
 	)
 
+	params = map[string]interface{}{
+		"id":         req.Id,
+		"name":       req.Name,
+		"phone":      req.Phone,
+		"login":      helper.NewNullString(req.Login),
+		"role":       req.Role,
+		"courier_id": helper.NewNullString(req.CourierId),
+	}
+
+	if len(req.Password) > 0 {
+		passwordHash, err := security.HashPassword(req.Password)
+		if err != nil {
+			return 0, err
+		}
+
+		password = " password_hash = :password_hash, "
+		params["password_hash"] = passwordHash
+	}
+
 	query = `
 		UPDATE 
 			users
 		SET 
 			name = :name,
 			phone = :phone,
+			login = :login,
+			role = :role,
+			courier_id = :courier_id,
+	` + password + `
 			updated_at = now()
 		WHERE id = :id
 	`
 
-	params = map[string]interface{}{
-		"id":    req.Id,
-		"name":  req.Name,
-		"phone": req.Phone,
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
 	}
+	defer tx.Rollback(ctx)
 
-	query, args := helper.ReplaceQueryParams(query, params)
+	result, err := tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return 0, storage.ErrLoginTaken
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "users", models.OutboxAggregateUser, models.OutboxActionUpdated, req.Id, "password_hash")
+	if err != nil {
+		return 0, err
+	}
 
-	result, err := c.db.Exec(ctx, query, args...)
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -211,6 +307,19 @@ func (c *userRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64,
 		return 0, errors.New("no fields")
 	}
 
+	// the hash is only ever written from a plain password
+	delete(req.Fields, "password_hash")
+
+	if password, ok := req.Fields["password"]; ok {
+		passwordHash, err := security.HashPassword(fmt.Sprint(password))
+		if err != nil {
+			return 0, err
+		}
+
+		delete(req.Fields, "password")
+		req.Fields["password_hash"] = passwordHash
+	}
+
 	for key := range req.Fields {
 		set += fmt.Sprintf(" %s = :%s, ", key, key)
 	}
@@ -227,7 +336,25 @@ func (c *userRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64,
 
 	query, args := helper.ReplaceQueryParams(query, req.Fields)
 
-	result, err := c.db.Exec(ctx, query, args...)
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return 0, storage.ErrLoginTaken
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "users", models.OutboxAggregateUser, models.OutboxActionUpdated, req.ID, "password_hash")
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -237,7 +364,18 @@ func (c *userRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64,
 
 func (c *userRepo) Delete(ctx context.Context, req *models.UserPrimaryKey) error {
 
-	_, err := c.db.Exec(ctx,
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "users", models.OutboxAggregateUser, models.OutboxActionDeleted, req.Id, "password_hash")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
 		"DELETE FROM users WHERE id = $1", req.Id,
 	)
 
@@ -245,5 +383,58 @@ func (c *userRepo) Delete(ctx context.Context, req *models.UserPrimaryKey) error
 		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
+}
+
+func (c *userRepo) GetByLogin(ctx context.Context, req *models.UserLoginKey) (*models.UserCredentials, error) {
+	var (
+		query         string
+		id            sql.NullString
+		login         sql.NullString
+		password_hash sql.NullString
+		role          sql.NullString
+		courier_id    sql.NullString
+	)
+
+	query = `
+		SELECT
+			id,
+			login,
+			password_hash,
+			role,
+			courier_id
+		FROM users
+		WHERE login = $1
+	`
+
+	err := c.db.QueryRow(ctx, query, req.Login).Scan(
+		&id,
+		&login,
+		&password_hash,
+		&role,
+		&courier_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserCredentials{
+		Id:           id.String,
+		Login:        login.String,
+		PasswordHash: password_hash.String,
+		Role:         role.String,
+		CourierId:    courier_id.String,
+	}, nil
+}
+
+// isUniqueViolation reports whether err is a postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a postgres foreign key violation.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
 }
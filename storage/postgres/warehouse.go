@@ -0,0 +1,312 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type warehouseRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWarehouseRepo(db *pgxpool.Pool) *warehouseRepo {
+	return &warehouseRepo{
+		db: db,
+	}
+}
+
+func (w *warehouseRepo) Create(ctx context.Context, req *models.CreateWarehouse) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	query = `
+		INSERT INTO warehouses(
+			id,
+			name,
+			address,
+			updated_at
+		)
+		VALUES (:id, :name, :address, NOW())
+	`
+
+	params := map[string]interface{}{
+		"id":      id,
+		"name":    req.Name,
+		"address": helper.NewNullString(req.Address),
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "warehouses", models.OutboxAggregateWarehouse, models.OutboxActionCreated, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (w *warehouseRepo) GetByID(ctx context.Context, req *models.WarehousePrimaryKey) (*models.Warehouse, error) {
+	var (
+		query      string
+		id         sql.NullString
+		name       sql.NullString
+		address    sql.NullString
+		created_at sql.NullString
+		updated_at sql.NullString
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			address,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM warehouses
+		WHERE id = $1
+	`
+
+	err := w.db.QueryRow(ctx, query, req.Id).Scan(
+		&id,
+		&name,
+		&address,
+		&created_at,
+		&updated_at,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Warehouse{
+		Id:        id.String,
+		Name:      name.String,
+		Address:   address.String,
+		CreatedAt: created_at.String,
+		UpdatedAt: updated_at.String,
+	}, nil
+}
+
+func (w *warehouseRepo) GetList(ctx context.Context, req *models.GetListWarehouseRequest) (resp *models.GetListWarehouseResponse, err error) {
+	resp = &models.GetListWarehouseResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT
+			id,
+			name,
+			address,
+			created_at,
+			updated_at
+		FROM warehouses
+	`
+
+	if len(req.Search) > 0 {
+		filter += " AND name ILIKE '%' || :search || '%' "
+		params["search"] = req.Search
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := w.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var warehouse models.Warehouse
+
+		var id, name, address, created_at, updated_at sql.NullString
+
+		err = rows.Scan(
+			&id,
+			&name,
+			&address,
+			&created_at,
+			&updated_at,
+		)
+
+		warehouse.Id = id.String
+		warehouse.Name = name.String
+		warehouse.Address = address.String
+		warehouse.CreatedAt = created_at.String
+		warehouse.UpdatedAt = updated_at.String
+
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Warehouses = append(resp.Warehouses, &warehouse)
+	}
+
+	resp.Count = len(resp.Warehouses)
+
+	return resp, nil
+}
+
+func (w *warehouseRepo) Update(ctx context.Context, req *models.UpdateWarehouse) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	query = `
+		UPDATE
+			warehouses
+		SET
+			name = :name,
+			address = :address,
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":      req.Id,
+		"name":    req.Name,
+		"address": helper.NewNullString(req.Address),
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "warehouses", models.OutboxAggregateWarehouse, models.OutboxActionUpdated, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (w *warehouseRepo) Patch(ctx context.Context, req *models.PatchRequest) (int64, error) {
+
+	var (
+		query string
+		set   string
+	)
+
+	if len(req.Fields) <= 0 {
+		return 0, errors.New("no fields")
+	}
+
+	for key := range req.Fields {
+		set += fmt.Sprintf(" %s = :%s, ", key, key)
+	}
+
+	query = `
+		UPDATE
+			warehouses
+		SET
+	` + set + ` updated_at = now()
+		WHERE id = :id
+	`
+
+	req.Fields["id"] = req.ID
+
+	query, args := helper.ReplaceQueryParams(query, req.Fields)
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "warehouses", models.OutboxAggregateWarehouse, models.OutboxActionUpdated, req.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (w *warehouseRepo) Delete(ctx context.Context, req *models.WarehousePrimaryKey) error {
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "warehouses", models.OutboxAggregateWarehouse, models.OutboxActionDeleted, req.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM warehouses WHERE id = $1", req.Id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
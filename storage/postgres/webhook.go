@@ -0,0 +1,569 @@
+package postgres
+
+import (
+	"app/api/models"
+	"app/pkg/helper"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type webhookRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepo(db *pgxpool.Pool) *webhookRepo {
+	return &webhookRepo{
+		db: db,
+	}
+}
+
+func (w *webhookRepo) Create(ctx context.Context, req *models.CreateWebhook) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	query = `
+		INSERT INTO webhooks(
+			id,
+			url,
+			secret,
+			events,
+			active,
+			updated_at
+		)
+		VALUES (:id, :url, :secret, :events, :active, NOW())
+	`
+
+	params := map[string]interface{}{
+		"id":     id,
+		"url":    req.Url,
+		"secret": req.Secret,
+		"events": webhookEvents(req.Events),
+		"active": req.Active,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeOutbox(ctx, tx, "webhooks", models.OutboxAggregateWebhook, models.OutboxActionCreated, id, "secret")
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (w *webhookRepo) GetByID(ctx context.Context, req *models.WebhookPrimaryKey) (*models.Webhook, error) {
+
+	query := `
+		SELECT
+			id,
+			url,
+			secret,
+			events,
+			active,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM webhooks
+		WHERE id = $1
+	`
+
+	return scanWebhook(w.db.QueryRow(ctx, query, req.Id))
+}
+
+func (w *webhookRepo) GetList(ctx context.Context, req *models.GetListWebhookRequest) (resp *models.GetListWebhookResponse, err error) {
+	resp = &models.GetListWebhookResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT
+			id,
+			url,
+			secret,
+			events,
+			active,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM webhooks
+	`
+
+	if len(req.Search) > 0 {
+		filter += " AND url ILIKE '%' || :search || '%' "
+		params["search"] = req.Search
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + " ORDER BY created_at DESC " + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := w.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Webhooks = append(resp.Webhooks, webhook)
+	}
+
+	resp.Count = len(resp.Webhooks)
+
+	return resp, nil
+}
+
+func (w *webhookRepo) Update(ctx context.Context, req *models.UpdateWebhook) (int64, error) {
+	var (
+		query  string
+		params map[string]interface{}
+	)
+
+	query = `
+		UPDATE
+			webhooks
+		SET
+			url = :url,
+			secret = :secret,
+			events = :events,
+			active = :active,
+			updated_at = now()
+		WHERE id = :id
+	`
+
+	params = map[string]interface{}{
+		"id":     req.Id,
+		"url":    req.Url,
+		"secret": req.Secret,
+		"events": webhookEvents(req.Events),
+		"active": req.Active,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	err = writeOutbox(ctx, tx, "webhooks", models.OutboxAggregateWebhook, models.OutboxActionUpdated, req.Id, "secret")
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (w *webhookRepo) Delete(ctx context.Context, req *models.WebhookPrimaryKey) error {
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = writeOutbox(ctx, tx, "webhooks", models.OutboxAggregateWebhook, models.OutboxActionDeleted, req.Id, "secret")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"DELETE FROM webhooks WHERE id = $1", req.Id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetActive returns every active subscription; matching the event against the
+// subscription filters is left to the caller.
+func (w *webhookRepo) GetActive(ctx context.Context) ([]*models.Webhook, error) {
+
+	query := `
+		SELECT
+			id,
+			url,
+			secret,
+			events,
+			active,
+			TO_CHAR(created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(updated_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM webhooks
+		WHERE active
+	`
+
+	rows, err := w.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+
+	for rows.Next() {
+
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (w *webhookRepo) CreateDelivery(ctx context.Context, req *models.CreateWebhookDelivery) (string, error) {
+	var (
+		query string
+		id    = uuid.New().String()
+	)
+
+	query = `
+		INSERT INTO webhook_deliveries(
+			id,
+			webhook_id,
+			event,
+			payload
+		)
+		VALUES (:id, :webhook_id, :event, :payload)
+	`
+
+	params := map[string]interface{}{
+		"id":         id,
+		"webhook_id": req.WebhookId,
+		"event":      req.Event,
+		"payload":    req.Payload,
+	}
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	_, err := w.db.Exec(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// ClaimDeliveries leases due deliveries the same way notifications are leased,
+// returning them together with the url and secret of their webhook.
+func (w *webhookRepo) ClaimDeliveries(ctx context.Context, req *models.ClaimWebhookDeliveries) ([]*models.WebhookDelivery, error) {
+
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = NOW() + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT id
+				FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT
+			d.id,
+			d.webhook_id,
+			wh.url,
+			wh.secret,
+			d.event,
+			d.payload::TEXT,
+			d.status,
+			d.attempts,
+			d.response_status,
+			d.last_error,
+			TO_CHAR(d.next_attempt_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(d.created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(d.delivered_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM claimed AS d
+		JOIN webhooks AS wh ON wh.id = d.webhook_id
+	`
+
+	rows, err := w.db.Query(ctx, query, req.Limit, req.Lease)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+
+	for rows.Next() {
+
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (w *webhookRepo) MarkDelivered(ctx context.Context, req *models.DeliveredWebhook) error {
+
+	_, err := w.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET
+			status = $2,
+			attempts = attempts + 1,
+			response_status = $3,
+			last_error = NULL,
+			delivered_at = NOW()
+		WHERE id = $1
+	`, req.Id, models.WebhookDeliveryDelivered, req.ResponseStatus)
+
+	return err
+}
+
+func (w *webhookRepo) MarkDeliveryFailed(ctx context.Context, req *models.FailWebhookDelivery) error {
+
+	status := models.WebhookDeliveryPending
+	if req.Dead {
+		status = models.WebhookDeliveryDead
+	}
+
+	_, err := w.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET
+			status = $2,
+			attempts = attempts + 1,
+			response_status = $3,
+			last_error = $4,
+			next_attempt_at = NOW() + make_interval(secs => $5)
+		WHERE id = $1
+	`, req.Id, status, helper.NewNullInt(req.ResponseStatus), req.Error, req.RetryAfter)
+
+	return err
+}
+
+func (w *webhookRepo) GetDeliveries(ctx context.Context, req *models.GetListWebhookDeliveryRequest) (resp *models.GetListWebhookDeliveryResponse, err error) {
+	resp = &models.GetListWebhookDeliveryResponse{}
+
+	var (
+		query  string
+		filter = " WHERE TRUE "
+		offset = " OFFSET 0"
+		limit  = " LIMIT 10"
+		params = map[string]interface{}{}
+	)
+
+	query = `
+		SELECT
+			d.id,
+			d.webhook_id,
+			wh.url,
+			wh.secret,
+			d.event,
+			d.payload::TEXT,
+			d.status,
+			d.attempts,
+			d.response_status,
+			d.last_error,
+			TO_CHAR(d.next_attempt_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(d.created_at, 'YYYY-MM-DD HH24-MI-SS'),
+			TO_CHAR(d.delivered_at, 'YYYY-MM-DD HH24-MI-SS')
+		FROM webhook_deliveries AS d
+		JOIN webhooks AS wh ON wh.id = d.webhook_id
+	`
+
+	if len(req.WebhookId) > 0 {
+		filter += " AND d.webhook_id = :webhook_id "
+		params["webhook_id"] = req.WebhookId
+	}
+
+	if len(req.Status) > 0 {
+		filter += " AND d.status = :status "
+		params["status"] = req.Status
+	}
+
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+
+	query += filter + " ORDER BY d.created_at DESC " + offset + limit
+
+	query, args := helper.ReplaceQueryParams(query, params)
+
+	rows, err := w.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Deliveries = append(resp.Deliveries, delivery)
+	}
+
+	resp.Count = len(resp.Deliveries)
+
+	return resp, nil
+}
+
+// RetryDelivery moves a dead delivery back to the queue with a fresh attempt
+// budget.
+func (w *webhookRepo) RetryDelivery(ctx context.Context, req *models.WebhookDeliveryPrimaryKey) (int64, error) {
+
+	result, err := w.db.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET
+			status = $2,
+			attempts = 0,
+			next_attempt_at = NOW()
+		WHERE id = $1 AND status = $3
+	`, req.Id, models.WebhookDeliveryPending, models.WebhookDeliveryDead)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
+type webhookScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanWebhook(row webhookScanner) (*models.Webhook, error) {
+
+	var (
+		id, url, secret, created_at, updated_at sql.NullString
+		events                                  []string
+		active                                  sql.NullBool
+	)
+
+	err := row.Scan(
+		&id,
+		&url,
+		&secret,
+		&events,
+		&active,
+		&created_at,
+		&updated_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Webhook{
+		Id:        id.String,
+		Url:       url.String,
+		Secret:    secret.String,
+		Events:    events,
+		Active:    active.Bool,
+		CreatedAt: created_at.String,
+		UpdatedAt: updated_at.String,
+	}, nil
+}
+
+func scanWebhookDelivery(row webhookScanner) (*models.WebhookDelivery, error) {
+
+	var (
+		delivery                    models.WebhookDelivery
+		response_status             sql.NullInt64
+		last_error, next_attempt_at sql.NullString
+		created_at, delivered_at    sql.NullString
+	)
+
+	err := row.Scan(
+		&delivery.Id,
+		&delivery.WebhookId,
+		&delivery.Url,
+		&delivery.Secret,
+		&delivery.Event,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&response_status,
+		&last_error,
+		&next_attempt_at,
+		&created_at,
+		&delivered_at,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.ResponseStatus = int(response_status.Int64)
+	delivery.LastError = last_error.String
+	delivery.NextAttemptAt = next_attempt_at.String
+	delivery.CreatedAt = created_at.String
+	delivery.DeliveredAt = delivered_at.String
+
+	return &delivery, nil
+}
+
+// webhookEvents keeps a missing filter list from being stored as NULL.
+func webhookEvents(events []string) []string {
+
+	if events == nil {
+		return []string{}
+	}
+
+	return events
+}
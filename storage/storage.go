@@ -3,6 +3,43 @@ package storage
 import (
 	"app/api/models"
 	"context"
+	"errors"
+)
+
+var (
+	ErrInvalidOrderTransition = errors.New("invalid order status transition")
+	ErrOrderStatusReadOnly    = errors.New("order status can only be changed through a transition")
+	ErrOrderLocked            = errors.New("order items can only be changed while the order is new")
+	ErrInsufficientStock      = errors.New("insufficient stock")
+	ErrOrderAlreadyAssigned   = errors.New("order already has a courier")
+	ErrOutOfDeliveryZone      = errors.New("delivery address is outside of every delivery zone")
+	ErrBelowMinimumOrder      = errors.New("order total is below the zone minimum")
+	ErrAddressNotFound        = errors.New("delivery address not found")
+	ErrAddressRequired        = errors.New("order needs an address id, an inline address or a default customer address")
+	ErrLoginTaken             = errors.New("login is already taken")
+	ErrOTPTooSoon             = errors.New("a code was sent recently, try again later")
+	ErrOTPInvalid             = errors.New("invalid or expired code")
+	ErrOTPAttemptsExceeded    = errors.New("too many wrong codes, try again later")
+	ErrPaymentExceedsBalance  = errors.New("payment amount exceeds the outstanding balance")
+	ErrPaymentNotPending      = errors.New("payment is not pending")
+	ErrRefundExceedsPayments  = errors.New("refund exceeds the captured payments")
+	ErrRefundItemNotFound     = errors.New("order item not found")
+	ErrRefundQuantityExceeded = errors.New("refund quantity exceeds the unrefunded quantity")
+	ErrPromoCodeTaken         = errors.New("promo code is already taken")
+	ErrPromotionInUse         = errors.New("promotion has been redeemed, deactivate it instead")
+	ErrPromoCodeInvalid       = errors.New("promo code is not valid")
+	ErrPromoUsageExceeded     = errors.New("promo code usage limit reached")
+	ErrPromoNotApplicable     = errors.New("promo code does not apply to this order")
+	ErrTaxClassInUse          = errors.New("tax class is assigned to categories or products")
+	ErrCurrencyMismatch       = errors.New("amounts are in different currencies")
+	ErrExchangeRateExists     = errors.New("a rate for this currency pair already starts at that time")
+	ErrExchangeRateNotFound   = errors.New("no exchange rate between the currencies")
+	ErrProductNotFound        = errors.New("product not found")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrCourierNotFound        = errors.New("courier not found")
+	ErrFieldNotPatchable      = errors.New("field cannot be patched")
+	ErrInvoiceNotIssued       = errors.New("order has not been invoiced yet")
+	ErrOrderNotInvoiceable    = errors.New("cancelled orders cannot be invoiced")
 )
 
 type StorageI interface {
@@ -13,6 +50,15 @@ type StorageI interface {
 	Category() CategoryRepoI
 	Product() ProductRepoI
 	Order() OrderRepoI
+	Warehouse() WarehouseRepoI
+	DeliveryZone() DeliveryZoneRepoI
+	Notification() NotificationRepoI
+	Webhook() WebhookRepoI
+	Outbox() OutboxRepoI
+	Payment() PaymentRepoI
+	Promotion() PromotionRepoI
+	TaxClass() TaxClassRepoI
+	ExchangeRate() ExchangeRateRepoI
 }
 
 type CustomerRepoI interface {
@@ -22,6 +68,13 @@ type CustomerRepoI interface {
 	Update(context.Context, *models.UpdateCustomer) (int64, error)
 	Patch(context.Context, *models.PatchRequest) (int64, error)
 	Delete(context.Context, *models.CustomerPrimaryKey) error
+	CreateAddress(context.Context, *models.CreateCustomerAddress) (string, error)
+	GetAddress(context.Context, *models.CustomerAddressPrimaryKey) (*models.CustomerAddress, error)
+	GetAddresses(context.Context, *models.GetListCustomerAddressRequest) (*models.GetListCustomerAddressResponse, error)
+	UpdateAddress(context.Context, *models.UpdateCustomerAddress) (int64, error)
+	DeleteAddress(context.Context, *models.CustomerAddressPrimaryKey) (int64, error)
+	CreateOTP(context.Context, *models.CreateCustomerOTP) error
+	CheckOTP(context.Context, *models.CheckCustomerOTP) (string, error)
 }
 
 type UserRepoI interface {
@@ -31,6 +84,7 @@ type UserRepoI interface {
 	Update(context.Context, *models.UpdateUser) (int64, error)
 	Patch(context.Context, *models.PatchRequest) (int64, error)
 	Delete(context.Context, *models.UserPrimaryKey) error
+	GetByLogin(context.Context, *models.UserLoginKey) (*models.UserCredentials, error)
 }
 
 type CourierRepoI interface {
@@ -40,6 +94,14 @@ type CourierRepoI interface {
 	Update(context.Context, *models.UpdateCourier) (int64, error)
 	Patch(context.Context, *models.PatchRequest) (int64, error)
 	Delete(context.Context, *models.CourierPrimaryKey) error
+	UpdateStatus(context.Context, *models.UpdateCourierStatus) (int64, error)
+	GetAvailable(context.Context) ([]*models.CourierLoad, error)
+	AddLocation(context.Context, *models.CreateCourierLocation) (int64, error)
+	GetLocations(context.Context, *models.GetListCourierLocationRequest) (*models.GetListCourierLocationResponse, error)
+	GetNearby(context.Context, *models.GetNearbyCourierRequest) (*models.GetNearbyCourierResponse, error)
+	CreateMessage(context.Context, *models.CreateCourierMessage) (*models.CourierMessage, error)
+	GetPendingMessages(context.Context, *models.CourierPrimaryKey) ([]*models.CourierMessage, error)
+	AckMessage(context.Context, *models.CourierMessagePrimaryKey) (int64, error)
 }
 
 type CategoryRepoI interface {
@@ -58,6 +120,10 @@ type ProductRepoI interface {
 	Update(context.Context, *models.UpdateProduct) (int64, error)
 	Patch(context.Context, *models.PatchRequest) (int64, error)
 	Delete(context.Context, *models.ProductPrimaryKey) error
+	AdjustStock(context.Context, *models.AdjustStock) error
+	TransferStock(context.Context, *models.TransferStock) error
+	GetStock(context.Context, *models.ProductPrimaryKey) (*models.GetProductStockResponse, error)
+	GetStockMovements(context.Context, *models.GetListStockMovementRequest) (*models.GetListStockMovementResponse, error)
 }
 
 type OrderRepoI interface {
@@ -67,4 +133,93 @@ type OrderRepoI interface {
 	Update(context.Context, *models.UpdateOrder) (int64, error)
 	Patch(context.Context, *models.PatchRequest) (int64, error)
 	Delete(context.Context, *models.OrderPrimaryKey) error
+	Transition(context.Context, *models.TransitionOrder) error
+	History(context.Context, *models.OrderPrimaryKey) (*models.GetOrderHistoryResponse, error)
+	AssignCourier(context.Context, *models.AssignCourier) error
+	GetUndispatched(context.Context) ([]string, error)
+	Refund(context.Context, *models.CreateRefund) (string, error)
+	GetRefunds(context.Context, *models.OrderPrimaryKey) (*models.GetListRefundResponse, error)
+	IssueInvoice(context.Context, *models.IssueOrderInvoice) (*models.OrderInvoice, error)
+	GetInvoice(context.Context, *models.OrderPrimaryKey) (*models.OrderInvoice, error)
+}
+
+type WarehouseRepoI interface {
+	Create(context.Context, *models.CreateWarehouse) (string, error)
+	GetByID(context.Context, *models.WarehousePrimaryKey) (*models.Warehouse, error)
+	GetList(context.Context, *models.GetListWarehouseRequest) (*models.GetListWarehouseResponse, error)
+	Update(context.Context, *models.UpdateWarehouse) (int64, error)
+	Patch(context.Context, *models.PatchRequest) (int64, error)
+	Delete(context.Context, *models.WarehousePrimaryKey) error
+}
+
+type DeliveryZoneRepoI interface {
+	Create(context.Context, *models.CreateDeliveryZone) (string, error)
+	GetByID(context.Context, *models.DeliveryZonePrimaryKey) (*models.DeliveryZone, error)
+	GetList(context.Context, *models.GetListDeliveryZoneRequest) (*models.GetListDeliveryZoneResponse, error)
+	Update(context.Context, *models.UpdateDeliveryZone) (int64, error)
+	Patch(context.Context, *models.PatchRequest) (int64, error)
+	Delete(context.Context, *models.DeliveryZonePrimaryKey) error
+}
+
+type NotificationRepoI interface {
+	Create(context.Context, *models.CreateNotification) (string, error)
+	ClaimPending(context.Context, *models.ClaimNotifications) ([]*models.Notification, error)
+	MarkSent(context.Context, *models.NotificationPrimaryKey) error
+	MarkFailed(context.Context, *models.FailNotification) error
+}
+
+type WebhookRepoI interface {
+	Create(context.Context, *models.CreateWebhook) (string, error)
+	GetByID(context.Context, *models.WebhookPrimaryKey) (*models.Webhook, error)
+	GetList(context.Context, *models.GetListWebhookRequest) (*models.GetListWebhookResponse, error)
+	Update(context.Context, *models.UpdateWebhook) (int64, error)
+	Delete(context.Context, *models.WebhookPrimaryKey) error
+	GetActive(context.Context) ([]*models.Webhook, error)
+	CreateDelivery(context.Context, *models.CreateWebhookDelivery) (string, error)
+	ClaimDeliveries(context.Context, *models.ClaimWebhookDeliveries) ([]*models.WebhookDelivery, error)
+	MarkDelivered(context.Context, *models.DeliveredWebhook) error
+	MarkDeliveryFailed(context.Context, *models.FailWebhookDelivery) error
+	GetDeliveries(context.Context, *models.GetListWebhookDeliveryRequest) (*models.GetListWebhookDeliveryResponse, error)
+	RetryDelivery(context.Context, *models.WebhookDeliveryPrimaryKey) (int64, error)
+}
+
+type OutboxRepoI interface {
+	GetPending(context.Context, *models.GetPendingOutboxEvents) ([]*models.OutboxEvent, error)
+	AssignSeq(context.Context, *models.OutboxEventPrimaryKey) (int64, error)
+	MarkPublished(context.Context, *models.OutboxEventPrimaryKey) error
+	MarkFailed(context.Context, *models.FailOutboxEvent) error
+	GetList(context.Context, *models.GetListOutboxEventRequest) ([]*models.OutboxEvent, error)
+}
+
+type PaymentRepoI interface {
+	Create(context.Context, *models.CreatePayment) (string, error)
+	GetByID(context.Context, *models.PaymentPrimaryKey) (*models.Payment, error)
+	GetList(context.Context, *models.GetListPaymentRequest) (*models.GetListPaymentResponse, error)
+	UpdateStatus(context.Context, *models.UpdatePaymentStatus) error
+}
+
+type PromotionRepoI interface {
+	Create(context.Context, *models.CreatePromotion) (string, error)
+	GetByID(context.Context, *models.PromotionPrimaryKey) (*models.Promotion, error)
+	GetList(context.Context, *models.GetListPromotionRequest) (*models.GetListPromotionResponse, error)
+	Update(context.Context, *models.UpdatePromotion) (int64, error)
+	Patch(context.Context, *models.PatchRequest) (int64, error)
+	Delete(context.Context, *models.PromotionPrimaryKey) error
+}
+
+type TaxClassRepoI interface {
+	Create(context.Context, *models.CreateTaxClass) (string, error)
+	GetByID(context.Context, *models.TaxClassPrimaryKey) (*models.TaxClass, error)
+	GetList(context.Context, *models.GetListTaxClassRequest) (*models.GetListTaxClassResponse, error)
+	Update(context.Context, *models.UpdateTaxClass) (int64, error)
+	Patch(context.Context, *models.PatchRequest) (int64, error)
+	Delete(context.Context, *models.TaxClassPrimaryKey) error
+}
+
+type ExchangeRateRepoI interface {
+	Create(context.Context, *models.CreateExchangeRate) (string, error)
+	GetByID(context.Context, *models.ExchangeRatePrimaryKey) (*models.ExchangeRate, error)
+	GetList(context.Context, *models.GetListExchangeRateRequest) (*models.GetListExchangeRateResponse, error)
+	Update(context.Context, *models.UpdateExchangeRate) (int64, error)
+	Delete(context.Context, *models.ExchangeRatePrimaryKey) error
 }